@@ -0,0 +1,110 @@
+package glass
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// FocusTopic is the event bus topic changes to the window focus are published on.
+const FocusTopic = "glass.focus"
+
+// IdleTopic is the event bus topic changes to the user activity are published on.
+const IdleTopic = "glass.idle"
+
+// DefaultIdleAfter is the default time without user input after which the mirror is idle.
+const DefaultIdleAfter = 5 * time.Minute
+
+// ActivityConfig contains configuration for focus and idle detection.
+type ActivityConfig struct {
+	// Enabled publishes focus and idle changes on the event bus.
+	Enabled bool `yaml:"enabled"`
+
+	// IdleAfter is the time without user input after which the
+	// mirror is idle. Zero disables idle detection.
+	IdleAfter time.Duration `yaml:"idleAfter"`
+}
+
+// Validate validates the activity configuration.
+func (c ActivityConfig) Validate() error {
+	if c.IdleAfter < 0 {
+		return errors.New("config: activity idle time cannot be negative")
+	}
+	return nil
+}
+
+// isActivitySetup determines if the setup javascript watches activity.
+func isActivitySetup(js string) bool {
+	return strings.HasPrefix(js, "watchActivity(")
+}
+
+// startActivity watches the window focus and user activity, publishing
+// changes on FocusTopic and IdleTopic. The page listeners are attached
+// again when the window is relaunched.
+func (r *Runtime) startActivity() error {
+	r.mu.Lock()
+	cfg := r.cfg.Activity
+	r.mu.Unlock()
+
+	if err := r.ui.Bind("activityChanged", r.activityChanged); err != nil {
+		return fmt.Errorf("could not bind activity: %w", err)
+	}
+	js := fmt.Sprintf("watchActivity(%d);", cfg.IdleAfter.Milliseconds())
+	if _, err := r.ui.Eval(js); err != nil {
+		return fmt.Errorf("could not watch activity: %w", err)
+	}
+	r.ui.replaceSetup(isActivitySetup, []string{js})
+	return nil
+}
+
+// restartActivity applies a change to the activity configuration.
+func (r *Runtime) restartActivity(wasEnabled, enabled bool) {
+	if !enabled {
+		if wasEnabled {
+			r.stopActivity()
+		}
+		return
+	}
+	if err := r.startActivity(); err != nil {
+		r.log.Error("could not watch activity", logCtx.Error("error", err))
+	}
+}
+
+// stopActivity removes the page activity listeners.
+func (r *Runtime) stopActivity() {
+	r.ui.replaceSetup(isActivitySetup, nil)
+	if _, err := r.ui.Eval("unwatchActivity();"); err != nil && !errors.Is(err, ErrClosed) {
+		r.log.Error("could not stop watching activity", logCtx.Error("error", err))
+	}
+	if err := r.ui.Unbind("activityChanged"); err != nil && !errors.Is(err, ErrClosed) {
+		r.log.Error("could not unbind activity", logCtx.Error("error", err))
+	}
+}
+
+// activityChanged is called from javascript when the window focus or
+// user activity changes. The kind is either "focus" or "idle".
+func (r *Runtime) activityChanged(kind string, on bool) error {
+	var ev Event
+	switch kind {
+	case "focus":
+		b, _ := json.Marshal(struct {
+			Focused bool `json:"focused"`
+		}{Focused: on})
+		ev = Event{Topic: FocusTopic, Data: b}
+	case "idle":
+		b, _ := json.Marshal(struct {
+			Idle bool `json:"idle"`
+		}{Idle: on})
+		ev = Event{Topic: IdleTopic, Data: b}
+	default:
+		return fmt.Errorf("unknown activity %q", kind)
+	}
+
+	r.log.Debug("activity changed", logCtx.Str("kind", kind), logCtx.Bool("on", on))
+	r.bus.Publish(ev)
+	return nil
+}
@@ -0,0 +1,74 @@
+package glass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_StartActivity(t *testing.T) {
+	win := NewRecordingWindow()
+	cfg := Config{Activity: ActivityConfig{Enabled: true, IdleAfter: time.Minute}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), "watchActivity(60000);")
+	assert.Equal(t, []string{"watchActivity(60000);"}, rt.ui.setup)
+
+	err = rt.Close()
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), "unwatchActivity();")
+	assert.Equal(t, `unbindFunction("activityChanged");`, win.Evals()[len(win.Evals())-1])
+	assert.Empty(t, rt.ui.setup)
+}
+
+func TestRuntime_ActivityChanged(t *testing.T) {
+	win := NewRecordingWindow()
+	cfg := Config{Activity: ActivityConfig{Enabled: true}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	require.NoError(t, rt.startActivity())
+
+	got := make(chan Event, 2)
+	unsubFocus := rt.Events().Subscribe(FocusTopic, func(e Event) { got <- e })
+	defer unsubFocus()
+	unsubIdle := rt.Events().Subscribe(IdleTopic, func(e Event) { got <- e })
+	defer unsubIdle()
+
+	fn, ok := win.Binding("activityChanged")
+	require.True(t, ok)
+	changed := fn.(func(string, bool) error)
+
+	require.NoError(t, changed("focus", false))
+	require.NoError(t, changed("idle", true))
+	assert.Error(t, changed("unknown", true))
+
+	e := <-got
+	assert.Equal(t, FocusTopic, e.Topic)
+	assert.JSONEq(t, `{"focused":false}`, string(e.Data))
+	e = <-got
+	assert.Equal(t, IdleTopic, e.Topic)
+	assert.JSONEq(t, `{"idle":true}`, string(e.Data))
+}
+
+func TestRuntime_ReloadStopsActivity(t *testing.T) {
+	win := NewRecordingWindow()
+	cfg := Config{Activity: ActivityConfig{Enabled: true}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	require.NoError(t, rt.startActivity())
+
+	err := rt.Reload(context.Background(), Config{})
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), "unwatchActivity();")
+	assert.Contains(t, win.Evals(), `unbindFunction("activityChanged");`)
+	assert.Empty(t, rt.ui.setup)
+	rt.ui.mu.RLock()
+	defer rt.ui.mu.RUnlock()
+	assert.NotContains(t, rt.ui.fns, "activityChanged")
+}
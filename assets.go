@@ -0,0 +1,137 @@
+package glass
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// AssetServer serves the static assets of modules from their directories.
+//
+// Each module is scoped to its own directory.
+type AssetServer struct {
+	ln  net.Listener
+	srv *http.Server
+
+	mu   sync.RWMutex
+	dirs map[string]http.Handler
+}
+
+// NewAssetServer returns a started asset server listening on a local port.
+func NewAssetServer() (*AssetServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("could not start asset server: %w", err)
+	}
+
+	s := &AssetServer{
+		ln:   ln,
+		dirs: map[string]http.Handler{},
+	}
+	s.srv = &http.Server{Handler: s}
+	go func() {
+		_ = s.srv.Serve(ln)
+	}()
+	return s, nil
+}
+
+// URL returns the base url of the asset server.
+func (s *AssetServer) URL() string {
+	return "http://" + s.ln.Addr().String()
+}
+
+// Register serves the assets of the module from dir.
+func (s *AssetServer) Register(module, dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := "/modules/" + module + "/"
+	s.dirs[module] = http.StripPrefix(prefix, http.FileServer(assetDir{http.Dir(dir)}))
+}
+
+// ServeHTTP serves module assets.
+func (s *AssetServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p := req.URL.Path
+	if !strings.HasPrefix(p, "/modules/") || containsDotDot(p) {
+		http.NotFound(rw, req)
+		return
+	}
+	module := strings.SplitN(strings.TrimPrefix(p, "/modules/"), "/", 2)[0]
+
+	s.mu.RLock()
+	h, ok := s.dirs[module]
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(rw, req)
+		return
+	}
+	h.ServeHTTP(rw, req)
+}
+
+// Close stops the asset server.
+func (s *AssetServer) Close() error {
+	return s.srv.Close()
+}
+
+var assetAttrRegex = regexp.MustCompile(`\b(src|href)=(["'])([^"']*)["']`)
+
+// Transformer returns an html transformer rewriting relative asset
+// urls to the asset server.
+func (s *AssetServer) Transformer() HTMLTransformer {
+	return func(module, html string) (string, error) {
+		base := s.URL() + "/modules/" + module + "/"
+		return assetAttrRegex.ReplaceAllStringFunc(html, func(attr string) string {
+			m := assetAttrRegex.FindStringSubmatch(attr)
+			if !isRelativeURL(m[3]) {
+				return attr
+			}
+			return m[1] + "=" + m[2] + base + strings.TrimPrefix(m[3], "./") + m[2]
+		}), nil
+	}
+}
+
+func isRelativeURL(u string) bool {
+	if u == "" || strings.HasPrefix(u, "/") || strings.HasPrefix(u, "#") {
+		return false
+	}
+	if i := strings.IndexAny(u, ":/?#"); i >= 0 && u[i] == ':' {
+		// The url has a scheme.
+		return false
+	}
+	return true
+}
+
+func containsDotDot(p string) bool {
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// assetDir is a file system that does not list directories.
+type assetDir struct {
+	fs http.FileSystem
+}
+
+func (d assetDir) Open(name string) (http.File, error) {
+	f, err := d.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if fi.IsDir() {
+		_ = f.Close()
+		return nil, os.ErrNotExist
+	}
+	return f, nil
+}
@@ -0,0 +1,76 @@
+package glass_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	glass "github.com/glasslabs/looking-glass"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetServer_ServesRelativeAssets(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "icon.png"), []byte("png data"), 0o600)
+	require.NoError(t, err)
+
+	srv, err := glass.NewAssetServer()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	srv.Register("test", dir)
+
+	html, err := srv.Transformer()("test", `<img src="./icon.png"><a href="https://example.com">x</a>`)
+	require.NoError(t, err)
+
+	url := srv.URL() + "/modules/test/icon.png"
+	assert.Equal(t, `<img src="`+url+`"><a href="https://example.com">x</a>`, html)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "png data", string(b))
+}
+
+func TestAssetServer_BlocksTraversal(t *testing.T) {
+	root := t.TempDir()
+	err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o600)
+	require.NoError(t, err)
+	dir := filepath.Join(root, "test")
+	err = os.Mkdir(dir, 0o750)
+	require.NoError(t, err)
+	other := filepath.Join(root, "other")
+	err = os.Mkdir(other, 0o750)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(other, "private.txt"), []byte("private"), 0o600)
+	require.NoError(t, err)
+
+	srv, err := glass.NewAssetServer()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+	srv.Register("test", dir)
+	srv.Register("other", other)
+
+	paths := []string{
+		"/modules/test/../secret.txt",
+		"/modules/test/..%2fsecret.txt",
+		"/modules/test/%2e%2e/secret.txt",
+		"/modules/test/../other/private.txt",
+		"/modules/test/",
+	}
+	for _, p := range paths {
+		req, err := http.NewRequest(http.MethodGet, srv.URL()+p, nil)
+		require.NoError(t, err)
+		req.URL.Opaque = p
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, p)
+	}
+}
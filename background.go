@@ -0,0 +1,371 @@
+package glass
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// DefaultBackgroundInterval is the default time each image of a background folder is shown.
+const DefaultBackgroundInterval = time.Minute
+
+// DefaultBackgroundTransition is the default time background images fade in over.
+const DefaultBackgroundTransition = time.Second
+
+// backgroundStyleID is the id of the background style element. The "glass-"
+// prefix is reserved, so it cannot collide with the css of a module.
+const backgroundStyleID = "glass-background"
+
+// imageTypes are the content types of the supported background image files.
+var imageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".avif": "image/avif",
+	".svg":  "image/svg+xml",
+}
+
+// BackgroundConfig contains configuration for the page background.
+type BackgroundConfig struct {
+	// Color is the css background color, e.g. "#102030".
+	Color string `yaml:"color"`
+
+	// Gradient is a css gradient, e.g. "linear-gradient(#000, #333)".
+	Gradient string `yaml:"gradient"`
+
+	// Image is the path or url of a background image.
+	Image string `yaml:"image"`
+
+	// Folder is the path of a directory of images that are cycled through.
+	Folder string `yaml:"folder"`
+
+	// Interval is the time each image of the folder is shown.
+	// Zero means DefaultBackgroundInterval.
+	Interval time.Duration `yaml:"interval"`
+
+	// Transition is the time an image fades in over.
+	// Zero means DefaultBackgroundTransition.
+	Transition time.Duration `yaml:"transition"`
+}
+
+// Validate validates the background configuration.
+func (c BackgroundConfig) Validate() error {
+	n := 0
+	for _, s := range []string{c.Gradient, c.Image, c.Folder} {
+		if s != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return errors.New("config: only one of background gradient, image and folder can be set")
+	}
+	for _, v := range []string{c.Color, c.Gradient} {
+		if strings.ContainsAny(v, "{};") {
+			return fmt.Errorf("config: background %q is not a css value", v)
+		}
+	}
+	if isRemoteCSS(c.Folder) {
+		return errors.New("config: background folder must be a local directory")
+	}
+	if c.Interval < 0 || c.Transition < 0 {
+		return errors.New("config: background interval and transition cannot be negative")
+	}
+	return nil
+}
+
+// Enabled determines if a background has been configured.
+func (c BackgroundConfig) Enabled() bool {
+	return c.Color != "" || c.Gradient != "" || c.Image != "" || c.Folder != ""
+}
+
+// checkFiles returns the problems with the files referenced by the background configuration.
+func (c BackgroundConfig) checkFiles() []error {
+	var errs []error
+	if c.Image != "" && !isRemoteCSS(c.Image) {
+		if _, err := readAsset(c.Image); err != nil {
+			errs = append(errs, fmt.Errorf("config: could not read background image %q: %w", c.Image, err))
+		}
+	}
+	if c.Folder != "" {
+		if _, err := folderImages(c.Folder); err != nil {
+			errs = append(errs, fmt.Errorf("config: could not read background folder %q: %w", c.Folder, err))
+		}
+	}
+	return errs
+}
+
+func (c BackgroundConfig) interval() time.Duration {
+	if c.Interval <= 0 {
+		return DefaultBackgroundInterval
+	}
+	return c.Interval
+}
+
+func (c BackgroundConfig) transition() time.Duration {
+	if c.Transition <= 0 {
+		return DefaultBackgroundTransition
+	}
+	return c.Transition
+}
+
+// backgroundCSS returns the css applying the background color and gradient.
+//
+// The body background is cleared, so the background covers the whole page.
+func backgroundCSS(cfg BackgroundConfig) string {
+	var sb strings.Builder
+	sb.WriteString("html {")
+	if cfg.Color != "" {
+		sb.WriteString(" background-color: " + cfg.Color + ";")
+	}
+	if cfg.Gradient != "" {
+		sb.WriteString(" background-image: " + cfg.Gradient + "; background-attachment: fixed;")
+	}
+	sb.WriteString(" } body { background: transparent; }")
+	return sb.String()
+}
+
+// folderImages returns the paths of the images in dir, in lexical order.
+func folderImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if _, ok := imageTypes[strings.ToLower(filepath.Ext(e.Name()))]; !ok {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// imageDataURL returns the image at the path or url as a data url.
+func imageDataURL(src string) (string, error) {
+	var (
+		b   []byte
+		typ string
+		err error
+	)
+	if isRemoteCSS(src) {
+		var header string
+		b, header, err = fetchAsset(src)
+		if err != nil {
+			return "", err
+		}
+		typ, _, _ = mime.ParseMediaType(header)
+		if u, err := url.Parse(src); err == nil && !strings.HasPrefix(typ, "image/") {
+			typ = imageTypes[strings.ToLower(path.Ext(u.Path))]
+		}
+	} else {
+		b, err = readAsset(src)
+		if err != nil {
+			return "", err
+		}
+		typ = imageTypes[strings.ToLower(filepath.Ext(src))]
+	}
+
+	if !strings.HasPrefix(typ, "image/") {
+		typ = http.DetectContentType(b)
+		if !strings.HasPrefix(typ, "image/") {
+			return "", errors.New("not an image")
+		}
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// isBackgroundSetup determines if the setup javascript applies the background.
+func isBackgroundSetup(js string) bool {
+	return strings.HasPrefix(js, `loadCSS("`+backgroundStyleID+`"`) ||
+		strings.HasPrefix(js, "stageBackground(") ||
+		strings.HasPrefix(js, "showBackground(")
+}
+
+// isBackgroundImageSetup determines if the setup javascript shows a background image.
+func isBackgroundImageSetup(js string) bool {
+	return strings.HasPrefix(js, "stageBackground(") || strings.HasPrefix(js, "showBackground(")
+}
+
+// setBackgroundCSS replaces the background css.
+func (ui *UI) setBackgroundCSS(css string) error {
+	b, _ := json.Marshal(css)
+	js := fmt.Sprintf(`loadCSS("`+backgroundStyleID+`", %s);`, b)
+	if _, err := ui.Eval(`removeCSS("` + backgroundStyleID + `");`); err != nil {
+		return err
+	}
+	if _, err := ui.Eval(js); err != nil {
+		return err
+	}
+
+	ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("`+backgroundStyleID+`"`)
+	}, []string{js})
+	return nil
+}
+
+// showBackground fades in the image data url as the background. The image
+// is sent in chunks, keeping each evaluation below the size limit of the
+// devtools channel.
+func (ui *UI) showBackground(data string, transition time.Duration) error {
+	var js []string
+	for i := 0; i < len(data); i += htmlChunkSize {
+		end := i + htmlChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		b, _ := json.Marshal(data[i:end])
+		js = append(js, fmt.Sprintf("stageBackground(%s, %t);", b, i == 0))
+	}
+
+	for _, s := range js {
+		if _, err := ui.Eval(s); err != nil {
+			return err
+		}
+	}
+	if _, err := ui.Eval(fmt.Sprintf("showBackground(%d);", transition.Milliseconds())); err != nil {
+		return err
+	}
+
+	// The current image is shown without a transition when the page is set up again.
+	ui.replaceSetup(isBackgroundImageSetup, append(js, "showBackground(0);"))
+	return nil
+}
+
+// clearBackground removes the background, restoring the page default.
+func (ui *UI) clearBackground() error {
+	ui.replaceSetup(isBackgroundSetup, nil)
+	if _, err := ui.Eval(`removeCSS("` + backgroundStyleID + `");`); err != nil {
+		return err
+	}
+	_, err := ui.Eval("clearBackground();")
+	return err
+}
+
+// startBackground applies the configured background, cycling through the
+// images of the background folder until ctx is done or the background
+// is restarted.
+func (r *Runtime) startBackground(ctx context.Context) error {
+	r.stopBackground()
+
+	r.mu.Lock()
+	cfg := r.cfg.Background
+	r.mu.Unlock()
+
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	stop := func() {
+		if err := r.ui.clearBackground(); err != nil && !errors.Is(err, ErrClosed) {
+			r.log.Error("could not clear background", logCtx.Error("error", err))
+		}
+	}
+	r.mu.Lock()
+	r.backgroundStop = stop
+	r.mu.Unlock()
+
+	if err := r.ui.setBackgroundCSS(backgroundCSS(cfg)); err != nil {
+		return fmt.Errorf("could not set background: %w", err)
+	}
+
+	switch {
+	case cfg.Image != "":
+		data, err := imageDataURL(cfg.Image)
+		if err != nil {
+			return fmt.Errorf("could not load background image %q: %w", cfg.Image, err)
+		}
+		if err = r.ui.showBackground(data, cfg.transition()); err != nil {
+			return fmt.Errorf("could not show background image: %w", err)
+		}
+	case cfg.Folder != "":
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		r.mu.Lock()
+		r.backgroundStop = func() {
+			cancel()
+			<-done
+			stop()
+		}
+		r.mu.Unlock()
+
+		go func() {
+			defer close(done)
+
+			r.runSlideshow(ctx, cfg)
+		}()
+	}
+	return nil
+}
+
+// runSlideshow shows the images of the background folder in turn until
+// ctx is done. The folder is read for each image, so images can be added
+// and removed while running.
+func (r *Runtime) runSlideshow(ctx context.Context, cfg BackgroundConfig) {
+	log := r.log.With(logCtx.Str("folder", cfg.Folder))
+
+	var next int
+	for {
+		imgs, err := folderImages(cfg.Folder)
+		switch {
+		case err != nil:
+			log.Warn("could not read background folder", logCtx.Error("error", err))
+		case len(imgs) == 0:
+			log.Warn("background folder has no images")
+		default:
+			if next >= len(imgs) {
+				next = 0
+			}
+			img := imgs[next]
+			next++
+
+			data, err := imageDataURL(img)
+			if err != nil {
+				log.Warn("could not load background image", logCtx.Str("image", img), logCtx.Error("error", err))
+				break
+			}
+			if err = r.ui.showBackground(data, cfg.transition()); err != nil && !errors.Is(err, ErrClosed) {
+				log.Error("could not show background image", logCtx.Error("error", err))
+			}
+		}
+
+		timer := time.NewTimer(cfg.interval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// stopBackground stops the background slideshow and removes the background.
+func (r *Runtime) stopBackground() {
+	r.mu.Lock()
+	stop := r.backgroundStop
+	r.backgroundStop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
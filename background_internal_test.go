@@ -0,0 +1,204 @@
+package glass
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngData is the header of a png file.
+var pngData = []byte("\x89PNG\r\n\x1a\n")
+
+func TestBackgroundConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     BackgroundConfig
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			cfg: BackgroundConfig{
+				Color:      "#102030",
+				Folder:     "photos",
+				Interval:   time.Minute,
+				Transition: 2 * time.Second,
+			},
+		},
+		{
+			name:    "handles multiple backgrounds",
+			cfg:     BackgroundConfig{Gradient: "linear-gradient(#000, #333)", Image: "photo.jpg"},
+			wantErr: "config: only one of background gradient, image and folder can be set",
+		},
+		{
+			name:    "handles invalid css value",
+			cfg:     BackgroundConfig{Color: "red; } body { color: red"},
+			wantErr: `config: background "red; } body { color: red" is not a css value`,
+		},
+		{
+			name:    "handles remote folder",
+			cfg:     BackgroundConfig{Folder: "https://example.com/photos"},
+			wantErr: "config: background folder must be a local directory",
+		},
+		{
+			name:    "handles negative interval",
+			cfg:     BackgroundConfig{Folder: "photos", Interval: -time.Second},
+			wantErr: "config: background interval and transition cannot be negative",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBackgroundCSS(t *testing.T) {
+	got := backgroundCSS(BackgroundConfig{Color: "#102030", Gradient: "linear-gradient(#000, #333)"})
+
+	assert.Equal(t, "html { background-color: #102030; background-image: linear-gradient(#000, #333); background-attachment: fixed; } body { background: transparent; }", got)
+}
+
+func TestFolderImages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.JPG", "a.png", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), pngData, 0o600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "c.png"), 0o700))
+
+	got, err := folderImages(dir)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.png"), filepath.Join(dir, "b.JPG")}, got)
+}
+
+func TestImageDataURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/notes.txt" {
+			_, _ = rw.Write([]byte("some notes"))
+			return
+		}
+		rw.Header().Set("Content-Type", "image/webp; charset=binary")
+		_, _ = rw.Write([]byte("webp"))
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(local, []byte("jpeg"), 0o600))
+	unknown := filepath.Join(dir, "photo")
+	require.NoError(t, os.WriteFile(unknown, pngData, 0o600))
+
+	got, err := imageDataURL(local)
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/jpeg;base64,anBlZw==", got)
+
+	got, err = imageDataURL(unknown)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, "data:image/png;base64,"))
+
+	got, err = imageDataURL(srv.URL + "/photo")
+	require.NoError(t, err)
+	assert.Equal(t, "data:image/webp;base64,d2VicA==", got)
+
+	_, err = imageDataURL(srv.URL + "/notes.txt")
+	assert.EqualError(t, err, "not an image")
+}
+
+func TestUI_ShowBackgroundChunksImage(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	data := strings.Repeat("a", htmlChunkSize+10)
+
+	err := ui.showBackground(data, 2*time.Second)
+
+	require.NoError(t, err)
+	evals := win.Evals()
+	require.Len(t, evals, 3)
+	first, _ := json.Marshal(data[:htmlChunkSize])
+	assert.Equal(t, "stageBackground("+string(first)+", true);", evals[0])
+	assert.Equal(t, `stageBackground("aaaaaaaaaa", false);`, evals[1])
+	assert.Equal(t, "showBackground(2000);", evals[2])
+	assert.Equal(t, append(evals[:2:2], "showBackground(0);"), ui.setup)
+}
+
+func TestRuntime_StartBackground(t *testing.T) {
+	dir := t.TempDir()
+	img := filepath.Join(dir, "photo.png")
+	require.NoError(t, os.WriteFile(img, pngData, 0o600))
+
+	win := NewRecordingWindow()
+	cfg := Config{Background: BackgroundConfig{Color: "#102030", Image: img}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), `loadCSS("glass-background", "html { background-color: #102030; } body { background: transparent; }");`)
+	assert.Contains(t, win.Evals(), `stageBackground("data:image/png;base64,iVBORw0KGgo=", true);`)
+	assert.Contains(t, win.Evals(), "showBackground(1000);")
+	assert.Len(t, rt.ui.setup, 3)
+
+	err = rt.Close()
+
+	require.NoError(t, err)
+	assert.Equal(t, "clearBackground();", win.Evals()[len(win.Evals())-1])
+	assert.Empty(t, rt.ui.setup)
+}
+
+func TestRuntime_BackgroundSlideshow(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.png"), []byte("a"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.png"), []byte("b"), 0o600))
+
+	win := NewRecordingWindow()
+	cfg := Config{Background: BackgroundConfig{Folder: dir, Interval: 10 * time.Millisecond, Transition: 5 * time.Millisecond}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+
+	require.NoError(t, rt.startBackground(context.Background()))
+
+	shown := func(js string) func() bool {
+		return func() bool {
+			for _, eval := range win.Evals() {
+				if eval == js {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	assert.Eventually(t, shown(`stageBackground("data:image/png;base64,YQ==", true);`), time.Second, time.Millisecond)
+	assert.Eventually(t, shown(`stageBackground("data:image/png;base64,Yg==", true);`), time.Second, time.Millisecond)
+
+	rt.stopBackground()
+
+	assert.Equal(t, "clearBackground();", win.Evals()[len(win.Evals())-1])
+}
+
+func TestRuntime_ReloadRemovesBackground(t *testing.T) {
+	win := NewRecordingWindow()
+	cfg := Config{Background: BackgroundConfig{Gradient: "linear-gradient(#000, #333)"}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	require.NoError(t, rt.startBackground(context.Background()))
+
+	err := rt.Reload(context.Background(), Config{})
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), "clearBackground();")
+	assert.Empty(t, rt.ui.setup)
+}
@@ -0,0 +1,104 @@
+package glass
+
+import (
+	"context"
+	"strings"
+)
+
+// The javascript wrapping batched statements.
+const (
+	batchPrefix = "runBatch(function () {\n"
+	batchSep    = "\n;"
+	batchSuffix = "\n});"
+)
+
+// Batch coalesces the ui calls made by fn into a single evaluation,
+// applied in order on the next animation frame.
+//
+// Calls made while batching are queued and return immediately. Eval,
+// EvalContext and EvalInto return no result, so values must be read
+// outside of a batch. EvalIntoStrict is not queued and returns ErrBatching. If applying the batch fails, the error is returned
+// by Batch. Nested batches join the outer batch.
+func (u *UIContext) Batch(fn func()) error {
+	u.mu.Lock()
+	if u.batching {
+		u.mu.Unlock()
+		fn()
+		return nil
+	}
+	u.batching = true
+	u.mu.Unlock()
+
+	var js []string
+	func() {
+		defer func() {
+			u.mu.Lock()
+			js = u.batched
+			u.batching, u.batched = false, nil
+			u.mu.Unlock()
+		}()
+
+		fn()
+	}()
+
+	if len(js) == 0 {
+		return nil
+	}
+	_ = u.async.wait(context.Background())
+
+	// Large batches, e.g. containing chunked html, are split so each
+	// evaluation stays below the size limit of the devtools channel.
+	for _, b := range splitBatch(js, htmlChunkSize) {
+		if _, err := u.ui.moduleEval(u.name, batchPrefix+strings.Join(b, batchSep)+batchSuffix); err != nil {
+			return u.evalError(err)
+		}
+	}
+	return nil
+}
+
+// queue queues the javascript if the module is batching,
+// returning true if it was queued.
+func (u *UIContext) queue(js string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.batching {
+		return false
+	}
+	u.batched = append(u.batched, js)
+	return true
+}
+
+// isBatching determines if the module is batching.
+func (u *UIContext) isBatching() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.batching
+}
+
+// eval evaluates the javascript, or queues it if the module is batching.
+func (u *UIContext) eval(js string) (interface{}, error) {
+	if u.queue(js) {
+		return nil, nil
+	}
+	return u.ui.moduleEval(u.name, js)
+}
+
+// splitBatch splits the batched javascript into batches of at most size
+// bytes. Javascript larger than size is batched on its own.
+func splitBatch(js []string, size int) [][]string {
+	var (
+		batches [][]string
+		n       int
+	)
+	start := 0
+	for i, s := range js {
+		if i > start && n+len(s) > size {
+			batches = append(batches, js[start:i])
+			start, n = i, 0
+		}
+		n += len(s)
+	}
+	return append(batches, js[start:])
+}
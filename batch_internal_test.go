@@ -0,0 +1,94 @@
+package glass
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIContext_Batch(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Batch(func() {
+		_ = uiCtx.LoadCSS(".a {}")
+		_ = uiCtx.LoadHTML("<p>a</p>")
+		_, _ = uiCtx.Eval("update(%d)", 1)
+		_ = uiCtx.SetVisible(false)
+	})
+
+	require.NoError(t, err)
+	evals := win.Evals()
+	require.Len(t, evals, 2)
+	assert.Equal(t, "runBatch(function () {\n"+
+		"loadCSS(`test`, `.a {}`);\n;"+
+		"loadModuleHTML(`test`, `<p>a</p>`);\n;"+
+		"update(1)\n;"+
+		`setModuleVisible("test", false);`+
+		"\n});", evals[1])
+	assert.Equal(t, "<p>a</p>", win.HTML(TestModuleName))
+	assert.False(t, uiCtx.Visible())
+}
+
+func TestUIContext_BatchEvalsOutsideBatch(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Batch(func() {})
+	require.NoError(t, err)
+	_, err = uiCtx.Eval("update(%d)", 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"update(1)"}, win.Evals()[1:])
+}
+
+func TestUIContext_BatchJoinsOuterBatch(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Batch(func() {
+		_, _ = uiCtx.Eval("a()")
+		_ = uiCtx.Batch(func() {
+			_, _ = uiCtx.Eval("b()")
+		})
+		_, _ = uiCtx.Eval("c()")
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"runBatch(function () {\na()\n;b()\n;c()\n});"}, win.Evals()[1:])
+}
+
+func TestUIContext_BatchReturnsError(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	err := win.OnEval("runBatch(function () {\na()\n});", nil, errors.New("test error"))
+	require.NoError(t, err)
+
+	err = uiCtx.Batch(func() {
+		_, _ = uiCtx.Eval("a()")
+	})
+
+	assert.EqualError(t, err, "test error")
+}
+
+func TestUIContext_BatchStopsBatchingOnPanic(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	assert.Panics(t, func() {
+		_ = uiCtx.Batch(func() {
+			_, _ = uiCtx.Eval("a()")
+			panic("test")
+		})
+	})
+	_, err := uiCtx.Eval("b()")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b()"}, win.Evals()[1:])
+}
+
+func TestSplitBatch(t *testing.T) {
+	js := []string{strings.Repeat("a", 4), strings.Repeat("b", 4), strings.Repeat("c", 12), "d"}
+
+	got := splitBatch(js, 10)
+
+	assert.Equal(t, [][]string{js[0:2], js[2:3], js[3:4]}, got)
+}
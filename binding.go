@@ -0,0 +1,106 @@
+package glass
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// checkBinding checks that fun can be bound into javascript.
+//
+// A bound function takes any number of arguments that can be decoded
+// from JSON, and returns nothing, an error, a value that can be encoded
+// to JSON, or a value and an error.
+func checkBinding(fun interface{}) error {
+	typ := reflect.TypeOf(fun)
+	if typ == nil || typ.Kind() != reflect.Func {
+		return errors.New("only functions can be bound")
+	}
+	if typ.IsVariadic() {
+		return errors.New("variadic functions cannot be bound")
+	}
+
+	for i := 0; i < typ.NumIn(); i++ {
+		if err := checkJSONType(typ.In(i), jsonUnmarshalerType, map[reflect.Type]bool{}); err != nil {
+			return fmt.Errorf("argument %d: %w", i+1, err)
+		}
+	}
+
+	switch typ.NumOut() {
+	case 0:
+	case 1:
+		if typ.Out(0).Implements(errorType) {
+			break
+		}
+		if err := checkJSONType(typ.Out(0), jsonMarshalerType, map[reflect.Type]bool{}); err != nil {
+			return fmt.Errorf("return value: %w", err)
+		}
+	case 2:
+		if typ.Out(1) != errorType {
+			return errors.New("second return value must be an error")
+		}
+		if err := checkJSONType(typ.Out(0), jsonMarshalerType, map[reflect.Type]bool{}); err != nil {
+			return fmt.Errorf("return value: %w", err)
+		}
+	default:
+		return errors.New("function may only return a value, an error or a value and an error")
+	}
+	return nil
+}
+
+// checkJSONType checks that values of typ can be converted to or from JSON.
+// Types implementing iface handle their own conversion.
+func checkJSONType(typ reflect.Type, iface reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+
+	if typ.Implements(iface) || reflect.PtrTo(typ).Implements(iface) {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return fmt.Errorf("%s cannot be converted to json", typ)
+	case reflect.Interface:
+		return nil
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return checkJSONType(typ.Elem(), iface, seen)
+	case reflect.Map:
+		switch typ.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			key := typ.Key()
+			if !key.Implements(textMarshalerType) && !reflect.PtrTo(key).Implements(textUnmarshalerType) {
+				return fmt.Errorf("%s cannot be converted to json: unsupported map key", typ)
+			}
+		}
+		return checkJSONType(typ.Elem(), iface, seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if f.Tag.Get("json") == "-" {
+				continue
+			}
+			if err := checkJSONType(f.Type, iface, seen); err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+	}
+	return nil
+}
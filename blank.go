@@ -0,0 +1,326 @@
+package glass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// BlankTopic is the event bus topic changes to the screen blanking are published on.
+const BlankTopic = "glass.blank"
+
+// DefaultBlankWakeFor is the default time the screen stays awake after a wake event.
+const DefaultBlankWakeFor = 5 * time.Minute
+
+// blankCheckInterval is the longest time between checks of the blank
+// schedule. Timers do not follow changes to the wall clock, e.g. after
+// a clock sync or resuming from suspend, so the schedule is checked
+// again at least this often.
+const blankCheckInterval = time.Minute
+
+// errDisplayPowerUnsupported is returned when the platform cannot turn the display off.
+var errDisplayPowerUnsupported = errors.New("turning the display off is not supported on this platform")
+
+// BlankConfig contains configuration for blanking the screen on a schedule.
+type BlankConfig struct {
+	// Schedule are the periods the screen is blanked in.
+	Schedule []BlankPeriod `yaml:"schedule"`
+
+	// PowerOff turns the display off while blanked, where supported.
+	PowerOff bool `yaml:"powerOff"`
+
+	// WakeTopic is the optional event bus topic waking the screen,
+	// e.g. from a presence sensor.
+	WakeTopic string `yaml:"wakeTopic"`
+
+	// WakeFor is the time the screen stays awake after a wake event.
+	// Zero means DefaultBlankWakeFor.
+	WakeFor time.Duration `yaml:"wakeFor"`
+}
+
+// BlankPeriod is a period of the day the screen is blanked in.
+// A period ending before it starts ends the following day.
+type BlankPeriod struct {
+	// From is the time of day the period starts, e.g. "23:00".
+	From string `yaml:"from"`
+	// To is the time of day the period ends, e.g. "06:30".
+	To string `yaml:"to"`
+}
+
+// Validate validates the blank configuration.
+func (c BlankConfig) Validate() error {
+	for _, p := range c.Schedule {
+		from, err := parseTimeOfDay(p.From)
+		if err != nil {
+			return fmt.Errorf("config: invalid blank schedule time %q", p.From)
+		}
+		to, err := parseTimeOfDay(p.To)
+		if err != nil {
+			return fmt.Errorf("config: invalid blank schedule time %q", p.To)
+		}
+		if from == to {
+			return fmt.Errorf("config: blank period %s-%s cannot be empty", p.From, p.To)
+		}
+	}
+	if c.WakeFor < 0 {
+		return errors.New("config: blank wake duration cannot be negative")
+	}
+	if c.WakeTopic == BlankTopic {
+		return fmt.Errorf("config: blank wake topic cannot be %q", BlankTopic)
+	}
+	return nil
+}
+
+// Enabled determines if a blank schedule has been configured.
+func (c BlankConfig) Enabled() bool {
+	return len(c.Schedule) > 0
+}
+
+func (c BlankConfig) wakeFor() time.Duration {
+	if c.WakeFor <= 0 {
+		return DefaultBlankWakeFor
+	}
+	return c.WakeFor
+}
+
+// blankPeriod is a parsed blank period.
+type blankPeriod struct {
+	from, to time.Duration
+}
+
+func (p blankPeriod) contains(d time.Duration) bool {
+	if p.from < p.to {
+		return d >= p.from && d < p.to
+	}
+	return d >= p.from || d < p.to
+}
+
+// blankPeriods returns the parsed blank schedule.
+func blankPeriods(schedule []BlankPeriod) []blankPeriod {
+	periods := make([]blankPeriod, 0, len(schedule))
+	for _, p := range schedule {
+		from, err := parseTimeOfDay(p.From)
+		if err != nil {
+			continue
+		}
+		to, err := parseTimeOfDay(p.To)
+		if err != nil {
+			continue
+		}
+		periods = append(periods, blankPeriod{from: from, to: to})
+	}
+	return periods
+}
+
+// scheduledBlank determines if the screen is blanked at now, and the time
+// of the next change. The screen is not blanked before awakeUntil.
+func scheduledBlank(periods []blankPeriod, now, awakeUntil time.Time) (bool, time.Time) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := now.Sub(midnight)
+
+	blank, next := false, midnight.AddDate(0, 0, 2)
+	for _, p := range periods {
+		if p.contains(since) {
+			blank = true
+		}
+		for _, at := range []time.Duration{p.from, p.to} {
+			t := midnight.Add(at)
+			if !t.After(now) {
+				t = midnight.AddDate(0, 0, 1).Add(at)
+			}
+			if t.Before(next) {
+				next = t
+			}
+		}
+	}
+
+	if blank && now.Before(awakeUntil) {
+		blank = false
+		if awakeUntil.Before(next) {
+			next = awakeUntil
+		}
+	}
+	return blank, next
+}
+
+// nextBlankCheck returns the time until the blank schedule is checked
+// again, given the next scheduled change.
+func nextBlankCheck(now, next time.Time) time.Duration {
+	if d := next.Sub(now); d < blankCheckInterval {
+		return d
+	}
+	return blankCheckInterval
+}
+
+// Blanked determines if the screen is blanked.
+func (r *Runtime) Blanked() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.blanked
+}
+
+// startBlank follows the blank schedule until ctx is done or the
+// schedule is restarted.
+func (r *Runtime) startBlank(ctx context.Context) {
+	r.stopBlank()
+
+	r.mu.Lock()
+	cfg := r.cfg.Blank
+	r.mu.Unlock()
+
+	if !cfg.Enabled() {
+		return
+	}
+
+	wake := make(chan struct{}, 1)
+	unsub := func() {}
+	if cfg.WakeTopic != "" {
+		unsub = r.bus.Subscribe(cfg.WakeTopic, func(Event) {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	r.mu.Lock()
+	r.blankStop = func() {
+		unsub()
+		cancel()
+		<-done
+	}
+	r.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		r.runBlankSchedule(ctx, cfg, wake)
+		r.setBlank(false, cfg.PowerOff)
+	}()
+}
+
+// runBlankSchedule blanks the screen on schedule until ctx is done.
+func (r *Runtime) runBlankSchedule(ctx context.Context, cfg BlankConfig, wake <-chan struct{}) {
+	periods := blankPeriods(cfg.Schedule)
+
+	var awakeUntil time.Time
+	for {
+		now := time.Now()
+		blank, next := scheduledBlank(periods, now, awakeUntil)
+		r.setBlank(blank, cfg.PowerOff)
+
+		timer := time.NewTimer(nextBlankCheck(now, next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-wake:
+			timer.Stop()
+			awakeUntil = time.Now().Add(cfg.wakeFor())
+		case <-timer.C:
+		}
+	}
+}
+
+// stopBlank stops following the blank schedule, waking the screen.
+func (r *Runtime) stopBlank() {
+	r.mu.Lock()
+	stop := r.blankStop
+	r.blankStop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// setBlank blanks or wakes the screen, publishing the change on BlankTopic.
+func (r *Runtime) setBlank(blank, powerOff bool) {
+	r.blankMu.Lock()
+	defer r.blankMu.Unlock()
+
+	r.mu.Lock()
+	changed := r.blanked != blank
+	r.blanked = blank
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if !blank && powerOff {
+		if err := displayOn(); err != nil {
+			r.log.Warn("could not turn display on", logCtx.Error("error", err))
+		}
+	}
+	if !blank {
+		r.mu.Lock()
+		enabled := r.blankAnimations
+		r.mu.Unlock()
+		if enabled {
+			r.setBlankAnimations(true)
+		}
+	}
+	if err := r.ui.setBlank(blank); err != nil {
+		r.log.Error("could not update screen blank", logCtx.Error("error", err))
+	}
+	if blank {
+		// Animations cannot be seen behind the blank screen. They are only
+		// resumed on wake if they were enabled before.
+		enabled := r.ui.animationsEnabled()
+		r.mu.Lock()
+		r.blankAnimations = enabled
+		r.mu.Unlock()
+		if enabled {
+			r.setBlankAnimations(false)
+		}
+	}
+	if blank && powerOff {
+		if err := displayOff(); err != nil {
+			r.log.Warn("could not turn display off", logCtx.Error("error", err))
+		}
+	}
+
+	if blank {
+		r.log.Info("screen blanked")
+	} else {
+		r.log.Info("screen woken")
+	}
+
+	b, _ := json.Marshal(struct {
+		Blank bool `json:"blank"`
+	}{Blank: blank})
+	r.bus.Publish(Event{Topic: BlankTopic, Data: b})
+}
+
+// setBlankAnimations pauses or resumes the page animations.
+func (r *Runtime) setBlankAnimations(enabled bool) {
+	if err := r.ui.SetAnimationsEnabled(enabled); err != nil {
+		r.log.Error("could not update animations", logCtx.Error("error", err))
+	}
+}
+
+// setBlank fades the black screen overlay in or out.
+func (ui *UI) setBlank(blank bool) error {
+	js := fmt.Sprintf("setScreenBlank(%t);", blank)
+	if _, err := ui.Eval(js); err != nil {
+		return err
+	}
+
+	var setup []string
+	if blank {
+		setup = []string{js}
+	}
+	ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, "setScreenBlank(")
+	}, setup)
+	return nil
+}
@@ -0,0 +1,22 @@
+package glass
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// displayOff puts the display to sleep using pmset.
+func displayOff() error {
+	if out, err := exec.Command("pmset", "displaysleepnow").CombinedOutput(); err != nil {
+		return fmt.Errorf("pmset: %w: %s", err, out)
+	}
+	return nil
+}
+
+// displayOn wakes the display by declaring user activity.
+func displayOn() error {
+	if out, err := exec.Command("caffeinate", "-u", "-t", "1").CombinedOutput(); err != nil {
+		return fmt.Errorf("caffeinate: %w: %s", err, out)
+	}
+	return nil
+}
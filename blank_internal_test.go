@@ -0,0 +1,178 @@
+package glass
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlankConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     BlankConfig
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			cfg: BlankConfig{
+				Schedule:  []BlankPeriod{{From: "23:00", To: "06:30"}},
+				WakeTopic: "presence",
+				WakeFor:   time.Minute,
+			},
+		},
+		{
+			name:    "handles invalid time",
+			cfg:     BlankConfig{Schedule: []BlankPeriod{{From: "11pm", To: "06:30"}}},
+			wantErr: `config: invalid blank schedule time "11pm"`,
+		},
+		{
+			name:    "handles empty period",
+			cfg:     BlankConfig{Schedule: []BlankPeriod{{From: "23:00", To: "23:00"}}},
+			wantErr: "config: blank period 23:00-23:00 cannot be empty",
+		},
+		{
+			name:    "handles negative wake duration",
+			cfg:     BlankConfig{WakeFor: -time.Second},
+			wantErr: "config: blank wake duration cannot be negative",
+		},
+		{
+			name:    "handles blank topic as wake topic",
+			cfg:     BlankConfig{WakeTopic: BlankTopic},
+			wantErr: `config: blank wake topic cannot be "glass.blank"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestScheduledBlank(t *testing.T) {
+	periods := blankPeriods([]BlankPeriod{{From: "23:00", To: "06:30"}, {From: "13:00", To: "14:00"}})
+	day := time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		awakeUntil time.Time
+		wantBlank  bool
+		wantNext   time.Time
+	}{
+		{
+			name:      "blank after midnight",
+			now:       day.Add(2 * time.Hour),
+			wantBlank: true,
+			wantNext:  day.Add(6*time.Hour + 30*time.Minute),
+		},
+		{
+			name:     "awake during the day",
+			now:      day.Add(8 * time.Hour),
+			wantNext: day.Add(13 * time.Hour),
+		},
+		{
+			name:      "blank in the afternoon",
+			now:       day.Add(13 * time.Hour),
+			wantBlank: true,
+			wantNext:  day.Add(14 * time.Hour),
+		},
+		{
+			name:      "blank before midnight",
+			now:       day.Add(23*time.Hour + 30*time.Minute),
+			wantBlank: true,
+			wantNext:  day.AddDate(0, 0, 1).Add(6*time.Hour + 30*time.Minute),
+		},
+		{
+			name:       "awake after a wake event",
+			now:        day.Add(2 * time.Hour),
+			awakeUntil: day.Add(2*time.Hour + 5*time.Minute),
+			wantNext:   day.Add(2*time.Hour + 5*time.Minute),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blank, next := scheduledBlank(periods, test.now, test.awakeUntil)
+
+			assert.Equal(t, test.wantBlank, blank)
+			assert.Equal(t, test.wantNext, next)
+		})
+	}
+}
+
+func TestNextBlankCheck(t *testing.T) {
+	now := time.Date(2022, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 10*time.Second, nextBlankCheck(now, now.Add(10*time.Second)))
+	assert.Equal(t, time.Minute, nextBlankCheck(now, now.Add(11*time.Hour)))
+}
+
+func TestRuntime_SetBlank(t *testing.T) {
+	win := NewRecordingWindow()
+	rt := NewRuntime(Config{}, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+
+	got := make(chan json.RawMessage, 2)
+	unsub := rt.Events().Subscribe(BlankTopic, func(e Event) {
+		got <- e.Data
+	})
+	defer unsub()
+
+	rt.setBlank(true, false)
+	assert.True(t, rt.Blanked())
+	assert.Equal(t, []string{"setScreenBlank(true);"}, rt.ui.setup)
+
+	rt.setBlank(true, false)
+	rt.setBlank(false, false)
+
+	assert.False(t, rt.Blanked())
+	assert.Empty(t, rt.ui.setup)
+	assert.Equal(t, []string{
+		"setScreenBlank(true);",
+		"setAnimations(false);",
+		"setAnimations(true);",
+		"setScreenBlank(false);",
+	}, win.Evals())
+	assert.JSONEq(t, `{"blank":true}`, string(<-got))
+	assert.JSONEq(t, `{"blank":false}`, string(<-got))
+}
+
+func TestRuntime_SetBlankKeepsDisabledAnimations(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	require.NoError(t, ui.SetAnimationsEnabled(false))
+	rt := NewRuntime(Config{}, ui, &MockModuleRunner{}, newTestLogger())
+
+	rt.setBlank(true, false)
+	rt.setBlank(false, false)
+
+	assert.Equal(t, []string{
+		"setAnimations(false);",
+		"setScreenBlank(true);",
+		"setScreenBlank(false);",
+	}, win.Evals())
+	assert.Equal(t, "setAnimations(false);", ui.animations)
+}
+
+func TestRuntime_StopBlankWakesScreen(t *testing.T) {
+	win := NewRecordingWindow()
+	cfg := Config{Blank: BlankConfig{Schedule: []BlankPeriod{{From: "00:00", To: "00:01"}}}}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	rt.startBlank(context.Background())
+	rt.setBlank(true, false)
+
+	rt.stopBlank()
+
+	require.False(t, rt.Blanked())
+	assert.Equal(t, "setScreenBlank(false);", win.Evals()[len(win.Evals())-1])
+}
@@ -0,0 +1,11 @@
+package glass
+
+// displayOff turns the display off using DPMS.
+func displayOff() error {
+	return xset("dpms", "force", "off")
+}
+
+// displayOn turns the display on using DPMS.
+func displayOn() error {
+	return xset("dpms", "force", "on")
+}
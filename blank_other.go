@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package glass
+
+func displayOff() error { return errDisplayPowerUnsupported }
+
+func displayOn() error { return errDisplayPowerUnsupported }
@@ -0,0 +1,131 @@
+package glass
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/glasslabs/looking-glass/internal/i18n"
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+const (
+	defaultChartWidth  = 300
+	defaultChartHeight = 150
+)
+
+var chartColors = []string{"#fff", "#999", "#666", "#ccc", "#444"}
+
+// renderChart renders the chart spec as an svg.
+func renderChart(spec types.ChartSpec, lang *i18n.Catalog) (string, error) {
+	w, h := spec.Width, spec.Height
+	if w <= 0 {
+		w = defaultChartWidth
+	}
+	if h <= 0 {
+		h = defaultChartHeight
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<svg class="chart chart-%s" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		html.EscapeString(spec.Type), w, h, w, h))
+
+	minVal, maxVal, n := chartBounds(spec.Series)
+	switch {
+	case spec.Type != types.LineChart && spec.Type != types.BarChart:
+		return "", fmt.Errorf("unsupported chart type %q", spec.Type)
+	case n == 0:
+		sb.WriteString(fmt.Sprintf(`<text class="chart-empty" x="%d" y="%d" text-anchor="middle" fill="currentColor">%s</text>`,
+			w/2, h/2, html.EscapeString(lang.T("chart.noData"))))
+	case spec.Type == types.LineChart:
+		renderLines(&sb, spec.Series, w, h, minVal, maxVal, n)
+	default:
+		renderBars(&sb, spec.Series, w, h, minVal, maxVal, n)
+	}
+
+	sb.WriteString("</svg>")
+	return sb.String(), nil
+}
+
+// chartBounds returns the minimum and maximum values, and the
+// maximum number of values in the series.
+func chartBounds(series []types.Series) (minVal, maxVal float64, n int) {
+	minVal, maxVal = math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		if len(s.Values) > n {
+			n = len(s.Values)
+		}
+		for _, v := range s.Values {
+			minVal = math.Min(minVal, v)
+			maxVal = math.Max(maxVal, v)
+		}
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	if minVal == maxVal {
+		minVal--
+		maxVal++
+	}
+	return minVal, maxVal, n
+}
+
+func renderLines(sb *strings.Builder, series []types.Series, w, h int, minVal, maxVal float64, n int) {
+	step := float64(w)
+	if n > 1 {
+		step = float64(w) / float64(n-1)
+	}
+
+	for i, s := range series {
+		if len(s.Values) == 0 {
+			continue
+		}
+
+		pts := make([]string, len(s.Values))
+		for j, v := range s.Values {
+			x := float64(j) * step
+			y := scale(v, minVal, maxVal, h)
+			pts[j] = formatFloat(x) + "," + formatFloat(y)
+		}
+		sb.WriteString(fmt.Sprintf(`<polyline class="chart-series" data-name="%s" fill="none" stroke="%s" stroke-width="2" points="%s"/>`,
+			html.EscapeString(s.Name), html.EscapeString(seriesColor(s, i)), strings.Join(pts, " ")))
+	}
+}
+
+func renderBars(sb *strings.Builder, series []types.Series, w, h int, minVal, maxVal float64, n int) {
+	// Bars grow from zero where possible.
+	minVal = math.Min(minVal, 0)
+	maxVal = math.Max(maxVal, 0)
+	base := scale(0, minVal, maxVal, h)
+
+	group := float64(w) / float64(n)
+	bar := group / float64(len(series)+1)
+	for i, s := range series {
+		for j, v := range s.Values {
+			x := float64(j)*group + bar/2 + float64(i)*bar
+			y := scale(v, minVal, maxVal, h)
+			top, height := math.Min(y, base), math.Abs(base-y)
+			sb.WriteString(fmt.Sprintf(`<rect class="chart-series" data-name="%s" fill="%s" x="%s" y="%s" width="%s" height="%s"/>`,
+				html.EscapeString(s.Name), html.EscapeString(seriesColor(s, i)),
+				formatFloat(x), formatFloat(top), formatFloat(bar), formatFloat(height)))
+		}
+	}
+}
+
+// scale scales v into the chart height, inverting the y axis.
+func scale(v, minVal, maxVal float64, h int) float64 {
+	return float64(h) - (v-minVal)/(maxVal-minVal)*float64(h)
+}
+
+func seriesColor(s types.Series, i int) string {
+	if s.Color != "" {
+		return s.Color
+	}
+	return chartColors[i%len(chartColors)]
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
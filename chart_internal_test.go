@@ -0,0 +1,126 @@
+package glass
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/internal/i18n"
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderChart(t *testing.T) {
+	tests := []struct {
+		name string
+		spec types.ChartSpec
+		want string
+	}{
+		{
+			name: "line chart",
+			spec: types.ChartSpec{
+				Type:   types.LineChart,
+				Width:  100,
+				Height: 50,
+				Series: []types.Series{{Name: "temp", Values: []float64{0, 5, 10}}},
+			},
+			want: `<svg class="chart chart-line" xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">` +
+				`<polyline class="chart-series" data-name="temp" fill="none" stroke="#fff" stroke-width="2" points="0,50 50,25 100,0"/>` +
+				`</svg>`,
+		},
+		{
+			name: "bar chart",
+			spec: types.ChartSpec{
+				Type:   types.BarChart,
+				Width:  100,
+				Height: 50,
+				Series: []types.Series{{Name: "rain", Color: "blue", Values: []float64{5, 10}}},
+			},
+			want: `<svg class="chart chart-bar" xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">` +
+				`<rect class="chart-series" data-name="rain" fill="blue" x="12.5" y="25" width="25" height="25"/>` +
+				`<rect class="chart-series" data-name="rain" fill="blue" x="62.5" y="0" width="25" height="50"/>` +
+				`</svg>`,
+		},
+		{
+			name: "empty series",
+			spec: types.ChartSpec{
+				Type:   types.LineChart,
+				Width:  100,
+				Height: 50,
+			},
+			want: `<svg class="chart chart-line" xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">` +
+				`<text class="chart-empty" x="50" y="25" text-anchor="middle" fill="currentColor">No data</text>` +
+				`</svg>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := renderChart(test.spec, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRenderChart_DifferentSeriesRenderDifferently(t *testing.T) {
+	spec1 := types.ChartSpec{
+		Type:   types.LineChart,
+		Series: []types.Series{{Name: "temp", Values: []float64{1, 2, 3}}},
+	}
+	spec2 := types.ChartSpec{
+		Type:   types.LineChart,
+		Series: []types.Series{{Name: "temp", Values: []float64{3, 1, 2}}},
+	}
+
+	got1, err := renderChart(spec1, nil)
+	require.NoError(t, err)
+	got2, err := renderChart(spec2, nil)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, got1, got2)
+}
+
+func TestRenderChart_UsesCatalog(t *testing.T) {
+	lang, err := i18n.Load("de", "internal/i18n/testdata")
+	require.NoError(t, err)
+
+	got, err := renderChart(types.ChartSpec{Type: types.LineChart}, lang)
+
+	require.NoError(t, err)
+	assert.Contains(t, got, ">Keine Daten</text>")
+}
+
+func TestRenderChart_HandlesUnknownType(t *testing.T) {
+	_, err := renderChart(types.ChartSpec{Type: "pie"}, nil)
+
+	assert.EqualError(t, err, `unsupported chart type "pie"`)
+}
+
+func TestUIContext_RenderChart(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, "loadModuleHTML(`test`, `<svg class=\"chart chart-bar\"")
+	})).Return(emptyVal)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.RenderChart(types.ChartSpec{
+		Type:   types.BarChart,
+		Series: []types.Series{{Name: "rain", Values: []float64{1, 2}}},
+	})
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
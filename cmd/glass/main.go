@@ -15,6 +15,8 @@ const (
 	flagConfigFile  = "config"
 	flagSecretsFile = "secrets"
 	flagModPath     = "modules"
+	flagRecordFile  = "record"
+	flagFast        = "fast"
 )
 
 var version = "¯\\_(ツ)_/¯"
@@ -44,9 +46,64 @@ var commands = []*cli.Command{
 				EnvVars:  []string{"MODULES"},
 				Required: true,
 			},
+			&cli.StringFlag{
+				Name:  flagRecordFile,
+				Usage: "The path to record the session to.",
+			},
 		}.Merge(cmd.LogFlags),
 		Action: run,
 	},
+	{
+		Name:  "validate",
+		Usage: "Validate the configuration without running looking glass",
+		Flags: cmd.Flags{
+			&cli.StringFlag{
+				Name:    flagSecretsFile,
+				Aliases: []string{"s"},
+				Usage:   "The path to the secrets file.",
+				EnvVars: []string{"SECRETS"},
+			},
+			&cli.StringFlag{
+				Name:     flagConfigFile,
+				Aliases:  []string{"c"},
+				Usage:    "The path to the configuration file.",
+				EnvVars:  []string{"CONFIG"},
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagModPath,
+				Aliases: []string{"m"},
+				Usage:   "The path to the modules. Module paths are not checked if empty.",
+				EnvVars: []string{"MODULES"},
+			},
+		},
+		Action: validate,
+	},
+	{
+		Name:      "replay",
+		Usage:     "Replay a recorded session",
+		ArgsUsage: "<file>",
+		Flags: cmd.Flags{
+			&cli.StringFlag{
+				Name:    flagSecretsFile,
+				Aliases: []string{"s"},
+				Usage:   "The path to the secrets file.",
+				EnvVars: []string{"SECRETS"},
+			},
+			&cli.StringFlag{
+				Name:     flagConfigFile,
+				Aliases:  []string{"c"},
+				Usage:    "The path to the configuration file.",
+				EnvVars:  []string{"CONFIG"},
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  flagFast,
+				Usage: "Replay the session without the recorded timing.",
+			},
+		}.Merge(cmd.LogFlags),
+		Action: replay,
+	},
 }
 
 func main() {
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	glass "github.com/glasslabs/looking-glass"
+	"github.com/urfave/cli/v2"
+)
+
+func replay(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("a session file is required")
+	}
+
+	secrets, err := loadSecrets(c.String(flagSecretsFile))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(c.String(flagConfigFile), secrets)
+	if err != nil {
+		return err
+	}
+
+	log, err := newLogger(c, cfg.Log)
+	if err != nil {
+		return err
+	}
+	cancel := log.WithTimestamp()
+	defer cancel()
+
+	f, err := os.Open(filepath.Clean(c.Args().First()))
+	if err != nil {
+		return fmt.Errorf("could not open session file: %w", err)
+	}
+	events, err := glass.ReadSession(f)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+
+	ui, err := glass.NewUI(cfg.UI, log)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = ui.Close()
+	}()
+
+	if err = glass.ReplaySession(c.Context, ui, events, !c.Bool(flagFast)); err != nil {
+		return err
+	}
+
+	select {
+	case <-ui.Done():
+	case <-c.Context.Done():
+	}
+
+	return nil
+}
@@ -1,38 +1,43 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
 	glass "github.com/glasslabs/looking-glass"
-	"github.com/glasslabs/looking-glass/internal/logadpt"
 	"github.com/glasslabs/looking-glass/module"
 	"github.com/hamba/cmd/v2"
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
 	"github.com/urfave/cli/v2"
 )
 
 const proxyURL = "https://proxy.golang.org"
 
 func run(c *cli.Context) error {
-	log, err := cmd.NewLogger(c)
+	secrets, err := loadSecrets(c.String(flagSecretsFile))
 	if err != nil {
 		return err
 	}
-	cancel := log.WithTimestamp()
-	defer cancel()
 
-	secrets, err := loadSecrets(c.String(flagSecretsFile))
+	cfg, err := loadConfig(c.String(flagConfigFile), secrets)
 	if err != nil {
 		return err
 	}
 
-	cfg, err := loadConfig(c.String(flagConfigFile), secrets)
+	log, err := newLogger(c, cfg.Log)
 	if err != nil {
 		return err
 	}
+	cancel := log.WithTimestamp()
+	defer cancel()
 
-	ui, err := glass.NewUI(cfg.UI)
+	ui, err := glass.NewUI(cfg.UI, log)
 	if err != nil {
 		return err
 	}
@@ -40,11 +45,27 @@ func run(c *cli.Context) error {
 		_ = ui.Close()
 	}()
 
+	if path := c.String(flagRecordFile); path != "" {
+		f, err := os.Create(filepath.Clean(path))
+		if err != nil {
+			return fmt.Errorf("could not create record file: %w", err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		ui.Record(f)
+	}
+
 	modPath := c.String(flagModPath)
-	cachePath, err := ensureCachePath(modPath)
+	cachePath, err := ensurePath(modPath, "cache")
 	if err != nil {
 		return err
 	}
+	storePath, err := ensurePath(modPath, "store")
+	if err != nil {
+		return err
+	}
+	ui.SetStoreDir(storePath)
 	client, err := newModuleClient(proxyURL, cachePath)
 	if err != nil {
 		return err
@@ -54,30 +75,155 @@ func run(c *cli.Context) error {
 		return err
 	}
 	svc.Debug = log.Debug
+
+	assets, err := glass.NewAssetServer()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = assets.Close()
+	}()
 	for _, desc := range cfg.Modules {
-		if err = svc.Extract(desc); err != nil {
-			return err
-		}
+		assets.Register(desc.InstanceID(), svc.Dir(desc))
+	}
+	ui.UseHTMLTransformer(assets.Transformer())
+
+	rt := glass.NewRuntime(cfg, ui, svc, log)
+	defer func() {
+		_ = rt.Close()
+	}()
 
-		uiCtx, err := glass.NewUIContext(ui, desc.Name, desc.Position)
+	if cfg.Server.Addr != "" {
+		srv := &http.Server{Addr: cfg.Server.Addr, Handler: glass.NewServer(rt)}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Error("server error", ctx.Error("error", err))
+			}
+		}()
+		defer func() {
+			_ = srv.Close()
+		}()
+	}
+
+	if cfg.MQTT.Enabled() {
+		// The mirror is still useful without the broker, so it keeps running.
+		closeMQTT, err := startMQTT(cfg.MQTT, rt.Events(), log)
 		if err != nil {
-			return err
+			log.Error("could not bridge events to mqtt", ctx.Error("error", err))
+		} else {
+			defer closeMQTT()
 		}
-		mod, err := svc.Run(c.Context, desc, uiCtx, logadpt.LogAdapter{Log: log})
-		if err != nil {
-			return err
+	}
+
+	if err = rt.Load(c.Context); err != nil {
+		return err
+	}
+
+	gov := glass.NewRestartGovernor(cfg.Restart)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	shutdown := func() error {
+		log.Info("shutting down")
+
+		if err := rt.Shutdown(); err != nil {
+			// Modules are still running, force the exit.
+			log.Error("could not shut down cleanly", ctx.Error("error", err))
+			os.Exit(1)
+		}
+		return nil
+	}
+
+	done := ui.Done()
+	for {
+		select {
+		case <-done:
+			if !cfg.Restart.Enabled {
+				return nil
+			}
+			log.Error("chrome stopped unexpectedly, relaunching")
+
+			err = ui.Recover(c.Context, gov, log)
+			switch {
+			case c.Context.Err() != nil:
+				// The window is gone, so selecting again could recover
+				// it again instead of shutting down.
+				return shutdown()
+			case errors.Is(err, glass.ErrRestartLimit):
+				// The window shows the error until shut down, and is
+				// not relaunched again.
+				log.Error("chrome restart limit reached, no longer relaunching")
+				done = nil
+				continue
+			case err != nil:
+				return err
+			}
+			done = ui.Done()
+			log.Info("chrome relaunched")
+		case <-c.Context.Done():
+			return shutdown()
+		case <-hup:
+			log.Info("reloading configuration")
+
+			newCfg, err := loadConfig(c.String(flagConfigFile), secrets)
+			if err != nil {
+				log.Error("could not reload configuration", ctx.Error("error", err))
+				continue
+			}
+			for _, desc := range newCfg.Modules {
+				assets.Register(desc.InstanceID(), svc.Dir(desc))
+			}
+			if err = rt.Reload(c.Context, newCfg); err != nil {
+				log.Error("could not reload modules", ctx.Error("error", err))
+			}
 		}
-		defer func() {
-			_ = mod.Close()
-		}()
 	}
+}
 
-	select {
-	case <-ui.Done():
-	case <-c.Context.Done():
+// newLogger returns a logger configured by cfg. Log flags and
+// environment variables take precedence over the configuration.
+func newLogger(c *cli.Context, cfg glass.LogConfig) (*logger.Logger, error) {
+	if c.IsSet(cmd.FlagLogLevel) {
+		cfg.Level = c.String(cmd.FlagLogLevel)
+	}
+	if c.IsSet(cmd.FlagLogFormat) {
+		cfg.Format = c.String(cmd.FlagLogFormat)
 	}
 
-	return nil
+	log, err := glass.NewLogger(os.Stdout, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := cmd.Split(c.StringSlice(cmd.FlagLogCtx), "=")
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]logger.Field, len(tags))
+	for i, t := range tags {
+		fields[i] = ctx.Str(t[0], t[1])
+	}
+	return log.With(fields...), nil
+}
+
+// startMQTT bridges the event bus to the broker in cfg, returning
+// a function that stops the bridge and disconnects.
+func startMQTT(cfg glass.MQTTConfig, bus *glass.EventBus, log *logger.Logger) (func(), error) {
+	conn, err := glass.DialMQTT(cfg)
+	if err != nil {
+		return nil, err
+	}
+	bridge, err := glass.NewMQTTBridge(bus, conn, cfg.Topics, log)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return func() {
+		_ = bridge.Close()
+		_ = conn.Close()
+	}, nil
 }
 
 func loadSecrets(file string) (map[string]interface{}, error) {
@@ -96,14 +242,10 @@ func loadSecrets(file string) (map[string]interface{}, error) {
 	return s, nil
 }
 
-func loadConfig(file string, secrets map[string]interface{}) (glass.Config, error) {
-	in, err := os.ReadFile(file)
-	if err != nil {
-		return glass.Config{}, fmt.Errorf("could not read configuration file: %w", err)
-	}
-	cfg, err := glass.ParseConfig(in, filepath.Dir(file), secrets)
+func loadConfig(path string, secrets map[string]interface{}) (glass.Config, error) {
+	cfg, err := glass.LoadConfig(path, secrets)
 	if err != nil {
-		return glass.Config{}, fmt.Errorf("could not parse configuration file: %w", err)
+		return glass.Config{}, err
 	}
 	if err = cfg.Validate(); err != nil {
 		return glass.Config{}, err
@@ -111,13 +253,13 @@ func loadConfig(file string, secrets map[string]interface{}) (glass.Config, erro
 	return cfg, nil
 }
 
-func ensureCachePath(modPath string) (string, error) {
-	p := filepath.Join(modPath, "cache")
+func ensurePath(modPath, name string) (string, error) {
+	p := filepath.Join(modPath, name)
 	if _, err := os.Stat(p); err == nil {
 		return p, nil
 	}
 	if err := os.MkdirAll(p, 0o750); err != nil {
-		return "", fmt.Errorf("could not create cache path %q: %w", p, err)
+		return "", fmt.Errorf("could not create %s path %q: %w", name, p, err)
 	}
 	return p, nil
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	glass "github.com/glasslabs/looking-glass"
+	"github.com/urfave/cli/v2"
+)
+
+func validate(c *cli.Context) error {
+	secrets, err := loadSecrets(c.String(flagSecretsFile))
+	if err != nil {
+		return err
+	}
+
+	ui := newTerm()
+	err = glass.ValidateConfig(c.String(flagConfigFile), secrets, c.String(flagModPath))
+	if err == nil {
+		ui.Info("Configuration is valid")
+		return nil
+	}
+	var errs glass.ConfigErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	for _, err := range errs {
+		ui.Error(err.Error())
+	}
+	return fmt.Errorf("configuration has %d problem(s)", len(errs))
+}
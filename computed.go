@@ -0,0 +1,191 @@
+package glass
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/glasslabs/looking-glass/internal/expr"
+	"gopkg.in/yaml.v3"
+)
+
+// computedPrefix marks a configuration value as computed.
+const computedPrefix = "="
+
+type computedValue struct {
+	node   *yaml.Node
+	parent []string
+	expr   *expr.Expr
+
+	resolving bool
+	resolved  bool
+}
+
+// computeValues evaluates the computed values in the document.
+//
+// A computed value is a string starting with "=" followed by an expression,
+// e.g. "=width * 9 / 16". Identifiers are resolved against the mapping
+// containing the value first, then against the document root. A value
+// starting with "==" is a literal string starting with "=".
+func computeValues(doc *yaml.Node) error {
+	root := doc
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return nil
+		}
+		root = root.Content[0]
+	}
+
+	c := &computer{root: root, vals: map[string]*computedValue{}}
+	if err := c.collect(root, nil); err != nil {
+		return err
+	}
+	for _, path := range c.order {
+		if err := c.resolve(path, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type computer struct {
+	root  *yaml.Node
+	vals  map[string]*computedValue
+	order []string
+}
+
+func (c *computer) collect(n *yaml.Node, path []string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if err := c.collect(n.Content[i+1], appendPath(path, n.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range n.Content {
+			if err := c.collect(child, appendPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if n.Tag != "!!str" || !strings.HasPrefix(n.Value, computedPrefix) {
+			return nil
+		}
+		if strings.HasPrefix(n.Value, computedPrefix+computedPrefix) {
+			n.Value = strings.TrimPrefix(n.Value, computedPrefix)
+			return nil
+		}
+
+		if len(path) == 0 {
+			return nil
+		}
+		name := strings.Join(path, ".")
+		e, err := expr.Parse(strings.TrimPrefix(n.Value, computedPrefix))
+		if err != nil {
+			return fmt.Errorf("config: invalid expression for %q: %w", name, err)
+		}
+		c.vals[name] = &computedValue{node: n, parent: path[:len(path)-1], expr: e}
+		c.order = append(c.order, name)
+	}
+	return nil
+}
+
+func (c *computer) resolve(name string, chain []string) error {
+	val := c.vals[name]
+	if val.resolved {
+		return nil
+	}
+	chain = append(chain, name)
+	if val.resolving {
+		return fmt.Errorf("config: cyclic reference: %s", strings.Join(chain, " -> "))
+	}
+	val.resolving = true
+
+	ctx := map[string]interface{}{}
+	for _, id := range val.expr.Idents() {
+		path := strings.Split(id, ".")
+		target, n := c.lookup(appendPath(val.parent, path...))
+		if n == nil {
+			target, n = c.lookup(path)
+		}
+		if n == nil {
+			continue
+		}
+		if _, ok := c.vals[target]; ok {
+			if err := c.resolve(target, chain); err != nil {
+				return err
+			}
+		}
+
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return fmt.Errorf("config: could not read %q: %w", target, err)
+		}
+		setPath(ctx, path, v)
+	}
+
+	res := val.expr.Value(ctx)
+	switch v := res.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			val.node.Tag, val.node.Value = "!!int", strconv.FormatInt(int64(v), 10)
+			break
+		}
+		val.node.Tag, val.node.Value = "!!float", strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		val.node.Tag, val.node.Value = "!!bool", strconv.FormatBool(v)
+	case string:
+		val.node.Tag, val.node.Value = "!!str", v
+	default:
+		return fmt.Errorf("config: expression for %q has no value", name)
+	}
+	val.node.Style = 0
+
+	val.resolving = false
+	val.resolved = true
+	return nil
+}
+
+// lookup returns the node at path, along with its full name.
+func (c *computer) lookup(path []string) (string, *yaml.Node) {
+	n := c.root
+	for _, seg := range path {
+		switch n.Kind {
+		case yaml.MappingNode:
+			idx := mappingIndex(n, seg)
+			if idx < 0 {
+				return "", nil
+			}
+			n = n.Content[idx]
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return "", nil
+			}
+			n = n.Content[i]
+		default:
+			return "", nil
+		}
+	}
+	return strings.Join(path, "."), n
+}
+
+func appendPath(path []string, segs ...string) []string {
+	res := make([]string, 0, len(path)+len(segs))
+	res = append(res, path...)
+	return append(res, segs...)
+}
+
+func setPath(m map[string]interface{}, path []string, v interface{}) {
+	for _, seg := range path[:len(path)-1] {
+		next, ok := m[seg].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[seg] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = v
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/glasslabs/looking-glass/module"
 	"gopkg.in/yaml.v3"
@@ -21,58 +23,278 @@ func ParseSecrets(in []byte) (map[string]interface{}, error) {
 
 // Config contains the main configuration.
 type Config struct {
-	UI      UIConfig            `yaml:"ui"`
-	Modules []module.Descriptor `yaml:"modules"`
+	Log        LogConfig              `yaml:"log"`
+	UI         UIConfig               `yaml:"ui"`
+	Layout     LayoutConfig           `yaml:"layout"`
+	Themes     ThemesConfig           `yaml:"themes"`
+	Blank      BlankConfig            `yaml:"blank"`
+	Background BackgroundConfig       `yaml:"background"`
+	Activity   ActivityConfig         `yaml:"activity"`
+	Loader     LoaderConfig           `yaml:"loader"`
+	Network    NetworkConfig          `yaml:"network"`
+	HTTP       HTTPConfig             `yaml:"http"`
+	Restart    RestartConfig          `yaml:"restart"`
+	Server     ServerConfig           `yaml:"server"`
+	MQTT       MQTTConfig             `yaml:"mqtt"`
+	Shutdown   ShutdownConfig         `yaml:"shutdown"`
+	Dev        bool                   `yaml:"dev"`
+	Features   map[string]bool        `yaml:"features"`
+	Vars       map[string]interface{} `yaml:"vars"`
+	Defaults   map[string]yaml.Node   `yaml:"defaults"`
+	Modules    []module.Descriptor    `yaml:"modules"`
 }
 
-// Validate validates the configuration.
+// Validate validates the configuration, returning the first problem found.
 func (c Config) Validate() error {
-	if err := c.UI.Validate(); err != nil {
-		return err
+	if errs := c.validate(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// validate returns all problems with the configuration.
+func (c Config) validate() []error {
+	var errs []error
+	for _, v := range []interface{ Validate() error }{c.Log, c.UI, c.Layout, c.Themes, c.Blank, c.Background, c.Activity, c.Loader, c.Network, c.HTTP, c.Restart, c.Shutdown, c.MQTT} {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Keeping the display awake would turn it back on.
+	if c.Blank.PowerOff && c.UI.PreventSleep {
+		errs = append(errs, errors.New("config: blank power off cannot be used with ui prevent sleep"))
 	}
 
 	if len(c.Modules) == 0 {
-		return errors.New("config: at least one module is required")
+		return append(errs, errors.New("config: at least one module is required"))
 	}
 	seen := map[string]bool{}
 	pathVer := map[string]string{}
 	for _, mod := range c.Modules {
 		if err := mod.Validate(); err != nil {
-			return err
+			errs = append(errs, err)
+			continue
 		}
-		if seen[mod.Name] {
-			return fmt.Errorf("config: module name %q is a duplicate. module names must be unique", mod.Name)
+		if mod.Area != "" && !c.Layout.HasArea(mod.Area) {
+			errs = append(errs, fmt.Errorf("%s: area %q is not defined in the layout", mod.InstanceID(), mod.Area))
 		}
-		seen[mod.Name] = true
+		if mod.Grid != nil && mod.Grid.Column+mod.Grid.ColumnSpan()-1 > c.UI.gridColumns() {
+			errs = append(errs, fmt.Errorf("%s: grid placement does not fit in %d columns", mod.InstanceID(), c.UI.gridColumns()))
+		}
+		switch {
+		case seen[mod.InstanceID()] && mod.ID != "":
+			errs = append(errs, fmt.Errorf("config: module id %q is a duplicate. module ids must be unique", mod.ID))
+		case seen[mod.InstanceID()]:
+			errs = append(errs, fmt.Errorf("config: module name %q is a duplicate. module names must be unique", mod.Name))
+		}
+		seen[mod.InstanceID()] = true
 
 		ver, ok := pathVer[mod.Path]
 		if ok && ver != mod.Version {
-			return fmt.Errorf("config: module %q has mismatched versions (%s != %s)", mod.Path, mod.Version, ver)
+			errs = append(errs, fmt.Errorf("config: module %q has mismatched versions (%s != %s)", mod.Path, mod.Version, ver))
+			continue
 		}
 		pathVer[mod.Path] = mod.Version
 	}
+	errs = append(errs, checkDependencies(c.Modules)...)
 
-	return nil
+	return errs
 }
 
 func defaultConfig() Config {
 	return Config{
 		UI: UIConfig{
-			Width:      640,
-			Height:     480,
-			Fullscreen: true,
+			Width:       640,
+			Height:      480,
+			Fullscreen:  true,
+			LoadTimeout: 10 * time.Second,
+		},
+		Activity: ActivityConfig{
+			IdleAfter: DefaultIdleAfter,
+		},
+		Network: NetworkConfig{
+			ProbeURL: "https://proxy.golang.org",
+			Timeout:  5 * time.Second,
+			Interval: 2 * time.Second,
+			MaxWait:  2 * time.Minute,
+
+			MonitorInterval: 30 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			Timeout: DefaultHTTPTimeout,
+			Retries: 2,
+		},
+		Restart: RestartConfig{
+			MinInterval: 30 * time.Second,
+			MaxPerHour:  10,
 		},
 	}
 }
 
-// ParseConfig parses configuration from in.
+// LoadConfig loads the configuration from path.
+//
+// If path is a directory, all yaml files in the directory are loaded in
+// lexical order, with their modules merged.
+func LoadConfig(path string, secrets map[string]interface{}) (Config, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read configuration file: %w", err)
+	}
+	if fi.IsDir() {
+		return loadConfigDir(path, secrets)
+	}
+
+	in, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read configuration file: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read configuration file: %w", err)
+	}
+	cfg, err := parseConfig(in, filepath.Ext(path), filepath.Dir(path), secrets, []string{abs})
+	if err != nil {
+		return Config{}, fmt.Errorf("could not parse configuration file: %w", err)
+	}
+	return cfg, nil
+}
+
+func loadConfigDir(dir string, secrets map[string]interface{}) (Config, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read configuration directory: %w", err)
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	var mods *yaml.Node
+	keyFiles := map[string]string{}
+	modFiles := map[string]string{}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || !configExts[strings.ToLower(ext)] {
+			continue
+		}
+
+		in, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return Config{}, fmt.Errorf("could not read configuration file: %w", err)
+		}
+		b, err := executeTemplate(in, dir, secrets)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		n, err := decodeConfig(b, ext)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		abs, err := filepath.Abs(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return Config{}, fmt.Errorf("could not read configuration file: %w", err)
+		}
+		if err = includeConfigs(n, dir, secrets, []string{abs}); err != nil {
+			return Config{}, err
+		}
+		if len(n.Content) == 0 {
+			continue
+		}
+		root := n.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return Config{}, fmt.Errorf("%s: configuration must be a mapping", e.Name())
+		}
+
+		for i := 0; i < len(root.Content); i += 2 {
+			key, val := root.Content[i], root.Content[i+1]
+			if key.Value != "modules" {
+				if file, ok := keyFiles[key.Value]; ok {
+					return Config{}, fmt.Errorf("config: %q in %s is already defined in %s", key.Value, e.Name(), file)
+				}
+				keyFiles[key.Value] = e.Name()
+				doc.Content = append(doc.Content, key, val)
+				continue
+			}
+
+			if val.Kind != yaml.SequenceNode {
+				return Config{}, fmt.Errorf("%s: modules must be a list", e.Name())
+			}
+			for _, mod := range val.Content {
+				idx := mappingIndex(mod, "name")
+				if idx < 0 {
+					continue
+				}
+				name := mod.Content[idx].Value
+				if file, ok := modFiles[name]; ok {
+					return Config{}, fmt.Errorf("config: module name %q in %s is already defined in %s", name, e.Name(), file)
+				}
+				modFiles[name] = e.Name()
+			}
+			if mods == nil {
+				mods = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+				doc.Content = append(doc.Content, key, mods)
+			}
+			mods.Content = append(mods.Content, val.Content...)
+		}
+	}
+
+	if err = expandEnv(doc); err != nil {
+		return Config{}, err
+	}
+	if err = computeValues(doc); err != nil {
+		return Config{}, err
+	}
+
+	cfg := defaultConfig()
+	if err = doc.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("could not parse configuration: %w", err)
+	}
+	err = cfg.applyDefaults()
+	return cfg, err
+}
+
+// ParseConfig parses yaml configuration from in.
 func ParseConfig(in []byte, cfgPath string, secrets map[string]interface{}) (Config, error) {
+	return parseConfig(in, ".yaml", cfgPath, secrets, nil)
+}
+
+// parseConfig parses configuration from in, in the format of the file extension ext.
+// The files being loaded are given in loading, to detect include cycles.
+func parseConfig(in []byte, ext, cfgPath string, secrets map[string]interface{}, loading []string) (Config, error) {
 	cfg := defaultConfig()
 
+	b, err := executeTemplate(in, cfgPath, secrets)
+	if err != nil {
+		return cfg, err
+	}
+
+	doc, err := decodeConfig(b, ext)
+	if err != nil {
+		return cfg, err
+	}
+	if doc.Kind == 0 {
+		return cfg, nil
+	}
+	if err = includeConfigs(doc, cfgPath, secrets, loading); err != nil {
+		return cfg, err
+	}
+	if err = expandEnv(doc); err != nil {
+		return cfg, err
+	}
+	if err = computeValues(doc); err != nil {
+		return cfg, err
+	}
+	if err = doc.Decode(&cfg); err != nil {
+		return cfg, err
+	}
+
+	err = cfg.applyDefaults()
+	return cfg, err
+}
+
+// executeTemplate executes the configuration template in.
+func executeTemplate(in []byte, cfgPath string, secrets map[string]interface{}) ([]byte, error) {
 	tmpl, err := template.New("config").
 		Parse(string(in))
 	if err != nil {
-		return cfg, fmt.Errorf("invalid configuration template: %w", err)
+		return nil, fmt.Errorf("invalid configuration template: %w", err)
 	}
 	var buf bytes.Buffer
 	err = tmpl.Execute(&buf, map[string]interface{}{
@@ -81,11 +303,84 @@ func ParseConfig(in []byte, cfgPath string, secrets map[string]interface{}) (Con
 		"Env":        getEnvVars(),
 	})
 	if err != nil {
-		return cfg, fmt.Errorf("invalid configuration template: %w", err)
+		return nil, fmt.Errorf("invalid configuration template: %w", err)
 	}
+	return buf.Bytes(), nil
+}
 
-	err = yaml.Unmarshal(buf.Bytes(), &cfg)
-	return cfg, err
+// applyDefaults merges the module type defaults under each module configuration.
+func (c *Config) applyDefaults() error {
+	for i, mod := range c.Modules {
+		def, ok := c.Defaults[mod.Path]
+		if !ok {
+			continue
+		}
+		if def.Kind != yaml.MappingNode {
+			return fmt.Errorf("config: defaults for module %q must be a mapping", mod.Path)
+		}
+
+		cfg, err := mergeNodes(&def, &mod.Config)
+		if err != nil {
+			return fmt.Errorf("%s: could not apply defaults: %w", mod.InstanceID(), err)
+		}
+		c.Modules[i].Config = *cfg
+	}
+	return nil
+}
+
+// mergeNodes merges the mapping node src over the mapping node dst,
+// returning a new node. Values in src take precedence.
+func mergeNodes(dst, src *yaml.Node) (*yaml.Node, error) {
+	if src.Kind == 0 {
+		return copyNode(dst), nil
+	}
+	if src.Kind != yaml.MappingNode {
+		return nil, errors.New("module config must be a mapping")
+	}
+
+	res := copyNode(dst)
+	for i := 0; i < len(src.Content); i += 2 {
+		key, val := src.Content[i], src.Content[i+1]
+
+		idx := mappingIndex(res, key.Value)
+		if idx < 0 {
+			res.Content = append(res.Content, copyNode(key), copyNode(val))
+			continue
+		}
+
+		if res.Content[idx].Kind == yaml.MappingNode && val.Kind == yaml.MappingNode {
+			merged, err := mergeNodes(res.Content[idx], val)
+			if err != nil {
+				return nil, err
+			}
+			res.Content[idx] = merged
+			continue
+		}
+		res.Content[idx] = copyNode(val)
+	}
+	return res, nil
+}
+
+// mappingIndex returns the index of the value node for key in a mapping node,
+// or -1 if it does not exist.
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func copyNode(n *yaml.Node) *yaml.Node {
+	cpy := *n
+	if n.Content != nil {
+		cpy.Content = make([]*yaml.Node, len(n.Content))
+		for i, c := range n.Content {
+			cpy.Content[i] = copyNode(c)
+		}
+	}
+	return &cpy
 }
 
 func getEnvVars() map[string]string {
@@ -1,7 +1,11 @@
 package glass_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	glass "github.com/glasslabs/looking-glass"
 	"github.com/glasslabs/looking-glass/module"
@@ -62,6 +66,30 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: "",
 		},
+		{
+			name: "allows multiple instances of a module",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name:     "clock-london",
+						Path:     "github.com/glasslabs/clock",
+						Version:  "v1.0.0",
+						Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+					},
+					{
+						Name:     "clock-tokyo",
+						Path:     "github.com/glasslabs/clock",
+						Version:  "v1.0.0",
+						Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+					},
+				},
+			},
+			wantErr: "",
+		},
 		{
 			name: "handles zero width",
 			config: glass.Config{
@@ -78,6 +106,61 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: "config: ui width and height muse be greater than zero",
 		},
+		{
+			name: "handles undefined module area",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Layout: glass.LayoutConfig{
+					Landscape: glass.GridTemplate{Areas: []string{"clock"}},
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+						Area: "weather",
+					},
+				},
+			},
+			wantErr: `test-module: area "weather" is not defined in the layout`,
+		},
+		{
+			name: "handles negative module refresh",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name:    "test-module",
+						Path:    "test",
+						Refresh: -time.Second,
+					},
+				},
+			},
+			wantErr: "test-module: refresh interval cannot be negative",
+		},
+		{
+			name: "handles grid placement outside the columns",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:       1,
+					Height:      1,
+					GridColumns: 4,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+						Grid: &module.GridPlacement{Column: 3, Span: 3},
+					},
+				},
+			},
+			wantErr: "test-module: grid placement does not fit in 4 columns",
+		},
 		{
 			name: "handles zero height",
 			config: glass.Config{
@@ -94,6 +177,78 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: "config: ui width and height muse be greater than zero",
 		},
+		{
+			name: "handles negative cache size",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+					Cache: glass.CacheConfig{
+						DiskSize: -1,
+					},
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: ui cache sizes cannot be negative",
+		},
+		{
+			name: "handles reserved chrome flag",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:      1,
+					Height:     1,
+					ChromeArgs: []string{"--kiosk", "--user-data-dir=/tmp/chrome"},
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: `config: chrome flag "--user-data-dir" is managed by looking glass`,
+		},
+		{
+			name: "handles negative zoom factor",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:      1,
+					Height:     1,
+					ZoomFactor: -1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: ui zoom factor cannot be negative",
+		},
+		{
+			name: "handles negative loader concurrency",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Loader: glass.LoaderConfig{
+					Concurrency: -1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: loader concurrency cannot be negative",
+		},
 		{
 			name: "handles no modules",
 			config: glass.Config{
@@ -104,6 +259,104 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: "config: at least one module is required",
 		},
+		{
+			name: "handles network wait without probe url",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Network: glass.NetworkConfig{
+					WaitForNetwork: true,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: network probe url is required to wait for the network",
+		},
+		{
+			name: "handles mqtt topics without broker",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				MQTT: glass.MQTTConfig{
+					Topics: []string{"presence"},
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: mqtt broker is required to publish topics",
+		},
+		{
+			name: "handles invalid mqtt broker scheme",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				MQTT: glass.MQTTConfig{
+					Broker: "http://localhost:1883",
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: `config: mqtt broker "http://localhost:1883" must use tcp, ssl, tls, ws or wss`,
+		},
+		{
+			name: "handles blank power off with prevent sleep",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:        1,
+					Height:       1,
+					PreventSleep: true,
+				},
+				Blank: glass.BlankConfig{
+					Schedule: []glass.BlankPeriod{{From: "23:00", To: "06:30"}},
+					PowerOff: true,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: blank power off cannot be used with ui prevent sleep",
+		},
+		{
+			name: "handles network monitor without probe url",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Network: glass.NetworkConfig{
+					Monitor:         true,
+					MonitorInterval: time.Second,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: network probe url is required to monitor the network",
+		},
 		{
 			name: "handles invalid module",
 			config: glass.Config{
@@ -140,6 +393,49 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: "config: module name \"test-module\" is a duplicate. module names must be unique",
 		},
+		{
+			name: "allows duplicate module name with ids",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "clock",
+						ID:   "clock-london",
+						Path: "test",
+					},
+					{
+						Name: "clock",
+						ID:   "clock-tokyo",
+						Path: "test",
+					},
+				},
+			},
+		},
+		{
+			name: "handles duplicate module id",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "clock",
+						ID:   "clock-london",
+						Path: "test",
+					},
+					{
+						Name: "clock",
+						ID:   "clock-london",
+						Path: "test",
+					},
+				},
+			},
+			wantErr: "config: module id \"clock-london\" is a duplicate. module ids must be unique",
+		},
 		{
 			name: "handles mismatched module versions",
 			config: glass.Config{
@@ -162,6 +458,85 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: "config: module \"test\" has mismatched versions (2 != 1)",
 		},
+		{
+			name: "handles invalid when expression",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name: "test-module",
+						Path: "test",
+						When: "features.foo &&",
+					},
+				},
+			},
+			wantErr: "test-module: invalid when expression: unexpected end of expression",
+		},
+		{
+			name: "handles unknown dependency",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{
+						Name:      "commute",
+						Path:      "test",
+						DependsOn: []string{"weather"},
+					},
+				},
+			},
+			wantErr: `commute: dependency "weather" is not a configured module`,
+		},
+		{
+			name: "handles dependency cycle",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{Name: "clock", Path: "test"},
+					{Name: "commute", Path: "test", DependsOn: []string{"clock", "weather"}},
+					{Name: "weather", Path: "test", DependsOn: []string{"news"}},
+					{Name: "news", Path: "test", DependsOn: []string{"commute"}},
+				},
+			},
+			wantErr: "config: module dependency cycle: commute -> weather -> news -> commute",
+		},
+		{
+			name: "handles ambiguous dependency",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{Name: "clock", ID: "clock-london", Path: "test"},
+					{Name: "clock", ID: "clock-tokyo", Path: "test"},
+					{Name: "commute", Path: "test", DependsOn: []string{"clock"}},
+				},
+			},
+			wantErr: `commute: dependency "clock" is ambiguous, it could be any of clock-london, clock-tokyo`,
+		},
+		{
+			name: "handles dependency cycle through module names",
+			config: glass.Config{
+				UI: glass.UIConfig{
+					Width:  1,
+					Height: 1,
+				},
+				Modules: []module.Descriptor{
+					{Name: "clock", ID: "clock-london", Path: "test", DependsOn: []string{"commute"}},
+					{Name: "commute", Path: "test", DependsOn: []string{"clock"}},
+				},
+			},
+			wantErr: "config: module dependency cycle: clock-london -> commute -> clock-london",
+		},
 	}
 
 	for _, test := range tests {
@@ -179,6 +554,29 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+var defaultNetwork = glass.NetworkConfig{
+	ProbeURL: "https://proxy.golang.org",
+	Timeout:  5 * time.Second,
+	Interval: 2 * time.Second,
+	MaxWait:  2 * time.Minute,
+
+	MonitorInterval: 30 * time.Second,
+}
+
+var defaultActivity = glass.ActivityConfig{
+	IdleAfter: glass.DefaultIdleAfter,
+}
+
+var defaultHTTP = glass.HTTPConfig{
+	Timeout: glass.DefaultHTTPTimeout,
+	Retries: 2,
+}
+
+var defaultRestart = glass.RestartConfig{
+	MinInterval: 30 * time.Second,
+	MaxPerHour:  10,
+}
+
 func TestParseConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -203,13 +601,18 @@ modules:
 `),
 			want: glass.Config{
 				UI: glass.UIConfig{
-					Width:      1024,
-					Height:     768,
-					Fullscreen: false,
+					Width:       1024,
+					Height:      768,
+					Fullscreen:  false,
+					LoadTimeout: 10 * time.Second,
 					CustomCSS: []string{
 						"/some/path/assets/css/main.css",
 					},
 				},
+				Activity: defaultActivity,
+				Network:  defaultNetwork,
+				HTTP:     defaultHTTP,
+				Restart:  defaultRestart,
 				Modules: []module.Descriptor{
 					{
 						Name:     "test-mod",
@@ -220,6 +623,50 @@ modules:
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "valid config with layout",
+			in: []byte(`
+ui:
+  width: 1024
+  height: 768
+layout:
+  landscape:
+    areas:
+      - "clock weather"
+      - "news news"
+    columns: "1fr 2fr"
+modules:
+  - name: clock
+    path: some/path
+    area: clock
+  - name: news
+    path: some/path
+    area: news
+`),
+			want: glass.Config{
+				UI: glass.UIConfig{
+					Width:       1024,
+					Height:      768,
+					Fullscreen:  true,
+					LoadTimeout: 10 * time.Second,
+				},
+				Layout: glass.LayoutConfig{
+					Landscape: glass.GridTemplate{
+						Areas:   []string{"clock weather", "news news"},
+						Columns: "1fr 2fr",
+					},
+				},
+				Activity: defaultActivity,
+				Network:  defaultNetwork,
+				HTTP:     defaultHTTP,
+				Restart:  defaultRestart,
+				Modules: []module.Descriptor{
+					{Name: "clock", Path: "some/path", Area: "clock"},
+					{Name: "news", Path: "some/path", Area: "news"},
+				},
+			},
+			wantErr: require.NoError,
+		},
 		{
 			name: "valid config with secrets",
 			in: []byte(`
@@ -235,10 +682,15 @@ modules:
 			secrets: map[string]interface{}{"test": "some/path"},
 			want: glass.Config{
 				UI: glass.UIConfig{
-					Width:      1024,
-					Height:     768,
-					Fullscreen: false,
+					Width:       1024,
+					Height:      768,
+					Fullscreen:  false,
+					LoadTimeout: 10 * time.Second,
 				},
+				Activity: defaultActivity,
+				Network:  defaultNetwork,
+				HTTP:     defaultHTTP,
+				Restart:  defaultRestart,
 				Modules: []module.Descriptor{
 					{
 						Name:     "test-mod",
@@ -254,10 +706,15 @@ modules:
 			in:   []byte("test: something: 1"),
 			want: glass.Config{
 				UI: glass.UIConfig{
-					Width:      640,
-					Height:     480,
-					Fullscreen: true,
+					Width:       640,
+					Height:      480,
+					Fullscreen:  true,
+					LoadTimeout: 10 * time.Second,
 				},
+				Activity: defaultActivity,
+				Network:  defaultNetwork,
+				HTTP:     defaultHTTP,
+				Restart:  defaultRestart,
 			},
 			wantErr: require.Error,
 		},
@@ -275,10 +732,15 @@ modules:
 `),
 			want: glass.Config{
 				UI: glass.UIConfig{
-					Width:      640,
-					Height:     480,
-					Fullscreen: true,
+					Width:       640,
+					Height:      480,
+					Fullscreen:  true,
+					LoadTimeout: 10 * time.Second,
 				},
+				Activity: defaultActivity,
+				Network:  defaultNetwork,
+				HTTP:     defaultHTTP,
+				Restart:  defaultRestart,
 			},
 			wantErr: require.Error,
 		},
@@ -293,3 +755,539 @@ modules:
 		})
 	}
 }
+
+func TestParseConfig_MergesModuleDefaults(t *testing.T) {
+	in := []byte(`
+defaults:
+  github.com/glasslabs/weather:
+    units: metric
+    appId: abc
+modules:
+  - name: weather-home
+    path: github.com/glasslabs/weather
+    position: top:left
+    config:
+      locationId: 1
+  - name: weather-work
+    path: github.com/glasslabs/weather
+    position: top:right
+    config:
+      locationId: 2
+      units: imperial
+`)
+
+	got, err := glass.ParseConfig(in, "/some/path", nil)
+
+	require.NoError(t, err)
+	require.Len(t, got.Modules, 2)
+	var home map[string]interface{}
+	err = got.Modules[0].Config.Decode(&home)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"units": "metric", "appId": "abc", "locationId": 1}, home)
+	var work map[string]interface{}
+	err = got.Modules[1].Config.Decode(&work)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"units": "imperial", "appId": "abc", "locationId": 2}, work)
+}
+
+func TestParseConfig_HandlesInvalidModuleDefaults(t *testing.T) {
+	in := []byte(`
+defaults:
+  github.com/glasslabs/weather: metric
+modules:
+  - name: weather-home
+    path: github.com/glasslabs/weather
+    position: top:left
+`)
+
+	_, err := glass.ParseConfig(in, "/some/path", nil)
+
+	assert.EqualError(t, err, "config: defaults for module \"github.com/glasslabs/weather\" must be a mapping")
+}
+
+func TestParseConfig_ComputesValues(t *testing.T) {
+	in := []byte(`
+ui:
+  width: 1920
+  height: "=width * 9 / 16"
+modules:
+  - name: clock
+    path: clock
+    position: top:left
+    config:
+      size: "=ui.height / 4"
+      label: "==not computed"
+`)
+
+	got, err := glass.ParseConfig(in, "/some/path", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1080, got.UI.Height)
+	var cfg map[string]interface{}
+	err = got.Modules[0].Config.Decode(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"size": 270, "label": "=not computed"}, cfg)
+}
+
+func TestParseConfig_HandlesCyclicComputedValues(t *testing.T) {
+	in := []byte(`
+ui:
+  width: "=height * 16 / 9"
+  height: "=width * 9 / 16"
+modules:
+  - name: clock
+    path: clock
+    position: top:left
+`)
+
+	_, err := glass.ParseConfig(in, "/some/path", nil)
+
+	assert.EqualError(t, err, "config: cyclic reference: ui.width -> ui.height -> ui.width")
+}
+
+func TestParseConfig_ExpandsEnvVars(t *testing.T) {
+	t.Setenv("GLASS_WIDTH", "1920")
+	t.Setenv("GLASS_API_KEY", "secret")
+	t.Setenv("GLASS_EMPTY", "")
+	t.Setenv("GLASS_EXPR", "=1 + 1")
+	in := []byte(`
+ui:
+  width: ${GLASS_WIDTH}
+  height: "=width * 9 / 16"
+modules:
+  - name: weather
+    path: weather
+    position: top:left
+    config:
+      appId: ${GLASS_API_KEY}
+      units: ${GLASS_UNITS:-metric}
+      lang: ${GLASS_EMPTY:-en}
+      quoted: "${GLASS_WIDTH}"
+      price: $$5 for ${GLASS_API_KEY}
+      expr: ${GLASS_EXPR}
+      nested:
+        - key: ${GLASS_API_KEY}
+`)
+
+	got, err := glass.ParseConfig(in, "/some/path", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1920, got.UI.Width)
+	assert.Equal(t, 1080, got.UI.Height)
+	var cfg map[string]interface{}
+	err = got.Modules[0].Config.Decode(&cfg)
+	require.NoError(t, err)
+	want := map[string]interface{}{
+		"appId":  "secret",
+		"units":  "metric",
+		"lang":   "en",
+		"quoted": "1920",
+		"price":  "$5 for secret",
+		"expr":   "=1 + 1",
+		"nested": []interface{}{map[string]interface{}{"key": "secret"}},
+	}
+	assert.Equal(t, want, cfg)
+}
+
+func TestParseConfig_HandlesMissingEnvVars(t *testing.T) {
+	in := []byte(`
+modules:
+  - name: weather
+    path: weather
+    position: top:left
+    config:
+      appId: ${GLASS_MISSING_API_KEY}
+`)
+
+	_, err := glass.ParseConfig(in, "/some/path", nil)
+
+	assert.EqualError(t, err, `config: "modules.0.config.appId": environment variable "GLASS_MISSING_API_KEY" is not set`)
+}
+
+func TestParseConfig_HandlesInvalidEnvVars(t *testing.T) {
+	in := []byte(`
+ui:
+  lang: ${GLASS_LANG
+`)
+
+	_, err := glass.ParseConfig(in, "/some/path", nil)
+
+	assert.EqualError(t, err, `config: "ui.lang": unterminated variable reference "${GLASS_LANG"`)
+}
+
+func TestLoadConfig_File(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+ui:
+  width: 1024
+  height: 768
+modules:
+  - name: test-mod
+    path: some/path
+    position: top:right
+`)
+
+	got, err := glass.LoadConfig(filepath.Join(dir, "config.yaml"), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1024, got.UI.Width)
+	require.Len(t, got.Modules, 1)
+	assert.Equal(t, "test-mod", got.Modules[0].Name)
+}
+
+func TestLoadConfig_TOMLFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.toml"), `
+[ui]
+width = 1024
+height = 768
+
+[restart]
+minInterval = "1m"
+
+[[modules]]
+name = "weather"
+path = "github.com/glasslabs/weather"
+position = "top:left"
+
+[modules.config]
+units = "metric"
+days = 3
+`)
+
+	got, err := glass.LoadConfig(filepath.Join(dir, "config.toml"), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1024, got.UI.Width)
+	assert.Equal(t, time.Minute, got.Restart.MinInterval)
+	require.Len(t, got.Modules, 1)
+	assert.Equal(t, "weather", got.Modules[0].Name)
+	assert.Equal(t, module.Position{Vertical: module.Top, Horizontal: module.Left}, got.Modules[0].Position)
+	var weather struct {
+		Units string `yaml:"units"`
+		Days  int    `yaml:"days"`
+	}
+	err = got.Modules[0].Config.Decode(&weather)
+	require.NoError(t, err)
+	assert.Equal(t, "metric", weather.Units)
+	assert.Equal(t, 3, weather.Days)
+}
+
+func TestLoadConfig_JSONFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.json"), `{
+  "ui": {"width": 1024, "height": 768},
+  "modules": [
+    {"name": "weather", "path": "github.com/glasslabs/weather", "position": "top:left", "config": {"units": "metric"}}
+  ]
+}`)
+
+	got, err := glass.LoadConfig(filepath.Join(dir, "config.json"), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1024, got.UI.Width)
+	require.Len(t, got.Modules, 1)
+	var weather map[string]interface{}
+	err = got.Modules[0].Config.Decode(&weather)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"units": "metric"}, weather)
+}
+
+func TestLoadConfig_HandlesDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "yaml",
+			file:    "config.yaml",
+			content: "ui:\n  width: [1024\n",
+			wantErr: "yaml decoder: yaml: line 1",
+		},
+		{
+			name:    "toml",
+			file:    "config.toml",
+			content: "[ui]\nwidth = 1024\nheight = = 768\n",
+			wantErr: "toml decoder: toml: line 3",
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: "{\n  \"ui\": {\"width\": 1024,}\n}",
+			wantErr: "json decoder: line 2 (offset 26)",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, test.file), test.content)
+
+			_, err := glass.LoadConfig(filepath.Join(dir, test.file), nil)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.wantErr)
+		})
+	}
+}
+
+func TestLoadConfig_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "00-base.yaml"), `
+ui:
+  width: 1024
+  height: 768
+modules:
+  - name: clock
+    path: github.com/glasslabs/clock
+    position: top:right
+`)
+	writeFile(t, filepath.Join(dir, "10-weather.yml"), `
+defaults:
+  github.com/glasslabs/weather:
+    units: metric
+modules:
+  - name: weather
+    path: github.com/glasslabs/weather
+    position: top:left
+`)
+	writeFile(t, filepath.Join(dir, "20-news.toml"), `
+[[modules]]
+name = "news"
+path = "github.com/glasslabs/news"
+position = "bottom:left"
+`)
+	writeFile(t, filepath.Join(dir, "README.md"), `not: config`)
+
+	got, err := glass.LoadConfig(dir, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1024, got.UI.Width)
+	assert.Equal(t, 768, got.UI.Height)
+	assert.True(t, got.UI.Fullscreen)
+	require.Len(t, got.Modules, 3)
+	assert.Equal(t, "clock", got.Modules[0].Name)
+	assert.Equal(t, "weather", got.Modules[1].Name)
+	assert.Equal(t, "news", got.Modules[2].Name)
+	var weather map[string]interface{}
+	err = got.Modules[1].Config.Decode(&weather)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"units": "metric"}, weather)
+}
+
+func TestLoadConfig_DirectoryHandlesDuplicateModules(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), `
+modules:
+  - name: clock
+    path: github.com/glasslabs/clock
+    position: top:right
+`)
+	writeFile(t, filepath.Join(dir, "b.yaml"), `
+modules:
+  - name: clock
+    path: github.com/glasslabs/clock
+    position: top:left
+`)
+
+	_, err := glass.LoadConfig(dir, nil)
+
+	assert.EqualError(t, err, `config: module name "clock" in b.yaml is already defined in a.yaml`)
+}
+
+func TestLoadConfig_DirectoryHandlesDuplicateKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), `
+ui:
+  width: 1024
+`)
+	writeFile(t, filepath.Join(dir, "b.yaml"), `
+ui:
+  height: 768
+`)
+
+	_, err := glass.LoadConfig(dir, nil)
+
+	assert.EqualError(t, err, `config: "ui" in b.yaml is already defined in a.yaml`)
+}
+
+func TestLoadConfig_Include(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+include:
+  - ui.yaml
+  - modules/*.yaml
+ui:
+  width: 1024
+  height: 768
+modules:
+  - name: clock
+    path: github.com/glasslabs/clock
+    position: top:right
+`)
+	writeFile(t, filepath.Join(dir, "ui.yaml"), `
+ui:
+  height: 600
+`)
+	writeFile(t, filepath.Join(dir, "modules", "10-weather.yaml"), `
+include: ../defaults.toml
+modules:
+  - name: weather
+    path: github.com/glasslabs/weather
+    position: top:left
+`)
+	writeFile(t, filepath.Join(dir, "modules", "20-news.yaml"), `
+modules:
+  - name: news
+    path: github.com/glasslabs/news
+    position: bottom:left
+`)
+	writeFile(t, filepath.Join(dir, "defaults.toml"), `
+[defaults."github.com/glasslabs/weather"]
+units = "metric"
+`)
+
+	got, err := glass.LoadConfig(filepath.Join(dir, "config.yaml"), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1024, got.UI.Width)
+	assert.Equal(t, 600, got.UI.Height)
+	require.Len(t, got.Modules, 3)
+	assert.Equal(t, "clock", got.Modules[0].Name)
+	assert.Equal(t, "weather", got.Modules[1].Name)
+	assert.Equal(t, "news", got.Modules[2].Name)
+	var weather map[string]interface{}
+	err = got.Modules[1].Config.Decode(&weather)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"units": "metric"}, weather)
+}
+
+func TestLoadConfig_IncludeHandlesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.yaml": "include: a.yaml\n",
+				"a.yaml":      "include: b.yaml\n",
+				"b.yaml":      "include: a.yaml\n",
+			},
+			wantErr: "config: include cycle: {dir}/a.yaml -> {dir}/b.yaml -> {dir}/a.yaml",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"config.yaml": "include: missing.yaml\n",
+			},
+			wantErr: "config: could not read include: open {dir}/missing.yaml: no such file or directory",
+		},
+		{
+			name: "invalid include",
+			files: map[string]string{
+				"config.yaml": "include:\n  path: a.yaml\n",
+			},
+			wantErr: "config: include must be a path or a list of paths",
+		},
+		{
+			name: "modules not a list",
+			files: map[string]string{
+				"config.yaml": "include: a.yaml\nmodules: []\n",
+				"a.yaml":      "modules:\n  name: clock\n",
+			},
+			wantErr: "{dir}/a.yaml: modules must be a list",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+
+			_, err := glass.LoadConfig(filepath.Join(dir, "config.yaml"), nil)
+
+			wantErr := strings.ReplaceAll(test.wantErr, "{dir}", dir)
+			assert.EqualError(t, err, "could not parse configuration file: "+wantErr)
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	modPath := t.TempDir()
+	err := os.MkdirAll(filepath.Join(modPath, "src", "clock"), 0o750)
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "custom.css"), "body {}")
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+ui:
+  width: 1024
+  height: 768
+  customCss:
+    - `+filepath.Join(dir, "custom.css")+`
+modules:
+  - name: clock
+    path: clock
+    position: top:right
+  - name: weather
+    path: github.com/glasslabs/weather
+    version: latest
+    position: top:left
+`)
+
+	err = glass.ValidateConfig(filepath.Join(dir, "config.yaml"), nil, modPath)
+
+	assert.NoError(t, err)
+}
+
+func TestValidateConfig_ReportsAllProblems(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+ui:
+  width: 1024
+  height: 768
+  customCss:
+    - `+filepath.Join(dir, "missing.css")+`
+loader:
+  strictPositions: true
+modules:
+  - name: clock
+    path: clock
+    position: top:right
+  - name: date
+    path: date
+    position: top:right
+  - name: weather
+    path: weather
+    position: top:left
+    refresh: -1s
+`)
+
+	err := glass.ValidateConfig(filepath.Join(dir, "config.yaml"), nil, t.TempDir())
+
+	var errs glass.ConfigErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 6)
+	assert.EqualError(t, errs[0], "weather: refresh interval cannot be negative")
+	assert.Contains(t, errs[1].Error(), "could not read custom css")
+	assert.EqualError(t, errs[2], "modules share the same position: clock and date at top:right")
+	assert.EqualError(t, errs[3], `clock: module "clock" not found in the modules path`)
+	assert.EqualError(t, errs[4], `date: module "date" not found in the modules path`)
+	assert.EqualError(t, errs[5], `weather: module "weather" not found in the modules path`)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	require.NoError(t, err)
+	err = os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,82 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/glasslabs/looking-glass/module"
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// enableModuleDragJS makes the modules draggable in the page.
+const enableModuleDragJS = "enableModuleDrag();"
+
+// enableModuleDrag lets modules be dragged to a new position in the window
+// while designing a layout. The position a module is dropped at is logged,
+// so it can be copied into the configuration. Only used in dev mode.
+func (r *Runtime) enableModuleDrag() error {
+	if err := r.ui.Bind("moduleDropped", r.moduleDropped); err != nil {
+		return fmt.Errorf("could not bind module drop: %w", err)
+	}
+	if _, err := r.ui.Eval(enableModuleDragJS); err != nil {
+		return fmt.Errorf("could not enable module drag: %w", err)
+	}
+	r.ui.replaceSetup(func(js string) bool {
+		return js == enableModuleDragJS
+	}, []string{enableModuleDragJS})
+	return nil
+}
+
+// disableModuleDrag stops modules being dragged once dev mode is turned off.
+func (r *Runtime) disableModuleDrag() {
+	r.ui.replaceSetup(func(js string) bool {
+		return js == enableModuleDragJS
+	}, nil)
+	if _, err := r.ui.Eval("disableModuleDrag();"); err != nil && !errors.Is(err, ErrClosed) {
+		r.log.Error("could not disable module drag", logCtx.Error("error", err))
+	}
+	if err := r.ui.Unbind("moduleDropped"); err != nil && !errors.Is(err, ErrClosed) {
+		r.log.Error("could not unbind module drop", logCtx.Error("error", err))
+	}
+}
+
+// restartDev applies dev mode being turned on or off on reload, toggling
+// module dragging and watching the files of the running modules.
+func (r *Runtime) restartDev(ctx context.Context, enabled bool, states []*moduleState) {
+	if !enabled {
+		r.stopWatching()
+		r.disableModuleDrag()
+		return
+	}
+
+	if err := r.enableModuleDrag(); err != nil {
+		r.log.Error("could not enable module dragging", logCtx.Error("error", err))
+	}
+	for _, state := range states {
+		r.mu.Lock()
+		running := state.running
+		r.mu.Unlock()
+		if running {
+			r.watchModule(ctx, state)
+		}
+	}
+}
+
+// moduleDropped is called from javascript when a module is dropped. The
+// column is the grid placement column, or zero if the module is not placed
+// in columns.
+func (r *Runtime) moduleDropped(name, vert, horiz string, column int) error {
+	pos, err := module.ParsePosition(vert + ":" + horiz)
+	if err != nil {
+		return err
+	}
+
+	log := r.log.With(logCtx.Str("module", name), logCtx.Str("position", pos.String()))
+	if column > 0 {
+		log.Info("module moved", logCtx.Int("column", column))
+		return nil
+	}
+	log.Info("module moved")
+	return nil
+}
@@ -0,0 +1,58 @@
+package glass
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_EnableModuleDrag(t *testing.T) {
+	win := NewRecordingWindow()
+	var buf bytes.Buffer
+	rt := NewRuntime(Config{Dev: true}, &UI{win: win}, &MockModuleRunner{}, logger.New(&buf, logger.LogfmtFormat(), logger.Info))
+
+	err := rt.enableModuleDrag()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"enableModuleDrag();"}, win.Evals())
+	assert.Equal(t, []string{"enableModuleDrag();"}, rt.ui.setup)
+
+	fn, ok := win.Binding("moduleDropped")
+	require.True(t, ok)
+	dropped := fn.(func(string, string, string, int) error)
+
+	err = dropped("clock", "top", "left", 0)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `msg="module moved" module=clock position=top:left`)
+
+	err = dropped("weather", "bottom", "right", 2)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `msg="module moved" module=weather position=bottom:right column=2`)
+
+	err = dropped("clock", "middle", "full", 0)
+	assert.Error(t, err)
+}
+
+func TestRuntime_ReloadTogglesModuleDrag(t *testing.T) {
+	win := NewRecordingWindow()
+	rt := NewRuntime(Config{}, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+
+	err := rt.Reload(context.Background(), Config{Dev: true})
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), "enableModuleDrag();")
+	assert.Equal(t, []string{"enableModuleDrag();"}, rt.ui.setup)
+	_, ok := win.Binding("moduleDropped")
+	assert.True(t, ok)
+
+	err = rt.Reload(context.Background(), Config{})
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), "disableModuleDrag();")
+	assert.Contains(t, win.Evals(), `unbindFunction("moduleDropped");`)
+	assert.Empty(t, rt.ui.setup)
+}
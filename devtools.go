@@ -0,0 +1,111 @@
+package glass
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Clip is a region of the page in css pixels.
+type Clip struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// devtoolsTimeout is the maximum time a devtools request may take.
+const devtoolsTimeout = 10 * time.Second
+
+// captureScreenshot captures a png of the clip region of the page, using
+// a separate devtools connection to the chrome instance with the given profile.
+func captureScreenshot(profile string, clip Clip) ([]byte, error) {
+	b, err := os.ReadFile(filepath.Join(profile, "DevToolsActivePort"))
+	if err != nil {
+		return nil, fmt.Errorf("could not find devtools port: %w", err)
+	}
+	port := strings.TrimSpace(strings.SplitN(string(b), "\n", 2)[0])
+
+	wsURL, err := devtoolsPageURL("http://127.0.0.1:" + port + "/json")
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := websocket.Dial(wsURL, "", "http://127.0.0.1")
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to devtools: %w", err)
+	}
+	defer func() { _ = ws.Close() }()
+	_ = ws.SetDeadline(time.Now().Add(devtoolsTimeout))
+
+	req := map[string]interface{}{
+		"id":     1,
+		"method": "Page.captureScreenshot",
+		"params": map[string]interface{}{
+			"format": "png",
+			"clip": map[string]interface{}{
+				"x":      clip.X,
+				"y":      clip.Y,
+				"width":  clip.Width,
+				"height": clip.Height,
+				"scale":  1,
+			},
+		},
+	}
+	if err = websocket.JSON.Send(ws, req); err != nil {
+		return nil, fmt.Errorf("could not send devtools request: %w", err)
+	}
+
+	for {
+		var resp struct {
+			ID     int `json:"id"`
+			Result struct {
+				Data []byte `json:"data"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err = websocket.JSON.Receive(ws, &resp); err != nil {
+			return nil, fmt.Errorf("could not read devtools response: %w", err)
+		}
+		if resp.ID != 1 {
+			continue
+		}
+		if resp.Error != nil {
+			return nil, errors.New(resp.Error.Message)
+		}
+		return resp.Result.Data, nil
+	}
+}
+
+// devtoolsPageURL returns the websocket url of the first page target.
+func devtoolsPageURL(listURL string) (string, error) {
+	c := &http.Client{Timeout: devtoolsTimeout}
+	resp, err := c.Get(listURL)
+	if err != nil {
+		return "", fmt.Errorf("could not list devtools targets: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var targets []struct {
+		Type  string `json:"type"`
+		WSURL string `json:"webSocketDebuggerUrl"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		return "", fmt.Errorf("could not decode devtools targets: %w", err)
+	}
+	for _, t := range targets {
+		if t.Type == "page" && t.WSURL != "" {
+			return t.WSURL, nil
+		}
+	}
+	return "", errors.New("no devtools page target found")
+}
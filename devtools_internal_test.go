@@ -0,0 +1,152 @@
+package glass
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIContext_Screenshot(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(NewValue("", nil))
+	win.On("Eval", "moduleBounds(`test`);").Return(NewValue(`{"x":600,"y":60,"width":300.5,"height":120}`, nil))
+
+	var got Clip
+	ui := &UI{
+		win: win,
+		capture: func(clip Clip) ([]byte, error) {
+			got = clip
+			return []byte("png"), nil
+		},
+	}
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	img, err := uiCtx.Screenshot()
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png"), img)
+	assert.Equal(t, Clip{X: 600, Y: 60, Width: 300.5, Height: 120}, got)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_ScreenshotHandlesMissingModule(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(NewValue("", nil))
+	win.On("Eval", "moduleBounds(`test`);").Return(NewValue(`null`, nil))
+
+	ui := &UI{
+		win: win,
+		capture: func(clip Clip) ([]byte, error) {
+			t.Fatal("unexpected capture")
+			return nil, nil
+		},
+	}
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	_, err = uiCtx.Screenshot()
+
+	assert.EqualError(t, err, "test: module is not visible")
+}
+
+func TestUI_Screenshot(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Done").Return(make(chan struct{}))
+	win.On("Eval", "pageBounds();").Return(NewValue(`{"x":0,"y":0,"width":1920,"height":1080}`, nil))
+
+	var got Clip
+	ui := &UI{
+		win: win,
+		capture: func(clip Clip) ([]byte, error) {
+			got = clip
+			return []byte("png"), nil
+		},
+	}
+
+	img, err := ui.Screenshot()
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png"), img)
+	assert.Equal(t, Clip{Width: 1920, Height: 1080}, got)
+}
+
+func TestUI_ScreenshotHandlesRendererNotReady(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Done").Return(make(chan struct{}))
+	win.On("Eval", "pageBounds();").Return(NewValue(`null`, nil))
+
+	ui := &UI{
+		win: win,
+		capture: func(clip Clip) ([]byte, error) {
+			t.Fatal("unexpected capture")
+			return nil, nil
+		},
+	}
+
+	_, err := ui.Screenshot()
+
+	assert.ErrorIs(t, err, ErrRendererNotReady)
+}
+
+func TestUI_ScreenshotHandlesClosedUI(t *testing.T) {
+	ui := &UI{closed: true}
+
+	_, err := ui.Screenshot()
+
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestNewServer_Screenshot(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Done").Return(make(chan struct{}))
+	win.On("Eval", "pageBounds();").Return(NewValue(`{"x":0,"y":0,"width":800,"height":600}`, nil))
+	ui := &UI{
+		win: win,
+		capture: func(clip Clip) ([]byte, error) {
+			return []byte("png"), nil
+		},
+	}
+	rt := NewRuntime(Config{}, ui, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/screenshot")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png"), b)
+}
+
+func TestNewServer_ScreenshotHandlesClosedUI(t *testing.T) {
+	rt := NewRuntime(Config{}, &UI{closed: true}, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/screenshot")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestDevtoolsPageURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"type":"service_worker","webSocketDebuggerUrl":"ws://worker"},{"type":"page","webSocketDebuggerUrl":"ws://page"}]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	got, err := devtoolsPageURL(srv.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ws://page", got)
+}
@@ -0,0 +1,117 @@
+package glass
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hamba/logger/v2"
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// errDisplaysUnsupported is returned when the platform has no way to list displays.
+var errDisplaysUnsupported = errors.New("listing displays is not supported on this platform")
+
+// display is a connected display.
+type display struct {
+	Name    string
+	X, Y    int
+	Width   int
+	Height  int
+	Primary bool
+}
+
+// selectDisplay returns the display with the 1-based index n.
+//
+// If the display does not exist, the primary display is returned
+// and ok is false.
+func selectDisplay(displays []display, n int) (d display, ok bool) {
+	if n > 0 && n <= len(displays) {
+		return displays[n-1], true
+	}
+	for _, d = range displays {
+		if d.Primary {
+			return d, false
+		}
+	}
+	if len(displays) > 0 {
+		return displays[0], false
+	}
+	return display{}, false
+}
+
+// resolveDisplay positions the window on the configured display.
+//
+// If the display cannot be found, the primary display is used. If the
+// displays cannot be listed, the configured position is kept.
+func resolveDisplay(cfg UIConfig, list func() ([]display, error), log *logger.Logger) UIConfig {
+	if cfg.Display <= 0 {
+		return cfg
+	}
+
+	displays, err := list()
+	if err != nil {
+		log.Warn("could not list displays", logCtx.Error("error", err))
+		return cfg
+	}
+	if len(displays) == 0 {
+		log.Warn("no displays found")
+		return cfg
+	}
+	d, ok := selectDisplay(displays, cfg.Display)
+	if !ok {
+		log.Warn("display not found, using primary display",
+			logCtx.Int("display", cfg.Display),
+			logCtx.Str("displays", formatDisplays(displays)),
+		)
+	}
+	log.Debug("showing window on display", logCtx.Str("name", d.Name))
+
+	cfg.X, cfg.Y = d.X, d.Y
+	return cfg
+}
+
+// parseXrandrMonitors parses the output of "xrandr --listmonitors".
+//
+//	Monitors: 2
+//	 0: +*HDMI-1 1920/531x1080/299+0+0  HDMI-1
+//	 1: +DP-1 1280/338x1024/270+1920+0  DP-1
+func parseXrandrMonitors(out string) ([]display, error) {
+	var displays []display
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 3 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+
+		name := strings.TrimLeft(fields[1], "+")
+		primary := strings.HasPrefix(name, "*")
+		name = strings.TrimPrefix(name, "*")
+
+		var w, h, x, y, wmm, hmm int
+		if _, err := fmt.Sscanf(fields[2], "%d/%dx%d/%d+%d+%d", &w, &wmm, &h, &hmm, &x, &y); err != nil {
+			return nil, fmt.Errorf("could not parse monitor %q: %w", name, err)
+		}
+		displays = append(displays, display{
+			Name:    name,
+			X:       x,
+			Y:       y,
+			Width:   w,
+			Height:  h,
+			Primary: primary,
+		})
+	}
+	return displays, sc.Err()
+}
+
+// formatDisplays formats the displays for logging.
+func formatDisplays(displays []display) string {
+	names := make([]string, len(displays))
+	for i, d := range displays {
+		names[i] = strconv.Itoa(i+1) + ":" + d.Name
+	}
+	return strings.Join(names, ", ")
+}
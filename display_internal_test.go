@@ -0,0 +1,96 @@
+package glass
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXrandrMonitors(t *testing.T) {
+	out := `Monitors: 2
+ 0: +*HDMI-1 1920/531x1080/299+0+0  HDMI-1
+ 1: +DP-1 1280/338x1024/270+1920+0  DP-1
+`
+
+	got, err := parseXrandrMonitors(out)
+
+	require.NoError(t, err)
+	assert.Equal(t, []display{
+		{Name: "HDMI-1", X: 0, Y: 0, Width: 1920, Height: 1080, Primary: true},
+		{Name: "DP-1", X: 1920, Y: 0, Width: 1280, Height: 1024},
+	}, got)
+}
+
+func TestParseXrandrMonitorsHandlesInvalidGeometry(t *testing.T) {
+	_, err := parseXrandrMonitors(" 0: +*HDMI-1 1920x1080  HDMI-1\n")
+
+	assert.Error(t, err)
+}
+
+func TestSelectDisplay(t *testing.T) {
+	displays := []display{
+		{Name: "DP-1", X: 1920},
+		{Name: "HDMI-1", Primary: true},
+	}
+
+	tests := []struct {
+		name     string
+		displays []display
+		n        int
+		want     string
+		wantOK   bool
+	}{
+		{
+			name:     "selects display",
+			displays: displays,
+			n:        1,
+			want:     "DP-1",
+			wantOK:   true,
+		},
+		{
+			name:     "falls back to primary display",
+			displays: displays,
+			n:        3,
+			want:     "HDMI-1",
+		},
+		{
+			name:     "falls back to first display without primary",
+			displays: []display{{Name: "DP-1"}, {Name: "DP-2"}},
+			n:        3,
+			want:     "DP-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := selectDisplay(test.displays, test.n)
+
+			assert.Equal(t, test.want, got.Name)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+}
+
+func TestResolveDisplay(t *testing.T) {
+	list := func() ([]display, error) {
+		return []display{{Name: "HDMI-1", Primary: true}, {Name: "DP-1", X: 1920, Y: 120}}, nil
+	}
+
+	got := resolveDisplay(UIConfig{X: 10, Y: 10, Display: 2}, list, newTestLogger())
+
+	assert.Equal(t, 1920, got.X)
+	assert.Equal(t, 120, got.Y)
+}
+
+func TestResolveDisplayKeepsPositionWhenDisplaysCannotBeListed(t *testing.T) {
+	list := func() ([]display, error) {
+		return nil, errors.New("test error")
+	}
+
+	got := resolveDisplay(UIConfig{X: 10, Y: 20, Display: 2}, list, newTestLogger())
+
+	assert.Equal(t, 10, got.X)
+	assert.Equal(t, 20, got.Y)
+}
@@ -0,0 +1,15 @@
+package glass
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// listDisplays lists the connected displays using xrandr.
+func listDisplays() ([]display, error) {
+	out, err := exec.Command("xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil, fmt.Errorf("xrandr: %w", err)
+	}
+	return parseXrandrMonitors(string(out))
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package glass
+
+// listDisplays is used on platforms without a way to list displays.
+func listDisplays() ([]display, error) {
+	return nil, errDisplaysUnsupported
+}
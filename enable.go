@@ -0,0 +1,75 @@
+package glass
+
+import (
+	"context"
+	"fmt"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// EnableModule loads a disabled module at its configured position.
+//
+// Enabling a module that is already enabled does nothing.
+func (r *Runtime) EnableModule(ctx context.Context, name string) error {
+	state, err := r.moduleState(name)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if !state.disabled {
+		r.mu.Unlock()
+		return nil
+	}
+	state.disabled = false
+	enabled := true
+	state.desc.Enabled = &enabled
+	r.mu.Unlock()
+
+	r.log.Info("enabling module", logCtx.Str("module", name))
+	return r.load(ctx, []*moduleState{state}, true)
+}
+
+// DisableModule unloads the module, keeping its configuration
+// so it can be enabled again.
+//
+// Disabling a module that is already disabled does nothing.
+func (r *Runtime) DisableModule(name string) error {
+	state, err := r.moduleState(name)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if state.disabled {
+		r.mu.Unlock()
+		return nil
+	}
+	state.disabled = true
+	loaded := state.ui != nil
+	r.mu.Unlock()
+
+	if loaded {
+		r.unload(state)
+	}
+
+	r.mu.Lock()
+	state.ui = nil
+	state.err = nil
+	state.failed = false
+	state.skipped = false
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Runtime) moduleState(name string) (*moduleState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.states {
+		if state.desc.InstanceID() == name {
+			return state, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown module %q", name)
+}
@@ -0,0 +1,87 @@
+package glass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_LoadSkipsDisabledModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"right"}]);`).Once().Return(NewValue(`{"clock":""}`, nil))
+	ui := &UI{win: win}
+
+	disabled := false
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+		Enabled:  &disabled,
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", clock).Return(nil)
+	svc.On("Run", mock.Anything, clock, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, ModuleRunning, rt.ModuleStatus("clock"))
+	assert.Equal(t, ModuleDisabled, rt.ModuleStatus("weather"))
+	assert.False(t, rt.RuntimeState()[1].Enabled)
+	win.AssertExpectations(t)
+	svc.AssertExpectations(t)
+}
+
+func TestRuntime_DisableAndEnableModule(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left},
+	}
+	mod := &MockModule{}
+	mod.On("Close").Once().Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mod, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	err = rt.DisableModule("weather")
+	require.NoError(t, err)
+
+	assert.Equal(t, ModuleDisabled, rt.ModuleStatus("weather"))
+	assert.Contains(t, win.Evals(), `removeModule("weather");`)
+
+	err = rt.EnableModule(context.Background(), "weather")
+	require.NoError(t, err)
+
+	assert.Equal(t, ModuleRunning, rt.ModuleStatus("weather"))
+	evals := win.Evals()
+	assert.Equal(t, `createModules([{"name":"weather","vert":"bottom","horiz":"left"}]);`, evals[len(evals)-2])
+	assert.Equal(t, `fadeModule("weather", 1, 500);`, evals[len(evals)-1])
+	mod.AssertExpectations(t)
+}
+
+func TestRuntime_EnableModuleHandlesUnknownModule(t *testing.T) {
+	rt := NewRuntime(Config{}, &UI{}, &MockModuleRunner{}, newTestLogger())
+
+	err := rt.EnableModule(context.Background(), "weather")
+
+	assert.EqualError(t, err, `unknown module "weather"`)
+}
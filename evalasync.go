@@ -0,0 +1,91 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// asyncQueue runs functions in order in the background.
+//
+// The background goroutine only runs while there are queued functions.
+type asyncQueue struct {
+	mu      sync.Mutex
+	fns     []func()
+	running bool
+	idle    chan struct{}
+}
+
+// push queues fn to be run after all previously queued functions.
+func (q *asyncQueue) push(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.fns = append(q.fns, fn)
+	if q.running {
+		return
+	}
+	q.running = true
+	q.idle = make(chan struct{})
+	go q.run()
+}
+
+func (q *asyncQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.fns) == 0 {
+			q.running = false
+			close(q.idle)
+			q.mu.Unlock()
+			return
+		}
+		fn := q.fns[0]
+		q.fns = q.fns[1:]
+		q.mu.Unlock()
+
+		fn()
+	}
+}
+
+// wait waits for all queued functions to run, or ctx to be done.
+func (q *asyncQueue) wait(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return nil
+	}
+	idle := q.idle
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EvalAsync evaluates a javascript expression in the background, returning
+// immediately without waiting for the result.
+//
+// Async evaluations of the module run in order, and complete before any
+// subsequent Eval, EvalContext or EvalInto call of the module is evaluated.
+// Errors are logged rather than returned.
+func (u *UIContext) EvalAsync(js string, args ...interface{}) {
+	js = fmt.Sprintf(js, args...)
+	if err := u.sandboxError(); err != nil {
+		u.logger().Error("could not evaluate async javascript", logCtx.Str("js", jsPrefix(js)), logCtx.Error("error", err))
+		return
+	}
+	if u.queue(js) {
+		return
+	}
+	u.async.push(func() {
+		if _, err := u.ui.moduleEval(u.name, js); err != nil && !errors.Is(err, ErrClosed) {
+			u.logger().Error("could not evaluate async javascript", logCtx.Str("js", jsPrefix(js)), logCtx.Error("error", u.evalError(err)))
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package glass
+
+import (
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/zserge/lorca"
+)
+
+// ErrEmptyResult is returned when a javascript evaluation that
+// requires a result returns nothing, e.g. `undefined`.
+var ErrEmptyResult = types.ErrEmptyResult
+
+// ErrBatching is returned when a javascript evaluation that requires
+// a result is made while batching.
+var ErrBatching = types.ErrBatching
+
+// evalResult is the outcome of a javascript evaluation, which is
+// either an error, an empty result or a value.
+type evalResult struct {
+	v   lorca.Value
+	err error
+}
+
+// newEvalResult returns the result of the evaluated value v.
+func newEvalResult(v lorca.Value) evalResult {
+	return evalResult{v: v, err: v.Err()}
+}
+
+// evalFailed returns the result of an evaluation that failed with err.
+func evalFailed(err error) evalResult {
+	return evalResult{err: err}
+}
+
+// empty determines if the evaluation succeeded without a value.
+func (r evalResult) empty() bool {
+	return r.err == nil && (r.v == nil || len(r.v.Bytes()) == 0)
+}
+
+// bytes returns the raw json value, which is nil if the
+// evaluation failed or was empty.
+func (r evalResult) bytes() []byte {
+	if r.err != nil || r.v == nil {
+		return nil
+	}
+	return r.v.Bytes()
+}
+
+// decode decodes the value into out.
+//
+// If the evaluation failed, its error is returned. If the result is empty,
+// out is left untouched and ErrEmptyResult is returned.
+func (r evalResult) decode(out interface{}) error {
+	switch {
+	case r.err != nil:
+		return r.err
+	case r.empty():
+		return ErrEmptyResult
+	}
+	return r.v.To(out)
+}
+
+// decodeOptional decodes the value into out, if there is one.
+//
+// If the evaluation failed, its error is returned. If the result
+// is empty, out is left untouched.
+func (r evalResult) decodeOptional(out interface{}) error {
+	if r.empty() {
+		return nil
+	}
+	return r.decode(out)
+}
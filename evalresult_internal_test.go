@@ -0,0 +1,97 @@
+package glass
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalResult_Decode(t *testing.T) {
+	tests := []struct {
+		name            string
+		res             evalResult
+		want            string
+		wantErr         error
+		wantOptionalErr error
+	}{
+		{
+			name: "value",
+			res:  newEvalResult(NewValue(`"test"`, nil)),
+			want: "test",
+		},
+		{
+			name:    "empty",
+			res:     newEvalResult(NewValue("", nil)),
+			want:    "untouched",
+			wantErr: ErrEmptyResult,
+		},
+		{
+			name:            "error",
+			res:             newEvalResult(NewValue("", errors.New("test"))),
+			want:            "untouched",
+			wantErr:         errors.New("test"),
+			wantOptionalErr: errors.New("test"),
+		},
+		{
+			name:            "failed",
+			res:             evalFailed(ErrClosed),
+			want:            "untouched",
+			wantErr:         ErrClosed,
+			wantOptionalErr: ErrClosed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := "untouched"
+			err := test.res.decode(&got)
+
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+
+			got = "untouched"
+			err = test.res.decodeOptional(&got)
+
+			assert.Equal(t, test.wantOptionalErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestUIContext_EvalIntoStrict(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	require.NoError(t, win.OnEval("document.title;", "Mirror", nil))
+
+	var title string
+	err := uiCtx.EvalIntoStrict(&title, "document.title;")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Mirror", title)
+}
+
+func TestUIContext_EvalIntoStrictHandlesEmptyResult(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	var title string
+	err := uiCtx.EvalIntoStrict(&title, "void %d;", 0)
+
+	assert.True(t, errors.Is(err, ErrEmptyResult))
+	assert.EqualError(t, err, "eval result is empty: void 0;")
+}
+
+func TestUIContext_EvalIntoStrictHandlesBatch(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	evals := len(win.Evals())
+
+	var err error
+	batchErr := uiCtx.Batch(func() {
+		var title string
+		err = uiCtx.EvalIntoStrict(&title, "document.title;")
+	})
+
+	require.NoError(t, batchErr)
+	assert.True(t, errors.Is(err, ErrBatching))
+	assert.Len(t, win.Evals(), evals)
+}
@@ -0,0 +1,71 @@
+package glass
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// AllTopics subscribes to events on every topic.
+const AllTopics = "*"
+
+// Event is a message published on the event bus.
+type Event struct {
+	Topic string
+	Data  json.RawMessage
+
+	// Origin identifies where the event came from when it was
+	// published from outside the mirror, e.g. "mqtt".
+	Origin string
+}
+
+// EventBus delivers events to the subscribers of their topic.
+type EventBus struct {
+	mu   sync.RWMutex
+	id   int
+	subs map[string]map[int]func(Event)
+}
+
+// NewEventBus returns an event bus.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subs: map[string]map[int]func(Event){},
+	}
+}
+
+// Publish publishes the event to the subscribers of its topic.
+func (b *EventBus) Publish(e Event) {
+	b.mu.RLock()
+	var fns []func(Event)
+	for _, fn := range b.subs[e.Topic] {
+		fns = append(fns, fn)
+	}
+	for _, fn := range b.subs[AllTopics] {
+		fns = append(fns, fn)
+	}
+	b.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(e)
+	}
+}
+
+// Subscribe calls fn for every event published on the topic,
+// returning a function that removes the subscription.
+func (b *EventBus) Subscribe(topic string, fn func(Event)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.id++
+	id := b.id
+	if b.subs[topic] == nil {
+		b.subs[topic] = map[int]func(Event){}
+	}
+	b.subs[topic][id] = fn
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subs[topic], id)
+	}
+}
@@ -0,0 +1,128 @@
+package glass
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandEnv expands environment variable references in the string values
+// of the document.
+//
+// A reference is either "${VAR}" or "${VAR:-default}", where the default is
+// used when the variable is unset or empty. A "$$" is a literal "$". Values
+// are expanded before computed values, while expanded values are never
+// computed themselves.
+func expandEnv(doc *yaml.Node) error {
+	return expandNode(doc, nil)
+}
+
+func expandNode(n *yaml.Node, path []string) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, child := range n.Content {
+			if err := expandNode(child, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if err := expandNode(n.Content[i+1], appendPath(path, n.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range n.Content {
+			if err := expandNode(child, appendPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if n.Tag != "!!str" || !strings.Contains(n.Value, "$") {
+			return nil
+		}
+
+		v, err := expandString(n.Value)
+		if err != nil {
+			return fmt.Errorf("config: %q: %w", strings.Join(path, "."), err)
+		}
+		if strings.HasPrefix(v, computedPrefix) && !strings.HasPrefix(n.Value, computedPrefix) {
+			v = computedPrefix + v
+		}
+		n.Value = v
+		if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle|yaml.TaggedStyle) == 0 {
+			// Plain values are resolved again, so numbers and booleans
+			// from the environment are decoded as such.
+			n.Tag = ""
+			n.Tag = n.ShortTag()
+		}
+	}
+	return nil
+}
+
+// expandString expands the environment variable references in s.
+func expandString(s string) (string, error) {
+	var sb strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		sb.WriteString(s[:i])
+		s = s[i+1:]
+
+		switch s[0] {
+		case '$':
+			sb.WriteByte('$')
+			s = s[1:]
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			continue
+		}
+
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference %q", "$"+s)
+		}
+		ref := s[1:end]
+		s = s[end+1:]
+
+		name, def, hasDef := ref, "", false
+		if idx := strings.Index(ref, ":-"); idx >= 0 {
+			name, def, hasDef = ref[:idx], ref[idx+2:], true
+		}
+		if !isEnvName(name) {
+			return "", fmt.Errorf("invalid variable name %q", name)
+		}
+
+		v, ok := os.LookupEnv(name)
+		switch {
+		case hasDef && v == "":
+			v = def
+		case !ok:
+			return "", fmt.Errorf("environment variable %q is not set", name)
+		}
+		sb.WriteString(v)
+	}
+}
+
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
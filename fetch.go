@@ -0,0 +1,177 @@
+package glass
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPTimeout is the default maximum time a module http request may take.
+const DefaultHTTPTimeout = 30 * time.Second
+
+// fetchBackoff is the delay before the first retry of a failed request,
+// doubling with each retry.
+const fetchBackoff = 500 * time.Millisecond
+
+// HTTPConfig contains configuration for the http client shared by modules.
+type HTTPConfig struct {
+	// Timeout is the maximum time each attempt of a request may take.
+	// Zero means DefaultHTTPTimeout.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retries is the number of times a request is retried when it fails
+	// or the server responds with a 5xx status.
+	Retries int `yaml:"retries"`
+
+	// Proxy is the url of the proxy requests are sent through.
+	// If empty, the proxy is taken from the environment.
+	Proxy string `yaml:"proxy"`
+}
+
+// Validate validates the http configuration.
+func (c HTTPConfig) Validate() error {
+	if c.Timeout < 0 || c.Retries < 0 {
+		return errors.New("config: http timeout and retries cannot be negative")
+	}
+	if c.Proxy != "" {
+		if _, err := url.Parse(c.Proxy); err != nil {
+			return fmt.Errorf("config: invalid http proxy: %w", err)
+		}
+	}
+	return nil
+}
+
+// Fetcher makes http requests for modules, sharing a connection pool.
+type Fetcher struct {
+	mu      sync.RWMutex
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// NewFetcher returns a fetcher.
+func NewFetcher(cfg HTTPConfig) *Fetcher {
+	f := &Fetcher{backoff: fetchBackoff}
+	f.configure(cfg)
+	return f
+}
+
+// configure replaces the client of the fetcher. Requests in flight
+// complete with the previous client.
+func (f *Fetcher) configure(cfg HTTPConfig) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		tr.Proxy = func(*http.Request) (*url.URL, error) {
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy: %w", err)
+			}
+			return proxy, nil
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client != nil {
+		f.client.CloseIdleConnections()
+	}
+	f.client = &http.Client{Transport: tr, Timeout: timeout}
+	f.retries = cfg.Retries
+}
+
+// Client returns the http client of the fetcher. Requests made with
+// the client are not retried.
+func (f *Fetcher) Client() *http.Client {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.client
+}
+
+// Do sends the request, retrying with an exponential backoff when it
+// fails or the server responds with a 5xx status. Requests with a body
+// are only retried if the body can be rewound.
+//
+// The response of the last attempt is returned as is, whatever its status.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	f.mu.RLock()
+	client, retries, delay := f.client, f.retries, f.backoff
+	f.mu.RUnlock()
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("could not rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		resp, err := client.Do(req)
+		if attempt >= retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// retryable determines if a request should be retried.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// defaultFetcher is used by modules without a runtime, e.g. in tests.
+var defaultFetcher = NewFetcher(HTTPConfig{})
+
+func (u *UIContext) fetcher() *Fetcher {
+	if u.http == nil {
+		return defaultFetcher
+	}
+	return u.http
+}
+
+// Fetch sends the http request using the shared http client, retrying
+// when it fails or the server responds with a 5xx status.
+//
+// The response is returned as is, whatever its status, and
+// must be closed by the caller.
+func (u *UIContext) Fetch(req *http.Request) (*http.Response, error) {
+	return u.fetcher().Do(req)
+}
+
+// HTTPClient returns the shared http client, for libraries that need
+// an *http.Client. Requests made with the client are not retried.
+func (u *UIContext) HTTPClient() *http.Client {
+	return u.fetcher().Client()
+}
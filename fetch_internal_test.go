@@ -0,0 +1,179 @@
+package glass
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     HTTPConfig
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid config",
+			cfg:     HTTPConfig{Timeout: time.Second, Retries: 2, Proxy: "http://proxy:3128"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "handles negative retries",
+			cfg:     HTTPConfig{Retries: -1},
+			wantErr: require.Error,
+		},
+		{
+			name:    "handles invalid proxy",
+			cfg:     HTTPConfig{Proxy: "http://proxy:port"},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+
+			test.wantErr(t, err)
+		})
+	}
+}
+
+func TestFetcher_DoRetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		if atomic.AddInt32(&calls, 1) < 3 {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = rw.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewFetcher(HTTPConfig{Retries: 2})
+	f.backoff = time.Millisecond
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	resp, err := f.Do(req)
+
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", string(b))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_DoReturnsLastResponse(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewFetcher(HTTPConfig{Retries: 1})
+	f.backoff = time.Millisecond
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := f.Do(req)
+
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_DoDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewFetcher(HTTPConfig{Retries: 2})
+	f.backoff = time.Millisecond
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := f.Do(req)
+
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_DoStopsRetryingWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	f := NewFetcher(HTTPConfig{Retries: 2})
+	f.backoff = time.Millisecond
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := f.Do(req)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFetcher_DoUsesProxy(t *testing.T) {
+	var got string
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.URL.String()
+	}))
+	t.Cleanup(proxy.Close)
+
+	f := NewFetcher(HTTPConfig{Proxy: proxy.URL})
+	req, err := http.NewRequest(http.MethodGet, "http://weather.example.com/forecast", nil)
+	require.NoError(t, err)
+
+	resp, err := f.Do(req)
+
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "http://weather.example.com/forecast", got)
+}
+
+func TestUIContext_Fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+
+	uiCtx, _ := NewTestUIContext()
+	uiCtx.http = NewFetcher(HTTPConfig{})
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := uiCtx.Fetch(req)
+
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(b))
+	assert.Same(t, uiCtx.http.Client(), uiCtx.HTTPClient())
+}
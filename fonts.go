@@ -0,0 +1,106 @@
+package glass
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/glasslabs/looking-glass/module"
+)
+
+// fontFormats are the css formats of the supported font files.
+var fontFormats = map[string]string{
+	".woff2": "woff2",
+	".woff":  "woff",
+	".ttf":   "truetype",
+	".otf":   "opentype",
+}
+
+// LoadFont registers the font in the page, making it available to module css.
+// Local font files are resolved relative to dir and embedded in the page.
+//
+// A font is only loaded once for a family, weight and style, so modules
+// declaring the same font share it. A font that fails to load is loaded
+// again by the next module declaring it.
+func (ui *UI) LoadFont(font module.Font, dir string) error {
+	ui.fontsMu.Lock()
+	defer ui.fontsMu.Unlock()
+
+	key := strings.ToLower(font.Family) + "|" + font.Weight + "|" + font.Style
+	ui.mu.RLock()
+	loaded, n := ui.fonts[key], len(ui.fonts)
+	ui.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	css, err := fontFaceCSS(font, dir)
+	if err != nil {
+		return fmt.Errorf("could not load font %q: %w", font.Family, err)
+	}
+
+	name, _ := json.Marshal("font" + strconv.Itoa(n+1))
+	b, _ := json.Marshal(css)
+	js := fmt.Sprintf("loadCSS(%s, %s);", name, b)
+	if _, err = ui.Eval(js); err != nil {
+		return fmt.Errorf("could not load font %q: %w", font.Family, err)
+	}
+
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	if ui.fonts == nil {
+		ui.fonts = map[string]bool{}
+	}
+	ui.fonts[key] = true
+	ui.setup = append(ui.setup, js)
+	return nil
+}
+
+// fontFaceCSS returns the @font-face rule of the font.
+func fontFaceCSS(font module.Font, dir string) (string, error) {
+	var src string
+	if isRemoteCSS(font.Src) {
+		u, err := url.Parse(font.Src)
+		if err != nil {
+			return "", err
+		}
+		src = "url(" + strconv.Quote(font.Src) + ")"
+		if format, ok := fontFormats[strings.ToLower(path.Ext(u.Path))]; ok {
+			src += " format(" + strconv.Quote(format) + ")"
+		}
+	} else {
+		format, ok := fontFormats[strings.ToLower(filepath.Ext(font.Src))]
+		if !ok {
+			return "", fmt.Errorf("unsupported font file %q", font.Src)
+		}
+		p := font.Src
+		if !filepath.IsAbs(p) && dir != "" {
+			p = filepath.Join(dir, p)
+		}
+		b, err := readAsset(p)
+		if err != nil {
+			return "", err
+		}
+		data := "data:font/" + format + ";base64," + base64.StdEncoding.EncodeToString(b)
+		src = "url(" + strconv.Quote(data) + ") format(" + strconv.Quote(format) + ")"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("@font-face {")
+	sb.WriteString(" font-family: " + strconv.Quote(font.Family) + ";")
+	sb.WriteString(" src: " + src + ";")
+	if font.Weight != "" {
+		sb.WriteString(" font-weight: " + font.Weight + ";")
+	}
+	if font.Style != "" {
+		sb.WriteString(" font-style: " + font.Style + ";")
+	}
+	sb.WriteString(" font-display: block; }")
+	return sb.String(), nil
+}
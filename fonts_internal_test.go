@@ -0,0 +1,100 @@
+package glass
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUI_LoadFont(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "fonts"), 0o750)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "fonts", "icons.woff2"), []byte("font"), 0o600)
+	require.NoError(t, err)
+
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	err = ui.LoadFont(module.Font{Family: "Icons", Src: "fonts/icons.woff2", Weight: "400"}, dir)
+
+	require.NoError(t, err)
+	data := base64.StdEncoding.EncodeToString([]byte("font"))
+	want := `loadCSS("font1", "@font-face { font-family: \"Icons\"; src: url(\"data:font/woff2;base64,` + data +
+		`\") format(\"woff2\"); font-weight: 400; font-display: block; }");`
+	assert.Equal(t, []string{want}, win.Evals())
+}
+
+func TestUI_LoadFontRemote(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	err := ui.LoadFont(module.Font{Family: "Icons", Src: "https://example.com/icons.ttf?v=2"}, "")
+
+	require.NoError(t, err)
+	want := `loadCSS("font1", "@font-face { font-family: \"Icons\"; src: url(\"https://example.com/icons.ttf?v=2\") format(\"truetype\"); font-display: block; }");`
+	assert.Equal(t, []string{want}, win.Evals())
+}
+
+func TestUI_LoadFontDeduplicatesFaces(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	err := ui.LoadFont(module.Font{Family: "Icons", Src: "https://example.com/icons.woff2"}, "")
+	require.NoError(t, err)
+	err = ui.LoadFont(module.Font{Family: "icons", Src: "https://example.com/other.woff2"}, "")
+	require.NoError(t, err)
+	err = ui.LoadFont(module.Font{Family: "Icons", Src: "https://example.com/icons-bold.woff2", Weight: "bold"}, "")
+	require.NoError(t, err)
+
+	assert.Len(t, win.Evals(), 2)
+	assert.Len(t, ui.setup, 2)
+}
+
+func TestUI_LoadFontHandlesUnsupportedFiles(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow()}
+
+	err := ui.LoadFont(module.Font{Family: "Icons", Src: "icons.svg"}, t.TempDir())
+
+	assert.EqualError(t, err, `could not load font "Icons": unsupported font file "icons.svg"`)
+}
+
+func TestUI_LoadFontHandlesMissingFiles(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	err := ui.LoadFont(module.Font{Family: "Icons", Src: "icons.woff"}, t.TempDir())
+
+	assert.Error(t, err)
+	assert.Empty(t, win.Evals())
+	assert.Empty(t, ui.setup)
+}
+
+func TestUI_LoadFontRetriesFailedLoads(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	font := module.Font{Family: "Icons", Src: "https://example.com/icons.woff2"}
+	css, err := fontFaceCSS(font, "")
+	require.NoError(t, err)
+	b, _ := json.Marshal(css)
+	js := `loadCSS("font1", ` + string(b) + `);`
+	require.NoError(t, win.OnEval(js, nil, errors.New("test error")))
+
+	err = ui.LoadFont(font, "")
+	require.Error(t, err)
+	assert.Empty(t, ui.setup)
+
+	require.NoError(t, win.OnEval(js, nil, nil))
+	err = ui.LoadFont(font, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{js, js}, win.Evals())
+	assert.Equal(t, []string{js}, ui.setup)
+}
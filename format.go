@@ -0,0 +1,79 @@
+package glass
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configExts are the configuration file extensions loaded from a directory.
+var configExts = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".toml": true,
+	".json": true,
+}
+
+// decodeConfig decodes the configuration document in, detecting its format
+// from the file extension ext. Yaml is used for unknown extensions.
+//
+// All formats are decoded into a yaml document, so the rest of the
+// configuration is handled the same way regardless of format.
+func decodeConfig(in []byte, ext string) (*yaml.Node, error) {
+	switch strings.ToLower(ext) {
+	case ".toml":
+		return decodeTOML(in)
+	case ".json":
+		return decodeJSON(in)
+	default:
+		var doc yaml.Node
+		if err := yaml.Unmarshal(in, &doc); err != nil {
+			return nil, fmt.Errorf("yaml decoder: %w", err)
+		}
+		return &doc, nil
+	}
+}
+
+func decodeTOML(in []byte) (*yaml.Node, error) {
+	var v map[string]interface{}
+	if _, err := toml.Decode(string(in), &v); err != nil {
+		return nil, fmt.Errorf("toml decoder: %w", err)
+	}
+	if len(v) == 0 {
+		return &yaml.Node{}, nil
+	}
+
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return nil, fmt.Errorf("toml decoder: %w", err)
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&n}}, nil
+}
+
+func decodeJSON(in []byte) (*yaml.Node, error) {
+	if len(bytes.TrimSpace(in)) == 0 {
+		return &yaml.Node{}, nil
+	}
+
+	// Json is a subset of yaml, so the document is parsed as yaml once it
+	// is known to be valid json, keeping the key order of the document.
+	var v interface{}
+	if err := json.Unmarshal(in, &v); err != nil {
+		var synErr *json.SyntaxError
+		if errors.As(err, &synErr) {
+			line := bytes.Count(in[:synErr.Offset], []byte("\n")) + 1
+			return nil, fmt.Errorf("json decoder: line %d (offset %d): %w", line, synErr.Offset, err)
+		}
+		return nil, fmt.Errorf("json decoder: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(in, &doc); err != nil {
+		return nil, fmt.Errorf("json decoder: %w", err)
+	}
+	return &doc, nil
+}
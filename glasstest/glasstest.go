@@ -0,0 +1,57 @@
+// Package glasstest provides a fake module ui for testing modules without chrome.
+package glasstest
+
+import (
+	glass "github.com/glasslabs/looking-glass"
+)
+
+// UI is a fake module ui that records all calls made by the module.
+//
+// It is backed by the same ui context used when running the module,
+// so calls behave as they would in chrome, while the javascript they
+// evaluate is recorded instead of run.
+type UI struct {
+	*glass.UIContext
+
+	win *glass.RecordingWindow
+}
+
+// NewUI returns a fake module ui.
+func NewUI() *UI {
+	uiCtx, win := glass.NewTestUIContext()
+	return &UI{
+		UIContext: uiCtx,
+		win:       win,
+	}
+}
+
+// Window returns the recording window backing the ui.
+func (u *UI) Window() *glass.RecordingWindow {
+	return u.win
+}
+
+// HTML returns the html last loaded into the module element.
+func (u *UI) HTML() string {
+	return u.win.HTML(glass.TestModuleName)
+}
+
+// CSS returns all css loaded by the module.
+func (u *UI) CSS() []string {
+	return u.win.CSS(glass.TestModuleName)
+}
+
+// Evals returns all evaluated javascript.
+func (u *UI) Evals() []string {
+	return u.win.Evals()
+}
+
+// Binding returns the function bound to the given name.
+func (u *UI) Binding(name string) (interface{}, bool) {
+	return u.win.Binding(name)
+}
+
+// OnEval scripts the result of evaluating the javascript js. The result is
+// encoded as json and returned, along with err, each time js is evaluated.
+func (u *UI) OnEval(js string, result interface{}, err error) error {
+	return u.win.OnEval(js, result, err)
+}
@@ -0,0 +1,75 @@
+package glasstest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/glasstest"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greeter struct {
+	ui types.UI
+}
+
+func (g greeter) Greet(name string) error {
+	if err := g.ui.LoadCSS(".greeting { color: #fff; }"); err != nil {
+		return err
+	}
+	var width int
+	if err := g.ui.EvalInto(&width, "window.innerWidth"); err != nil {
+		return err
+	}
+	return g.ui.LoadHTML(fmt.Sprintf(`<p class="greeting" data-width="%d">Hello %s</p>`, width, name))
+}
+
+func TestUI(t *testing.T) {
+	ui := glasstest.NewUI()
+	err := ui.OnEval("window.innerWidth", 1024, nil)
+	require.NoError(t, err)
+
+	err = greeter{ui: ui}.Greet("Bob")
+
+	require.NoError(t, err)
+	assert.Equal(t, `<p class="greeting" data-width="1024">Hello Bob</p>`, ui.HTML())
+	assert.Equal(t, []string{".greeting { color: #fff; }"}, ui.CSS())
+	assert.Contains(t, ui.Evals(), "window.innerWidth")
+}
+
+func TestUI_OnEvalReturnsErrors(t *testing.T) {
+	ui := glasstest.NewUI()
+	err := ui.OnEval("window.innerWidth", nil, errors.New("test error"))
+	require.NoError(t, err)
+
+	err = greeter{ui: ui}.Greet("Bob")
+
+	assert.EqualError(t, err, "test error")
+	assert.Empty(t, ui.HTML())
+}
+
+func TestUI_RecordsBindings(t *testing.T) {
+	ui := glasstest.NewUI()
+
+	err := ui.Bind("greet", func() string { return "hello" })
+	require.NoError(t, err)
+
+	fn, ok := ui.Binding("greet")
+	require.True(t, ok)
+	assert.Equal(t, "hello", fn.(func() string)())
+}
+
+func TestUI_ExpandsBatches(t *testing.T) {
+	ui := glasstest.NewUI()
+
+	err := ui.Batch(func() {
+		_ = ui.LoadCSS(".greeting { color: #fff; }")
+		_ = ui.LoadHTML(`<p class="greeting">Hello Bob</p>`)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, `<p class="greeting">Hello Bob</p>`, ui.HTML())
+	assert.Equal(t, []string{".greeting { color: #fff; }"}, ui.CSS())
+}
@@ -3,17 +3,22 @@ module github.com/glasslabs/looking-glass
 go 1.17
 
 require (
+	github.com/BurntSushi/toml v1.1.0
 	github.com/agiledragon/gomonkey/v2 v2.7.0
+	github.com/eclipse/paho.mqtt.golang v1.4.1
+	github.com/fsnotify/fsnotify v1.5.4
 	github.com/hamba/cmd/v2 v2.3.0
 	github.com/hamba/logger/v2 v2.3.0
 	github.com/hamba/testutils v0.1.1
 	github.com/joho/godotenv v1.4.0
+	github.com/prometheus/client_golang v1.12.1
 	github.com/stretchr/testify v1.7.1
 	github.com/traefik/yaegi v0.11.2
 	github.com/urfave/cli/v2 v2.4.0
 	github.com/vincent-petithory/dataurl v0.0.0-20191104211930-d1553a71de50
 	github.com/zserge/lorca v0.1.10
 	golang.org/x/mod v0.5.1
+	golang.org/x/net v0.0.0-20210917221730-978cfadd31cf
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
 
@@ -28,13 +33,13 @@ require (
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/hamba/statter/v2 v2.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.9 // indirect
 	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
 	github.com/openzipkin/zipkin-go v0.4.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/prometheus/client_golang v1.12.1 // indirect
 	github.com/prometheus/client_model v0.2.0 // indirect
 	github.com/prometheus/common v0.32.1 // indirect
 	github.com/prometheus/procfs v0.7.3 // indirect
@@ -47,8 +52,8 @@ require (
 	go.opentelemetry.io/otel/exporters/zipkin v1.4.1 // indirect
 	go.opentelemetry.io/otel/sdk v1.4.1 // indirect
 	go.opentelemetry.io/otel/trace v1.4.1 // indirect
-	golang.org/x/net v0.0.0-20210917221730-978cfadd31cf // indirect
-	golang.org/x/sys v0.0.0-20220114195835-da31bd327af9 // indirect
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
 )
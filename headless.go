@@ -0,0 +1,47 @@
+package glass
+
+import (
+	"errors"
+	"io"
+
+	"github.com/glasslabs/looking-glass/internal/i18n"
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/hamba/logger/v2"
+	modver "golang.org/x/mod/module"
+)
+
+// errOffline is returned when a headless runtime tries to download a module.
+var errOffline = errors.New("modules cannot be downloaded by a headless runtime")
+
+// offlineClient is a module client that cannot download modules.
+type offlineClient struct{}
+
+func (offlineClient) Version(string, string) (modver.Version, error) {
+	return modver.Version{}, errOffline
+}
+
+func (offlineClient) Download(modver.Version) (io.ReadCloser, error) { return nil, errOffline }
+
+// NewHeadlessRuntime returns a runtime that runs the configured modules from
+// modPath against a recording window, without chrome.
+//
+// This is used to test the full configuration to render pipeline. All calls
+// made to the ui can be inspected on the returned window. Modules must already
+// be present in the module path, as they cannot be downloaded.
+func NewHeadlessRuntime(cfg Config, modPath string) (*Runtime, *RecordingWindow, error) {
+	svc, err := module.NewService(modPath, offlineClient{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lang, err := i18n.Load(cfg.UI.Lang, cfg.UI.Locales)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	win := NewRecordingWindow()
+	ui := &UI{win: win, lang: lang}
+
+	log := logger.New(io.Discard, logger.LogfmtFormat(), logger.Error)
+	return NewRuntime(cfg, ui, svc, log), win, nil
+}
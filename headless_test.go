@@ -0,0 +1,40 @@
+package glass_test
+
+import (
+	"context"
+	"testing"
+
+	glass "github.com/glasslabs/looking-glass"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHeadlessRuntime(t *testing.T) {
+	in := []byte(`
+modules:
+  - name: greeting
+    path: hello
+    position: top:left
+  - name: welcome
+    path: hello
+    position: bottom:right
+    config:
+      name: Bob
+`)
+	cfg, err := glass.ParseConfig(in, "testdata", nil)
+	require.NoError(t, err)
+
+	rt, win, err := glass.NewHeadlessRuntime(cfg, "testdata/mod")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = rt.Close() })
+
+	err = rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), `createModules([{"name":"greeting","vert":"top","horiz":"left"},{"name":"welcome","vert":"bottom","horiz":"right"}]);`)
+	assert.Equal(t, "<p>Hello World</p>", win.HTML("greeting"))
+	assert.Equal(t, "<p>Hello Bob</p>", win.HTML("welcome"))
+	for _, state := range rt.RuntimeState() {
+		assert.True(t, state.Healthy, state.Name)
+	}
+}
@@ -0,0 +1,160 @@
+package glass
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeConfigs merges the files listed under the include key of the
+// configuration doc over it, in order. Modules are appended instead of
+// replaced. Relative paths and globs are resolved against dir.
+//
+// The files currently being loaded are given in loading, to detect cycles.
+func includeConfigs(doc *yaml.Node, dir string, secrets map[string]interface{}, loading []string) error {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	idx := mappingIndex(root, "include")
+	if idx < 0 {
+		return nil
+	}
+	patterns, err := includePatterns(root.Content[idx])
+	if err != nil {
+		return err
+	}
+	root.Content = append(root.Content[:idx-1], root.Content[idx+1:]...)
+
+	for _, pattern := range patterns {
+		files, err := includeFiles(pattern, dir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			n, err := loadInclude(file, secrets, loading)
+			if err != nil {
+				return err
+			}
+			if err = mergeInclude(root, n, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// includePatterns returns the paths of an include, which is either
+// a single path or a list of paths.
+func includePatterns(n *yaml.Node) ([]string, error) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return []string{n.Value}, nil
+	case yaml.SequenceNode:
+		patterns := make([]string, 0, len(n.Content))
+		for _, p := range n.Content {
+			if p.Kind != yaml.ScalarNode {
+				return nil, errors.New("config: include must be a path or a list of paths")
+			}
+			patterns = append(patterns, p.Value)
+		}
+		return patterns, nil
+	default:
+		return nil, errors.New("config: include must be a path or a list of paths")
+	}
+}
+
+// includeFiles returns the files matching the include pattern, in lexical order.
+// A pattern without glob characters must exist.
+func includeFiles(pattern, dir string) ([]string, error) {
+	if pattern == "" {
+		return nil, errors.New("config: include path cannot be empty")
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid include pattern %q: %w", pattern, err)
+	}
+	return files, nil
+}
+
+// loadInclude loads the included configuration file, along with its own includes.
+func loadInclude(file string, secrets map[string]interface{}, loading []string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("config: could not resolve include %q: %w", file, err)
+	}
+	for i, f := range loading {
+		if f == abs {
+			return nil, fmt.Errorf("config: include cycle: %s", strings.Join(append(loading[i:], abs), " -> "))
+		}
+	}
+
+	in, err := os.ReadFile(filepath.Clean(abs))
+	if err != nil {
+		return nil, fmt.Errorf("config: could not read include: %w", err)
+	}
+	b, err := executeTemplate(in, filepath.Dir(abs), secrets)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	n, err := decodeConfig(b, filepath.Ext(abs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	// The slice is copied so includes in sibling files do not share it.
+	loading = append(append([]string(nil), loading...), abs)
+	if err = includeConfigs(n, filepath.Dir(abs), secrets, loading); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// mergeInclude merges the included configuration document n over the mapping root.
+func mergeInclude(root, n *yaml.Node, file string) error {
+	if n.Kind != yaml.DocumentNode || len(n.Content) == 0 {
+		return nil
+	}
+	inc := n.Content[0]
+	if inc.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: configuration must be a mapping", file)
+	}
+
+	for i := 0; i < len(inc.Content); i += 2 {
+		key, val := inc.Content[i], inc.Content[i+1]
+
+		idx := mappingIndex(root, key.Value)
+		switch {
+		case idx < 0:
+			root.Content = append(root.Content, key, val)
+		case key.Value == "modules":
+			if val.Kind != yaml.SequenceNode || root.Content[idx].Kind != yaml.SequenceNode {
+				return fmt.Errorf("%s: modules must be a list", file)
+			}
+			root.Content[idx].Content = append(root.Content[idx].Content, val.Content...)
+		case root.Content[idx].Kind == yaml.MappingNode && val.Kind == yaml.MappingNode:
+			merged, err := mergeNodes(root.Content[idx], val)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			root.Content[idx] = merged
+		default:
+			root.Content[idx] = val
+		}
+	}
+	return nil
+}
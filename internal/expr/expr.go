@@ -0,0 +1,425 @@
+// Package expr implements a small expression language.
+//
+// Expressions support the operators "&&", "||", "!", "==" and "!=",
+// the arithmetic operators "+", "-", "*" and "/", parentheses, string
+// and number literals, the literals true and false, and dotted
+// identifiers that are resolved against a context.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed expression.
+type Expr struct {
+	root node
+}
+
+// Parse parses an expression.
+func Parse(s string) (*Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.val, tok.pos)
+	}
+	return &Expr{root: root}, nil
+}
+
+// Idents returns the dotted identifiers referenced by the expression.
+func (e *Expr) Idents() []string {
+	var idents []string
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case ident:
+			idents = append(idents, strings.Join(n.path, "."))
+		case not:
+			walk(n.x)
+		case neg:
+			walk(n.x)
+		case binary:
+			walk(n.l)
+			walk(n.r)
+		}
+	}
+	walk(e.root)
+	return idents
+}
+
+// Eval evaluates the expression against the context.
+//
+// Identifiers are resolved by walking nested maps in the context.
+// Missing identifiers resolve to nil, which is false.
+func (e *Expr) Eval(ctx map[string]interface{}) bool {
+	return truthy(e.root.eval(ctx))
+}
+
+// Value evaluates the expression against the context, returning its value.
+//
+// Arithmetic on values that are not numbers results in nil.
+func (e *Expr) Value(ctx map[string]interface{}) interface{} {
+	return e.root.eval(ctx)
+}
+
+type node interface {
+	eval(ctx map[string]interface{}) interface{}
+}
+
+type (
+	literal struct{ val interface{} }
+	ident   struct{ path []string }
+	not     struct{ x node }
+	neg     struct{ x node }
+	binary  struct {
+		op   string
+		l, r node
+	}
+)
+
+func (n literal) eval(map[string]interface{}) interface{} { return n.val }
+
+func (n ident) eval(ctx map[string]interface{}) interface{} {
+	var v interface{} = ctx
+	for _, name := range n.path {
+		switch m := v.(type) {
+		case map[string]interface{}:
+			v = m[name]
+		case map[string]string:
+			v = m[name]
+		case map[string]bool:
+			v = m[name]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func (n not) eval(ctx map[string]interface{}) interface{} { return !truthy(n.x.eval(ctx)) }
+
+func (n neg) eval(ctx map[string]interface{}) interface{} {
+	x, ok := number(n.x.eval(ctx))
+	if !ok {
+		return nil
+	}
+	return -x
+}
+
+func (n binary) eval(ctx map[string]interface{}) interface{} {
+	switch n.op {
+	case "+", "-", "*", "/":
+		return arith(n.op, n.l.eval(ctx), n.r.eval(ctx))
+	case "&&":
+		return truthy(n.l.eval(ctx)) && truthy(n.r.eval(ctx))
+	case "||":
+		return truthy(n.l.eval(ctx)) || truthy(n.r.eval(ctx))
+	case "==":
+		return equal(n.l.eval(ctx), n.r.eval(ctx))
+	default:
+		return !equal(n.l.eval(ctx), n.r.eval(ctx))
+	}
+}
+
+func arith(op string, a, b interface{}) interface{} {
+	x, ok := number(a)
+	if !ok {
+		return nil
+	}
+	y, ok := number(b)
+	if !ok {
+		return nil
+	}
+
+	switch op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	default:
+		if y == 0 {
+			return nil
+		}
+		return x / y
+	}
+}
+
+func number(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	default:
+		return 0, false
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case float64:
+		return val != 0
+	default:
+		return true
+	}
+}
+
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().val == "||" {
+		p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: "||", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseCmp()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().val == "&&" {
+		p.next()
+		r, err := p.parseCmp()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: "&&", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseCmp() (node, error) {
+	l, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peek().val; op == "==" || op == "!=" {
+		p.next()
+		r, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	l, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek().val; op == "+" || op == "-"; op = p.peek().val {
+		p.next()
+		r, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseProduct() (node, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek().val; op == "*" || op == "/"; op = p.peek().val {
+		p.next()
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch p.peek().val {
+	case "!":
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{x: x}, nil
+	case "-":
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return neg{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.typ {
+	case tokString:
+		return literal{val: tok.val}, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.val, tok.pos)
+		}
+		return literal{val: f}, nil
+	case tokIdent:
+		switch tok.val {
+		case "true":
+			return literal{val: true}, nil
+		case "false":
+			return literal{val: false}, nil
+		}
+		return ident{path: strings.Split(tok.val, ".")}, nil
+	case tokOp:
+		if tok.val != "(" {
+			break
+		}
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.val != ")" {
+			return nil, fmt.Errorf("expected \")\" at position %d", closing.pos)
+		}
+		return x, nil
+	case tokEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.val, tok.pos)
+}
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+func lex(s string) ([]token, error) {
+	var toks []token
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != '"' {
+				j++
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			toks = append(toks, token{typ: tokString, val: string(rs[i+1 : j]), pos: i})
+			i = j + 1
+		case isIdentRune(r, true):
+			j := i
+			for j < len(rs) && (isIdentRune(rs[j], false) || rs[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{typ: tokIdent, val: string(rs[i:j]), pos: i})
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{typ: tokNumber, val: string(rs[i:j]), pos: i})
+			i = j
+		case r == '(' || r == ')' || r == '+' || r == '-' || r == '*' || r == '/':
+			toks = append(toks, token{typ: tokOp, val: string(r), pos: i})
+			i++
+		case r == '!' && (i+1 >= len(rs) || rs[i+1] != '='):
+			toks = append(toks, token{typ: tokOp, val: "!", pos: i})
+			i++
+		default:
+			if i+1 < len(rs) {
+				switch op := string(rs[i : i+2]); op {
+				case "&&", "||", "==", "!=":
+					toks = append(toks, token{typ: tokOp, val: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+		}
+	}
+	return append(toks, token{typ: tokEOF, pos: len(rs)}), nil
+}
+
+func isIdentRune(r rune, first bool) bool {
+	if r == '_' || unicode.IsLetter(r) {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || r == '-')
+}
@@ -0,0 +1,103 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/glasslabs/looking-glass/internal/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	ctx := map[string]interface{}{
+		"features": map[string]bool{"foo": true, "bar": false},
+		"env":      map[string]string{"ROOM": "kitchen"},
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{name: "feature", expr: "features.foo", want: true},
+		{name: "disabled feature", expr: "features.bar", want: false},
+		{name: "missing", expr: "features.baz", want: false},
+		{name: "and", expr: `features.foo && env.ROOM == "kitchen"`, want: true},
+		{name: "and false", expr: `features.foo && env.ROOM == "bedroom"`, want: false},
+		{name: "or", expr: `features.bar || env.ROOM != "bedroom"`, want: true},
+		{name: "not", expr: "!features.bar", want: true},
+		{name: "parens", expr: `!(features.foo && features.bar)`, want: true},
+		{name: "literal", expr: "true && !false", want: true},
+		{name: "missing env is empty", expr: `env.MISSING == ""`, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := expr.Parse(test.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, e.Eval(ctx))
+		})
+	}
+}
+
+func TestParse_HandlesSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{name: "dangling operator", expr: "features.foo &&", wantErr: "unexpected end of expression"},
+		{name: "unclosed paren", expr: "(features.foo", wantErr: `expected ")" at position 13`},
+		{name: "unterminated string", expr: `env.ROOM == "kitchen`, wantErr: "unterminated string at position 12"},
+		{name: "invalid character", expr: "features.foo & features.bar", wantErr: `unexpected "&" at position 13`},
+		{name: "trailing token", expr: "features.foo features.bar", wantErr: `unexpected "features.bar" at position 13`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := expr.Parse(test.expr)
+
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestExpr_Value(t *testing.T) {
+	ctx := map[string]interface{}{
+		"width": 1920,
+		"ui":    map[string]interface{}{"scale": 1.5},
+		"name":  "mirror",
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want interface{}
+	}{
+		{name: "ratio", expr: "width * 9 / 16", want: 1080.0},
+		{name: "precedence", expr: "2 + 3 * 4", want: 14.0},
+		{name: "parens", expr: "(2 + 3) * 4", want: 20.0},
+		{name: "negative", expr: "-ui.scale * 2", want: -3.0},
+		{name: "subtraction", expr: "width - 20", want: 1900.0},
+		{name: "comparison", expr: "width / 2 == 960", want: true},
+		{name: "non number", expr: "name * 2", want: nil},
+		{name: "division by zero", expr: "width / 0", want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := expr.Parse(test.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, e.Value(ctx))
+		})
+	}
+}
+
+func TestExpr_Idents(t *testing.T) {
+	e, err := expr.Parse(`ui.width * 9 / 16 + offset == 0 || !features.foo`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ui.width", "offset", "features.foo"}, e.Idents())
+}
@@ -0,0 +1,123 @@
+// Package i18n provides localised strings for the ui.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLang is the language used for missing translations.
+const DefaultLang = "en"
+
+//go:embed locales
+var locales embed.FS
+
+var defaultCatalog = mustLoadDefault()
+
+// Catalog contains the messages of a language.
+//
+// A nil catalog uses the default language.
+type Catalog struct {
+	lang     string
+	msgs     map[string]string
+	fallback map[string]string
+}
+
+// Load loads the catalog for the given language.
+//
+// Catalogs are named after their language, e.g. "de.yaml" or "de.json".
+// Catalogs in dir take precedence over the bundled catalogs.
+func Load(lang, dir string) (*Catalog, error) {
+	if lang == "" {
+		lang = DefaultLang
+	}
+
+	msgs, err := readCatalog(lang, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{
+		lang:     lang,
+		msgs:     msgs,
+		fallback: defaultCatalog.msgs,
+	}, nil
+}
+
+// Lang returns the language of the catalog.
+func (c *Catalog) Lang() string {
+	if c == nil {
+		return DefaultLang
+	}
+	return c.lang
+}
+
+// T returns the message for the given key, formatted with args.
+//
+// If the key is missing from the catalog, the default language is used.
+// If the key is missing entirely, the key is returned.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	if c == nil {
+		c = defaultCatalog
+	}
+
+	msg, ok := c.msgs[key]
+	if !ok {
+		if msg, ok = c.fallback[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+func readCatalog(lang, dir string) (map[string]string, error) {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		name := lang + ext
+		if dir != "" {
+			b, err := os.ReadFile(filepath.Join(dir, name))
+			switch {
+			case err == nil:
+				return parseCatalog(name, b)
+			case !errors.Is(err, fs.ErrNotExist):
+				return nil, fmt.Errorf("i18n: could not read catalog %q: %w", name, err)
+			}
+		}
+		if b, err := locales.ReadFile("locales/" + name); err == nil {
+			return parseCatalog(name, b)
+		}
+	}
+	return nil, fmt.Errorf("i18n: no catalog found for language %q", lang)
+}
+
+func parseCatalog(name string, b []byte) (map[string]string, error) {
+	var (
+		msgs map[string]string
+		err  error
+	)
+	if filepath.Ext(name) == ".json" {
+		err = json.Unmarshal(b, &msgs)
+	} else {
+		err = yaml.Unmarshal(b, &msgs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("i18n: could not parse catalog %q: %w", name, err)
+	}
+	return msgs, nil
+}
+
+func mustLoadDefault() *Catalog {
+	msgs, err := readCatalog(DefaultLang, "")
+	if err != nil {
+		panic(err)
+	}
+	return &Catalog{lang: DefaultLang, msgs: msgs}
+}
@@ -0,0 +1,46 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/glasslabs/looking-glass/internal/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	c, err := i18n.Load("de", "testdata")
+	require.NoError(t, err)
+
+	assert.Equal(t, "de", c.Lang())
+	assert.Equal(t, "Keine Daten", c.T("chart.noData"))
+	assert.Equal(t, "Looking Glass has stopped", c.T("error.title"))
+	assert.Equal(t, "unknown.key", c.T("unknown.key"))
+}
+
+func TestLoad_JSON(t *testing.T) {
+	c, err := i18n.Load("fr", "testdata")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Aucune donnée", c.T("chart.noData"))
+}
+
+func TestLoad_DefaultLang(t *testing.T) {
+	c, err := i18n.Load("", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", c.Lang())
+	assert.Equal(t, "No data", c.T("chart.noData"))
+}
+
+func TestLoad_HandlesMissingCatalog(t *testing.T) {
+	_, err := i18n.Load("xx", "testdata")
+
+	assert.EqualError(t, err, `i18n: no catalog found for language "xx"`)
+}
+
+func TestCatalog_TNilUsesDefault(t *testing.T) {
+	var c *i18n.Catalog
+
+	assert.Equal(t, "No data", c.T("chart.noData"))
+}
@@ -0,0 +1,167 @@
+package glass
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"runtime/debug"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// ModuleStatus is the lifecycle status of a module.
+type ModuleStatus string
+
+// Module statuses.
+const (
+	ModulePending  ModuleStatus = "MODULE_PENDING"
+	ModuleRunning  ModuleStatus = "MODULE_RUNNING"
+	ModuleSkipped  ModuleStatus = "MODULE_SKIPPED"
+	ModuleDisabled ModuleStatus = "MODULE_DISABLED"
+	ModuleFailed   ModuleStatus = "MODULE_FAILED"
+)
+
+// PanicHandler handles a panic recovered from a module.
+type PanicHandler func(module string, v interface{}, stack []byte)
+
+// OnPanic adds handlers called when a function bound by a module panics.
+func (ui *UI) OnPanic(fns ...PanicHandler) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.panicFns = append(ui.panicFns, fns...)
+}
+
+func (ui *UI) reportPanic(module string, v interface{}, stack []byte) {
+	ui.mu.RLock()
+	fns := ui.panicFns
+	ui.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(module, v, stack)
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// guardBinding wraps a function bound by the module, recovering
+// and reporting panics instead of crashing the process.
+//
+// On panic, the function returns zero values, along with an
+// error if its last return value is an error.
+func (u *UIContext) guardBinding(fun interface{}) interface{} {
+	v := reflect.ValueOf(fun)
+	if v.Kind() != reflect.Func {
+		return fun
+	}
+	typ := v.Type()
+
+	return reflect.MakeFunc(typ, func(args []reflect.Value) (res []reflect.Value) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			u.ui.reportPanic(u.name, p, debug.Stack())
+
+			res = make([]reflect.Value, typ.NumOut())
+			for i := range res {
+				res[i] = reflect.Zero(typ.Out(i))
+			}
+			if n := typ.NumOut(); n > 0 && typ.Out(n-1) == errorType {
+				err := fmt.Errorf("%s: panic: %v", u.name, p)
+				res[n-1] = reflect.ValueOf(&err).Elem()
+			}
+		}()
+
+		if typ.IsVariadic() {
+			return v.CallSlice(args)
+		}
+		return v.Call(args)
+	}).Interface()
+}
+
+// ModuleStatus returns the status of the named module.
+//
+// An empty status is returned if the module is not configured.
+func (r *Runtime) ModuleStatus(name string) ModuleStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.states {
+		if state.desc.InstanceID() == name {
+			return state.status()
+		}
+	}
+	return ""
+}
+
+// modulePanic isolates a module that panicked. The panic is logged, the
+// module is marked as failed and its element is replaced with an error badge.
+func (r *Runtime) modulePanic(name string, v interface{}, stack []byte) {
+	r.log.Error("module panicked",
+		logCtx.Str("module", name),
+		logCtx.Str("panic", fmt.Sprint(v)),
+		logCtx.Str("stack", string(stack)),
+	)
+
+	r.quarantine(name, fmt.Errorf("panic: %v", v))
+}
+
+// refreshOverrun handles a module refresh that took longer than its budget.
+func (r *Runtime) refreshOverrun(o Overrun) {
+	r.metrics.observeOverrun(o.Module)
+
+	log := r.log.With(
+		logCtx.Str("module", o.Module),
+		logCtx.Duration("took", o.Took),
+		logCtx.Duration("budget", o.Budget),
+	)
+	switch {
+	case o.Quarantined:
+		log.Error("module refresh far over budget, no longer refreshing")
+		r.quarantine(o.Module, fmt.Errorf("refresh took %s, over %d times its budget of %s", o.Took, budgetQuarantine, o.Budget))
+	case o.Overruns >= budgetOverruns:
+		log.Warn("module refresh over budget, backing off", logCtx.Duration("interval", o.Interval))
+	default:
+		log.Debug("module refresh over budget")
+	}
+}
+
+// quarantine stops refreshing the module, marks it failed and replaces
+// its html with an error badge.
+func (r *Runtime) quarantine(name string, err error) {
+	_ = r.sched.Unschedule(name)
+
+	var uiCtx *UIContext
+	r.mu.Lock()
+	for _, state := range r.states {
+		if state.desc.InstanceID() == name {
+			state.failed = true
+			state.err = err
+			uiCtx = state.ui
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if uiCtx == nil {
+		return
+	}
+	// The element is kept, so the failed module keeps its position.
+	badge := `<div class="module-error">` + html.EscapeString(r.ui.lang.T("error.moduleFailed", name)) + `</div>`
+	if err := uiCtx.LoadHTML(badge); err != nil {
+		r.log.Error("could not show module error", logCtx.Str("module", name), logCtx.Error("error", err))
+	}
+}
+
+// recoverModule recovers a panic in a module lifecycle call,
+// isolating the module. It must be deferred.
+func (r *Runtime) recoverModule(name string, panicked *bool) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	*panicked = true
+	r.modulePanic(name, v, debug.Stack())
+}
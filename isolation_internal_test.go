@@ -0,0 +1,116 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_IsolatesModulePanicOnLoad(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"bad","vert":"top","horiz":"left"},{"name":"good","vert":"top","horiz":"right"}]);`).
+		Return(NewValue(`{"bad":"","good":""}`, nil))
+	win.On("Eval", "loadModuleHTML(`bad`, `<div class=\"module-error\">bad has stopped working</div>`);").Once().Return(emptyVal)
+	ui := &UI{win: win}
+
+	bad := module.Descriptor{
+		Name:     "bad",
+		Path:     "bad-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+	}
+	good := module.Descriptor{
+		Name:     "good",
+		Path:     "good-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, bad, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		panic("test panic")
+	}).Return(nil, nil)
+	svc.On("Run", mock.Anything, good, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{bad, good}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, ModuleFailed, rt.ModuleStatus("bad"))
+	assert.Equal(t, ModuleRunning, rt.ModuleStatus("good"))
+	state := rt.RuntimeState()
+	assert.Equal(t, "panic: test panic", state[0].LastError)
+	win.AssertExpectations(t)
+	svc.AssertExpectations(t)
+}
+
+func TestRuntime_IsolatesBindingPanic(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	rt := NewRuntime(Config{}, uiCtx.ui, &MockModuleRunner{}, newTestLogger())
+	rt.states = []*moduleState{{desc: module.Descriptor{Name: "test"}, ui: uiCtx, running: true}}
+	rt.ui.OnPanic(rt.modulePanic)
+
+	err := uiCtx.Bind("toggle", func(on bool) (string, error) {
+		panic("test panic")
+	})
+	require.NoError(t, err)
+	fn, ok := win.Binding("toggle")
+	require.True(t, ok)
+
+	got, err := fn.(func(bool) (string, error))(true)
+
+	assert.Empty(t, got)
+	assert.EqualError(t, err, "test: panic: test panic")
+	assert.Equal(t, ModuleFailed, rt.ModuleStatus("test"))
+	assert.Equal(t, `<div class="module-error">test has stopped working</div>`, win.HTML("test"))
+}
+
+func TestRuntime_IsolatesRefreshPanic(t *testing.T) {
+	rt := NewRuntime(Config{}, &UI{}, &MockModuleRunner{}, newTestLogger())
+	rt.states = []*moduleState{{desc: module.Descriptor{Name: "test"}, running: true}}
+
+	mod := &MockRefreshModule{}
+	mod.On("Refresh", mock.Anything).Run(func(mock.Arguments) {
+		panic("test panic")
+	}).Return(nil)
+
+	err := rt.refreshFunc("test", mod)(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, ModuleFailed, rt.ModuleStatus("test"))
+}
+
+func TestRuntime_QuarantinesRefreshOverrun(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	rt := NewRuntime(Config{}, uiCtx.ui, &MockModuleRunner{}, newTestLogger())
+	rt.states = []*moduleState{{desc: module.Descriptor{Name: "test"}, ui: uiCtx, running: true}}
+
+	rt.refreshOverrun(Overrun{Module: "test", Took: 11 * time.Second, Budget: time.Second, Overruns: 1, Quarantined: true})
+
+	assert.Equal(t, ModuleFailed, rt.ModuleStatus("test"))
+	state := rt.RuntimeState()
+	assert.Equal(t, "refresh took 11s, over 10 times its budget of 1s", state[0].LastError)
+	assert.Equal(t, `<div class="module-error">test has stopped working</div>`, win.HTML("test"))
+}
+
+func TestRuntime_ModuleStatus(t *testing.T) {
+	rt := NewRuntime(Config{}, &UI{}, &MockModuleRunner{}, newTestLogger())
+	rt.states = []*moduleState{
+		{desc: module.Descriptor{Name: "pending"}},
+		{desc: module.Descriptor{Name: "running"}, running: true},
+		{desc: module.Descriptor{Name: "skipped"}, skipped: true},
+		{desc: module.Descriptor{Name: "errored"}, err: errors.New("test")},
+	}
+
+	assert.Equal(t, ModulePending, rt.ModuleStatus("pending"))
+	assert.Equal(t, ModuleRunning, rt.ModuleStatus("running"))
+	assert.Equal(t, ModuleSkipped, rt.ModuleStatus("skipped"))
+	assert.Equal(t, ModuleFailed, rt.ModuleStatus("errored"))
+	assert.Equal(t, ModuleStatus(""), rt.ModuleStatus("unknown"))
+}
@@ -0,0 +1,42 @@
+package glass
+
+import (
+	"errors"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// errKeepAwakeUnsupported is returned when the platform has no keep awake mechanism.
+var errKeepAwakeUnsupported = errors.New("keep awake is not supported on this platform")
+
+// KeepAwaker prevents the display from sleeping.
+type KeepAwaker interface {
+	// Start prevents the display from sleeping.
+	Start() error
+	// Stop restores the display sleep behaviour.
+	Stop() error
+}
+
+// startKeepAwake starts the keep awake mechanism if configured.
+func (r *Runtime) startKeepAwake() {
+	if r.awake == nil {
+		return
+	}
+
+	if err := r.awake.Start(); err != nil {
+		r.log.Warn("could not prevent display sleep", logCtx.Error("error", err))
+		r.awake = nil
+	}
+}
+
+// stopKeepAwake stops the keep awake mechanism if it was started.
+func (r *Runtime) stopKeepAwake() {
+	if r.awake == nil {
+		return
+	}
+
+	if err := r.awake.Stop(); err != nil {
+		r.log.Warn("could not restore display sleep", logCtx.Error("error", err))
+	}
+	r.awake = nil
+}
@@ -0,0 +1,32 @@
+package glass
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// caffeinateKeepAwake prevents the display from sleeping using caffeinate.
+type caffeinateKeepAwake struct {
+	cmd *exec.Cmd
+}
+
+func newKeepAwaker() KeepAwaker {
+	return &caffeinateKeepAwake{}
+}
+
+func (k *caffeinateKeepAwake) Start() error {
+	k.cmd = exec.Command("caffeinate", "-d")
+	if err := k.cmd.Start(); err != nil {
+		return fmt.Errorf("caffeinate: %w", err)
+	}
+	return nil
+}
+
+func (k *caffeinateKeepAwake) Stop() error {
+	if k.cmd == nil || k.cmd.Process == nil {
+		return nil
+	}
+	_ = k.cmd.Process.Kill()
+	_ = k.cmd.Wait()
+	return nil
+}
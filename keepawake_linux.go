@@ -0,0 +1,60 @@
+package glass
+
+import (
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const xsetResetInterval = time.Minute
+
+// xsetKeepAwake disables the screen saver and DPMS using xset,
+// periodically resetting the screen saver.
+type xsetKeepAwake struct {
+	once sync.Once
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newKeepAwaker() KeepAwaker {
+	return &xsetKeepAwake{done: make(chan struct{})}
+}
+
+func (k *xsetKeepAwake) Start() error {
+	if err := xset("s", "off", "-dpms"); err != nil {
+		return err
+	}
+
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+
+		ticker := time.NewTicker(xsetResetInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-k.done:
+				return
+			case <-ticker.C:
+				_ = xset("s", "reset")
+			}
+		}
+	}()
+	return nil
+}
+
+func (k *xsetKeepAwake) Stop() error {
+	k.once.Do(func() { close(k.done) })
+	k.wg.Wait()
+
+	return xset("s", "on", "+dpms")
+}
+
+func xset(args ...string) error {
+	if out, err := exec.Command("xset", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("xset: %w: %s", err, out)
+	}
+	return nil
+}
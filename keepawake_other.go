@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package glass
+
+// unsupportedKeepAwake is used on platforms without a keep awake mechanism.
+type unsupportedKeepAwake struct{}
+
+func newKeepAwaker() KeepAwaker {
+	return unsupportedKeepAwake{}
+}
+
+func (unsupportedKeepAwake) Start() error { return errKeepAwakeUnsupported }
+
+func (unsupportedKeepAwake) Stop() error { return nil }
@@ -0,0 +1,226 @@
+package glass
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// Screen orientations.
+const (
+	Landscape = "landscape"
+	Portrait  = "portrait"
+)
+
+// LayoutConfig contains configuration for a grid layout with
+// a template per screen orientation.
+//
+// Modules are placed into the grid using their area.
+type LayoutConfig struct {
+	Landscape GridTemplate `yaml:"landscape"`
+	Portrait  GridTemplate `yaml:"portrait"`
+}
+
+// GridTemplate is a css grid template with named areas.
+type GridTemplate struct {
+	// Areas are the rows of named areas, e.g. "clock weather".
+	// A "." marks an empty cell.
+	Areas   []string `yaml:"areas"`
+	Columns string   `yaml:"columns"`
+	Rows    string   `yaml:"rows"`
+}
+
+var areaNameRegex = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9\-_]*|\.)$`)
+
+// Validate validates the layout configuration.
+func (c LayoutConfig) Validate() error {
+	if err := c.Landscape.validate(); err != nil {
+		return fmt.Errorf("config: invalid landscape layout: %w", err)
+	}
+	if err := c.Portrait.validate(); err != nil {
+		return fmt.Errorf("config: invalid portrait layout: %w", err)
+	}
+	return nil
+}
+
+func (t GridTemplate) validate() error {
+	type bounds struct{ top, left, bottom, right, cells int }
+
+	var names []string
+	areas := map[string]*bounds{}
+	cols := -1
+	for r, row := range t.Areas {
+		cells := strings.Fields(row)
+		if len(cells) == 0 {
+			return errors.New("area rows cannot be empty")
+		}
+		if cols >= 0 && len(cells) != cols {
+			return fmt.Errorf("area row %q has %d columns, expected %d", row, len(cells), cols)
+		}
+		cols = len(cells)
+
+		for c, cell := range cells {
+			if !areaNameRegex.MatchString(cell) {
+				return fmt.Errorf("invalid area name %q", cell)
+			}
+			if cell == "." {
+				continue
+			}
+
+			b, ok := areas[cell]
+			if !ok {
+				b = &bounds{top: r, left: c, bottom: r, right: c}
+				areas[cell] = b
+				names = append(names, cell)
+			}
+			if c < b.left {
+				b.left = c
+			}
+			if c > b.right {
+				b.right = c
+			}
+			b.bottom = r
+			b.cells++
+		}
+	}
+
+	// An area filling every cell of the rectangle spanning its cells is a
+	// rectangle, which is the only shape css grids allow.
+	for _, name := range names {
+		b := areas[name]
+		if b.cells != (b.bottom-b.top+1)*(b.right-b.left+1) {
+			return fmt.Errorf("area %q is not a rectangle", name)
+		}
+	}
+	return nil
+}
+
+// Enabled determines if a grid layout has been configured.
+func (c LayoutConfig) Enabled() bool {
+	return len(c.Landscape.Areas) > 0 || len(c.Portrait.Areas) > 0
+}
+
+// Template returns the grid template for the orientation.
+//
+// If no template is configured for the orientation, the
+// template of the other orientation is used.
+func (c LayoutConfig) Template(orientation string) GridTemplate {
+	land, port := c.Landscape, c.Portrait
+	if len(land.Areas) == 0 {
+		land = port
+	}
+	if len(port.Areas) == 0 {
+		port = land
+	}
+
+	if orientation == Portrait {
+		return port
+	}
+	return land
+}
+
+// HasArea determines if the area is defined in any template.
+func (c LayoutConfig) HasArea(area string) bool {
+	return c.Landscape.hasArea(area) || c.Portrait.hasArea(area)
+}
+
+func (t GridTemplate) hasArea(area string) bool {
+	for _, row := range t.Areas {
+		for _, cell := range strings.Fields(row) {
+			if cell == area {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gridJS returns the javascript applying the template and placing
+// the modules into their areas. Modules whose area is not in the
+// template are hidden.
+func gridJS(t GridTemplate, areas map[string]string) string {
+	mods := make(map[string]string, len(areas))
+	for name, area := range areas {
+		if !t.hasArea(area) {
+			area = ""
+		}
+		mods[name] = area
+	}
+
+	b, _ := json.Marshal(struct {
+		Areas   []string          `json:"areas"`
+		Columns string            `json:"columns"`
+		Rows    string            `json:"rows"`
+		Modules map[string]string `json:"modules"`
+	}{
+		Areas:   t.Areas,
+		Columns: t.Columns,
+		Rows:    t.Rows,
+		Modules: mods,
+	})
+	return "applyGrid(" + string(b) + ");"
+}
+
+// SetOrientation applies the grid template for the orientation,
+// re-placing modules into their areas.
+func (r *Runtime) SetOrientation(orientation string) error {
+	r.mu.Lock()
+	layout := r.cfg.Layout
+	r.mu.Unlock()
+
+	if !layout.Enabled() {
+		return nil
+	}
+	if orientation != Landscape && orientation != Portrait {
+		return fmt.Errorf("unknown orientation %q", orientation)
+	}
+
+	areas := map[string]string{}
+	r.mu.Lock()
+	for _, state := range r.states {
+		if state.ui == nil || state.desc.Area == "" {
+			continue
+		}
+		areas[state.ui.name] = state.desc.Area
+	}
+	r.mu.Unlock()
+
+	js := gridJS(layout.Template(orientation), areas)
+	if _, err := r.ui.Eval(js); err != nil {
+		return fmt.Errorf("could not apply %s layout: %w", orientation, err)
+	}
+	r.ui.mu.Lock()
+	r.ui.layout = js
+	r.ui.mu.Unlock()
+
+	r.log.Info("layout applied", logCtx.Str("orientation", orientation))
+	return nil
+}
+
+// applyCurrentLayout applies the layout for the current orientation.
+func (r *Runtime) applyCurrentLayout() error {
+	res, err := r.ui.Eval("currentOrientation();")
+	if err != nil {
+		return fmt.Errorf("could not determine orientation: %w", err)
+	}
+	orientation, _ := res.(string)
+	return r.SetOrientation(orientation)
+}
+
+// applyLayout applies the layout for the current orientation and
+// follows orientation changes.
+func (r *Runtime) applyLayout() error {
+	if err := r.applyCurrentLayout(); err != nil {
+		return err
+	}
+
+	return r.ui.Bind("orientationChanged", func(orientation string) {
+		if err := r.SetOrientation(orientation); err != nil {
+			r.log.Error("could not change orientation", logCtx.Error("error", err))
+		}
+	})
+}
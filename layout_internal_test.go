@@ -0,0 +1,110 @@
+package glass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     LayoutConfig
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "valid layout",
+			cfg: LayoutConfig{
+				Landscape: GridTemplate{Areas: []string{"clock weather", "news news"}},
+				Portrait:  GridTemplate{Areas: []string{"clock", "weather", "."}},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "handles uneven rows",
+			cfg: LayoutConfig{
+				Landscape: GridTemplate{Areas: []string{"clock weather", "news"}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "handles invalid area name",
+			cfg: LayoutConfig{
+				Portrait: GridTemplate{Areas: []string{"clock \"weather"}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "handles non-rectangular area",
+			cfg: LayoutConfig{
+				Landscape: GridTemplate{Areas: []string{"news news", "news clock"}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "handles split area",
+			cfg: LayoutConfig{
+				Landscape: GridTemplate{Areas: []string{"news clock news"}},
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+
+			test.wantErr(t, err)
+		})
+	}
+}
+
+func TestRuntime_SetOrientation(t *testing.T) {
+	landscapeJS := `applyGrid({"areas":["clock weather"],"columns":"1fr 1fr","rows":"","modules":{"clock":"clock","weather":"weather"}});`
+	portraitJS := `applyGrid({"areas":["clock","."],"columns":"","rows":"1fr 2fr","modules":{"clock":"clock","weather":""}});`
+
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"","horiz":""},{"name":"weather","vert":"","horiz":""}]);`).
+		Return(NewValue(`{"clock":"","weather":""}`, nil))
+	win.On("Eval", "currentOrientation();").Return(NewValue(`"landscape"`, nil))
+	win.On("Eval", landscapeJS).Once().Return(NewValue("", nil))
+	win.On("Eval", portraitJS).Once().Return(NewValue("", nil))
+	win.On("Bind", "orientationChanged", mock.Anything).Return(nil)
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{Name: "clock", Path: "clock", Area: "clock"}
+	weather := module.Descriptor{Name: "weather", Path: "weather", Area: "weather"}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	cfg := Config{
+		Layout: LayoutConfig{
+			Landscape: GridTemplate{Areas: []string{"clock weather"}, Columns: "1fr 1fr"},
+			Portrait:  GridTemplate{Areas: []string{"clock", "."}, Rows: "1fr 2fr"},
+		},
+		Modules: []module.Descriptor{clock, weather},
+	}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	err = rt.SetOrientation(Portrait)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+	assert.Equal(t, portraitJS, ui.layout)
+}
+
+func TestLayoutConfig_TemplateFallsBackToOtherOrientation(t *testing.T) {
+	cfg := LayoutConfig{
+		Landscape: GridTemplate{Areas: []string{"clock weather"}},
+	}
+
+	assert.Equal(t, cfg.Landscape, cfg.Template(Portrait))
+}
@@ -0,0 +1,220 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/glasslabs/looking-glass/module"
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// LoaderConfig contains configuration for the module loader.
+type LoaderConfig struct {
+	// Concurrency is the maximum number of modules initialised at the same time.
+	// Zero means GOMAXPROCS.
+	Concurrency int `yaml:"concurrency"`
+
+	// StrictPositions fails loading when modules share the same position,
+	// rather than warning about it.
+	StrictPositions bool `yaml:"strictPositions"`
+}
+
+// Validate validates the load configuration.
+func (c LoaderConfig) Validate() error {
+	if c.Concurrency < 0 {
+		return errors.New("config: loader concurrency cannot be negative")
+	}
+	return nil
+}
+
+// limit returns the number of modules that can be initialised at the same time.
+func (c LoaderConfig) limit() int {
+	if c.Concurrency > 0 {
+		return c.Concurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// ModuleErrors contains the errors of the modules that failed to load.
+type ModuleErrors []error
+
+// Error returns the error message.
+func (e ModuleErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// moduleErrors returns the non-nil errors as a single error, or nil.
+func moduleErrors(errs []error) error {
+	var merrs ModuleErrors
+	for _, err := range errs {
+		if err != nil {
+			merrs = append(merrs, err)
+		}
+	}
+	switch len(merrs) {
+	case 0:
+		return nil
+	case 1:
+		return merrs[0]
+	default:
+		return merrs
+	}
+}
+
+// runAll runs the given modules using a bounded pool of workers, returning
+// the errors of the modules that failed in the order of the modules.
+//
+// A module is only run once the modules it depends on in states have
+// finished initialising, whether or not they succeeded.
+func (r *Runtime) runAll(ctx context.Context, states []*moduleState, fadeIn bool) []error {
+	r.mu.Lock()
+	limit := r.cfg.Loader.limit()
+	r.mu.Unlock()
+
+	done := make(map[string]chan struct{}, len(states))
+	for _, state := range states {
+		done[state.desc.InstanceID()] = make(chan struct{})
+	}
+
+	errs := make([]error, len(states))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, state := range states {
+		wg.Add(1)
+
+		go func(i int, state *moduleState) {
+			defer wg.Done()
+			defer close(done[state.desc.InstanceID()])
+
+			// The worker is only taken once the dependencies are done,
+			// so waiting modules do not hold up the rest of the modules.
+			r.waitDependencies(state, done)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if fadeIn {
+				if err := state.ui.fade(1); err != nil {
+					r.log.Error("could not fade in module", logCtx.Str("module", state.desc.InstanceID()), logCtx.Error("error", err))
+				}
+			}
+			if err := r.run(ctx, state); err != nil {
+				r.fail(state, err)
+				errs[i] = err
+			}
+		}(i, state)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// waitDependencies waits for the dependencies of the module that are
+// being initialised to finish. Dependencies that are not being initialised,
+// because they are already running, disabled or skipped, are not waited on.
+func (r *Runtime) waitDependencies(state *moduleState, done map[string]chan struct{}) {
+	r.mu.Lock()
+	mods := r.cfg.Modules
+	r.mu.Unlock()
+
+	for _, name := range state.desc.DependsOn {
+		dep, err := resolveDependency(mods, name)
+		if err != nil {
+			continue
+		}
+		ch, ok := done[dep]
+		if !ok {
+			continue
+		}
+		<-ch
+
+		if r.ModuleStatus(dep) == ModuleFailed {
+			r.log.Warn("module dependency failed",
+				logCtx.Str("module", state.desc.InstanceID()),
+				logCtx.Str("dependency", dep),
+			)
+		}
+	}
+}
+
+// resolveDependency returns the instance id of the module a dependency
+// names. A dependency names a module by its instance id, or by its name
+// if no other module has the same name.
+func resolveDependency(mods []module.Descriptor, dep string) (string, error) {
+	var ids []string
+	for _, mod := range mods {
+		if mod.InstanceID() == dep {
+			return dep, nil
+		}
+		if mod.Name == dep {
+			ids = append(ids, mod.InstanceID())
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("dependency %q is not a configured module", dep)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("dependency %q is ambiguous, it could be any of %s", dep, strings.Join(ids, ", "))
+	}
+}
+
+// checkDependencies checks the module dependencies resolve to configured
+// modules, and that the dependencies do not form a cycle.
+func checkDependencies(mods []module.Descriptor) []error {
+	var errs []error
+	deps := make(map[string][]string, len(mods))
+	for _, mod := range mods {
+		ids := make([]string, 0, len(mod.DependsOn))
+		for _, name := range mod.DependsOn {
+			id, err := resolveDependency(mods, name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", mod.InstanceID(), err))
+				continue
+			}
+			ids = append(ids, id)
+		}
+		deps[mod.InstanceID()] = ids
+	}
+
+	visited := make(map[string]bool, len(mods))
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		for i, n := range path {
+			if n == name {
+				return append(append([]string(nil), path[i:]...), name)
+			}
+		}
+		if visited[name] {
+			return nil
+		}
+
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		visited[name] = true
+		return nil
+	}
+	for _, mod := range mods {
+		if cycle := visit(mod.InstanceID()); cycle != nil {
+			// Only the first cycle is reported, as the rest of the
+			// modules may not have been visited.
+			return append(errs, fmt.Errorf("config: module dependency cycle: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+	return errs
+}
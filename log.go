@@ -0,0 +1,64 @@
+package glass
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hamba/logger/v2"
+)
+
+// LogConfig contains configuration for logging.
+type LogConfig struct {
+	// Level is the minimum log level. One of "debug", "info", "warn", "error" or "crit".
+	// Defaults to "info".
+	Level string `yaml:"level"`
+	// Format is the log format. One of "logfmt", "text", "json" or "console".
+	// Defaults to "logfmt".
+	Format string `yaml:"format"`
+}
+
+// Validate validates the log configuration.
+func (c LogConfig) Validate() error {
+	if _, err := logLevel(c.Level); err != nil {
+		return err
+	}
+	if _, err := logFormatter(c.Format); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewLogger returns a logger writing to w configured by cfg.
+func NewLogger(w io.Writer, cfg LogConfig) (*logger.Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	lvl, _ := logLevel(cfg.Level)
+	fmtr, _ := logFormatter(cfg.Format)
+	return logger.New(w, fmtr, lvl), nil
+}
+
+func logLevel(level string) (logger.Level, error) {
+	if level == "" {
+		return logger.Info, nil
+	}
+	lvl, err := logger.LevelFromString(level)
+	if err != nil {
+		return 0, fmt.Errorf("config: unknown log level %q", level)
+	}
+	return lvl, nil
+}
+
+func logFormatter(format string) (logger.Formatter, error) {
+	switch format {
+	case "", "logfmt", "text":
+		return logger.LogfmtFormat(), nil
+	case "json":
+		return logger.JSONFormat(), nil
+	case "console":
+		return logger.ConsoleFormat(), nil
+	default:
+		return nil, fmt.Errorf("config: unknown log format %q", format)
+	}
+}
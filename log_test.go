@@ -0,0 +1,78 @@
+package glass_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	glass "github.com/glasslabs/looking-glass"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, err := glass.NewLogger(&buf, glass.LogConfig{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	log.Info("test message")
+
+	var got map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &got)
+	require.NoError(t, err)
+	assert.Equal(t, "test message", got["msg"])
+	assert.Equal(t, "info", got["lvl"])
+}
+
+func TestNewLogger_FiltersLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, err := glass.NewLogger(&buf, glass.LogConfig{Level: "warn", Format: "text"})
+	require.NoError(t, err)
+
+	log.Info("info message")
+	log.Warn("warn message")
+
+	assert.NotContains(t, buf.String(), "info message")
+	assert.Contains(t, buf.String(), "warn message")
+}
+
+func TestLogConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     glass.LogConfig
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			cfg:  glass.LogConfig{Level: "debug", Format: "console"},
+		},
+		{
+			name: "empty config",
+			cfg:  glass.LogConfig{},
+		},
+		{
+			name:    "handles invalid level",
+			cfg:     glass.LogConfig{Level: "loud"},
+			wantErr: `config: unknown log level "loud"`,
+		},
+		{
+			name:    "handles invalid format",
+			cfg:     glass.LogConfig{Format: "xml"},
+			wantErr: `config: unknown log format "xml"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
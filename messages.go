@@ -0,0 +1,73 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+)
+
+// Publish publishes the json encoded payload on the topic, delivering it
+// to the modules subscribed to the topic.
+func (u *UIContext) Publish(topic string, payload interface{}) error {
+	if u.bus == nil {
+		return fmt.Errorf("%s: messaging is not supported", u.name)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: could not encode %q payload: %w", u.name, topic, err)
+	}
+	u.bus.Publish(Event{Topic: topic, Data: b})
+	return nil
+}
+
+// Subscribe registers a handler called with the payload of every message
+// published on the topic.
+//
+// Handlers are called on a dedicated goroutine per module, in order, so
+// slow or panicking handlers do not affect the publisher.
+func (u *UIContext) Subscribe(topic string, handler func(json.RawMessage)) error {
+	if u.bus == nil {
+		return fmt.Errorf("%s: messaging is not supported", u.name)
+	}
+
+	u.mu.Lock()
+	if u.msgs == nil {
+		u.msgs = newEventDispatcher()
+		u.topics = map[string]func(){}
+	}
+	d := u.msgs
+	_, subscribed := u.topics[topic]
+	if !subscribed {
+		u.topics[topic] = u.bus.Subscribe(topic, func(e Event) {
+			d.dispatch(topic, e.Data)
+		})
+	}
+	u.mu.Unlock()
+
+	d.on(topic, func(payload json.RawMessage) {
+		defer func() {
+			if v := recover(); v != nil {
+				u.ui.reportPanic(u.name, v, debug.Stack())
+			}
+		}()
+
+		handler(payload)
+	})
+	return nil
+}
+
+// closeMessages removes the subscriptions of the module.
+func (u *UIContext) closeMessages() {
+	u.mu.Lock()
+	d, topics := u.msgs, u.topics
+	u.msgs, u.topics = nil, nil
+	u.mu.Unlock()
+
+	for _, unsub := range topics {
+		unsub()
+	}
+	if d != nil {
+		d.close()
+	}
+}
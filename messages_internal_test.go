@@ -0,0 +1,149 @@
+package glass
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMessagingContexts(t *testing.T) (pub, sub *UIContext) {
+	t.Helper()
+
+	ui := &UI{win: NewRecordingWindow()}
+	bus := NewEventBus()
+	pub, err := NewUIContext(ui, "calendar", module.Position{Vertical: module.Top, Horizontal: module.Left})
+	require.NoError(t, err)
+	sub, err = NewUIContext(ui, "lights", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	pub.bus, sub.bus = bus, bus
+	t.Cleanup(sub.closeMessages)
+	return pub, sub
+}
+
+func TestUIContext_PublishSubscribe(t *testing.T) {
+	pub, sub := newMessagingContexts(t)
+
+	got := make(chan json.RawMessage, 1)
+	err := sub.Subscribe("calendar.upcoming", func(payload json.RawMessage) {
+		got <- payload
+	})
+	require.NoError(t, err)
+
+	err = pub.Publish("calendar.upcoming", map[string]string{"title": "Dinner"})
+
+	require.NoError(t, err)
+	select {
+	case payload := <-got:
+		assert.JSONEq(t, `{"title":"Dinner"}`, string(payload))
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+}
+
+func TestUIContext_PublishDoesNotBlockOnSlowSubscribers(t *testing.T) {
+	pub, sub := newMessagingContexts(t)
+
+	release := make(chan struct{})
+	calls := make(chan string, 2)
+	err := sub.Subscribe("topic", func(payload json.RawMessage) {
+		calls <- string(payload)
+		<-release
+	})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		_ = pub.Publish("topic", 1)
+		_ = pub.Publish("topic", 2)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked")
+	}
+	close(release)
+	assert.Equal(t, "1", <-calls)
+	assert.Equal(t, "2", <-calls)
+}
+
+func TestUIContext_SubscribeIsolatesPanics(t *testing.T) {
+	pub, sub := newMessagingContexts(t)
+
+	panics := make(chan string, 1)
+	sub.ui.OnPanic(func(module string, v interface{}, stack []byte) {
+		panics <- module
+	})
+	err := sub.Subscribe("topic", func(json.RawMessage) {
+		panic("test")
+	})
+	require.NoError(t, err)
+
+	err = pub.Publish("topic", "hello")
+
+	require.NoError(t, err)
+	select {
+	case module := <-panics:
+		assert.Equal(t, "lights", module)
+	case <-time.After(time.Second):
+		t.Fatal("panic not reported")
+	}
+}
+
+func TestUIContext_SubscribeTwiceToTopic(t *testing.T) {
+	pub, sub := newMessagingContexts(t)
+
+	calls := make(chan string, 4)
+	for _, name := range []string{"first", "second"} {
+		name := name
+		err := sub.Subscribe("topic", func(json.RawMessage) {
+			calls <- name
+		})
+		require.NoError(t, err)
+	}
+
+	err := pub.Publish("topic", "hello")
+
+	require.NoError(t, err)
+	assert.Equal(t, "first", <-calls)
+	assert.Equal(t, "second", <-calls)
+	select {
+	case name := <-calls:
+		t.Fatalf("unexpected call to %s handler", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUIContext_CloseRemovesSubscriptions(t *testing.T) {
+	pub, sub := newMessagingContexts(t)
+
+	called := make(chan struct{}, 1)
+	err := sub.Subscribe("topic", func(json.RawMessage) {
+		called <- struct{}{}
+	})
+	require.NoError(t, err)
+
+	err = sub.Close()
+	require.NoError(t, err)
+	err = pub.Publish("topic", "hello")
+
+	require.NoError(t, err)
+	select {
+	case <-called:
+		t.Fatal("handler called after close")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUIContext_PublishWithoutBus(t *testing.T) {
+	uiCtx := &UIContext{ui: &UI{}, name: "test"}
+
+	err := uiCtx.Publish("topic", "hello")
+
+	assert.EqualError(t, err, "test: messaging is not supported")
+}
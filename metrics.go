@@ -0,0 +1,99 @@
+package glass
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Refresh results.
+const (
+	refreshSuccess = "success"
+	refreshFailure = "failure"
+)
+
+// Metrics are the prometheus metrics of the runtime.
+//
+// The metrics are kept in their own registry, rather than the
+// default global registry.
+type Metrics struct {
+	reg *prometheus.Registry
+
+	refreshDuration *prometheus.HistogramVec
+	refreshes       *prometheus.CounterVec
+	overruns        *prometheus.CounterVec
+	evalDuration    *prometheus.HistogramVec
+}
+
+// NewMetrics returns the runtime metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		refreshDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "glass",
+			Name:      "module_refresh_duration_seconds",
+			Help:      "The duration of module refreshes.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"module"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "glass",
+			Name:      "module_refreshes_total",
+			Help:      "The number of module refreshes, by result.",
+		}, []string{"module", "result"}),
+		overruns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "glass",
+			Name:      "module_refresh_overruns_total",
+			Help:      "The number of module refreshes that took longer than their budget.",
+		}, []string{"module"}),
+		evalDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "glass",
+			Name:      "eval_duration_seconds",
+			Help:      "The duration of javascript evaluations.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"module"}),
+	}
+	m.reg.MustRegister(m.refreshDuration, m.refreshes, m.overruns, m.evalDuration)
+	return m
+}
+
+// observeRefresh records a module refresh.
+func (m *Metrics) observeRefresh(module string, d time.Duration, err error) {
+	result := refreshSuccess
+	if err != nil {
+		result = refreshFailure
+	}
+	m.refreshDuration.WithLabelValues(module).Observe(d.Seconds())
+	m.refreshes.WithLabelValues(module, result).Inc()
+}
+
+// observeOverrun records a module refresh over its budget.
+func (m *Metrics) observeOverrun(module string) {
+	m.overruns.WithLabelValues(module).Inc()
+}
+
+// observeEval records a javascript evaluation made by module.
+func (m *Metrics) observeEval(module string, d time.Duration) {
+	m.evalDuration.WithLabelValues(module).Observe(d.Seconds())
+}
+
+// Handler returns an HTTP handler serving the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{})
+}
+
+// UseMetrics records the duration of javascript evaluations in m.
+func (ui *UI) UseMetrics(m *Metrics) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.metrics = m
+}
+
+func (ui *UI) evalMetrics() *Metrics {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	return ui.metrics
+}
@@ -0,0 +1,74 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServer_Metrics(t *testing.T) {
+	desc := module.Descriptor{Name: "test", Path: "test-module"}
+	mod := &MockRefreshModule{}
+	mod.On("Refresh", mock.Anything).Once().Return(errors.New("test error"))
+	mod.On("Refresh", mock.Anything).Once().Return(nil)
+	ui := &UI{win: NewRecordingWindow()}
+	cfg := Config{Server: ServerConfig{Metrics: true}, Modules: []module.Descriptor{desc}}
+	rt := NewRuntime(cfg, ui, &MockModuleRunner{}, newTestLogger())
+	refresh := rt.refreshFunc("test", mod)
+	_ = refresh(context.Background())
+	_ = refresh(context.Background())
+	_, err := ui.Eval("1+1")
+	require.NoError(t, err)
+	_, err = (&UIContext{ui: ui, name: "test"}).Eval("1+1")
+	require.NoError(t, err)
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	body := string(b)
+	assert.Contains(t, body, `glass_module_refreshes_total{module="test",result="failure"} 1`)
+	assert.Contains(t, body, `glass_module_refreshes_total{module="test",result="success"} 1`)
+	assert.Contains(t, body, `glass_module_refresh_duration_seconds_count{module="test"} 2`)
+	assert.Contains(t, body, `glass_eval_duration_seconds_count{module=""} 1`)
+	assert.Contains(t, body, `glass_eval_duration_seconds_count{module="test"} 1`)
+}
+
+func TestNewServer_MetricsDisabled(t *testing.T) {
+	rt := NewRuntime(Config{}, &UI{}, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRuntime_RecordsRefreshPanicAsFailure(t *testing.T) {
+	mod := &MockRefreshModule{}
+	mod.On("Refresh", mock.Anything).Once().Run(func(mock.Arguments) { panic("boom") })
+	rt := NewRuntime(Config{}, &UI{}, &MockModuleRunner{}, newTestLogger())
+
+	err := rt.refreshFunc("test", mod)(context.Background())
+
+	require.Error(t, err)
+	failures, err := rt.metrics.refreshes.GetMetricWithLabelValues("test", refreshFailure)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(failures))
+}
@@ -1,8 +1,13 @@
 package module_test
 
 import (
+	"context"
+	"encoding/json"
 	"io"
+	"net/http"
+	"time"
 
+	"github.com/glasslabs/looking-glass/module/types"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/mod/module"
 )
@@ -35,17 +40,198 @@ func (m *MockUI) LoadHTML(html string) error {
 	return args.Error(0)
 }
 
+func (m *MockUI) AppendHTML(html string) error {
+	args := m.Called(html)
+	return args.Error(0)
+}
+
+func (m *MockUI) SetMaxNodes(n int) {
+	_ = m.Called(n)
+}
+
+func (m *MockUI) RenderTemplate(tmpl string, data interface{}) error {
+	args := m.Called(tmpl, data)
+	return args.Error(0)
+}
+
+func (m *MockUI) RenderTemplateFile(path string, data interface{}) error {
+	args := m.Called(path, data)
+	return args.Error(0)
+}
+
+func (m *MockUI) RenderChart(spec types.ChartSpec) error {
+	args := m.Called(spec)
+	return args.Error(0)
+}
+
+func (m *MockUI) Ticker(items []string, opts types.TickerOptions) error {
+	args := m.Called(items, opts)
+	return args.Error(0)
+}
+
+func (m *MockUI) RefreshInterval() time.Duration {
+	args := m.Called()
+	return args.Get(0).(time.Duration)
+}
+
+func (m *MockUI) Screenshot() ([]byte, error) {
+	args := m.Called()
+	b, _ := args.Get(0).([]byte)
+	return b, args.Error(1)
+}
+
 func (m *MockUI) Bind(name string, fun interface{}) error {
 	args := m.Called(name, fun)
 	return args.Error(0)
 }
 
+func (m *MockUI) Unbind(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockUI) On(event string, handler func(json.RawMessage)) error {
+	args := m.Called(event, handler)
+	return args.Error(0)
+}
+
+func (m *MockUI) SetData(v interface{}) error {
+	args := m.Called(v)
+	return args.Error(0)
+}
+
+func (m *MockUI) SetVisible(visible bool) error {
+	args := m.Called(visible)
+	return args.Error(0)
+}
+
+func (m *MockUI) Visible() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockUI) Emit(event string, payload interface{}) error {
+	args := m.Called(event, payload)
+	return args.Error(0)
+}
+
+func (m *MockUI) Publish(topic string, payload interface{}) error {
+	args := m.Called(topic, payload)
+	return args.Error(0)
+}
+
+func (m *MockUI) Subscribe(topic string, handler func(json.RawMessage)) error {
+	args := m.Called(topic, handler)
+	return args.Error(0)
+}
+
 func (m *MockUI) Eval(cmd string, ctx ...interface{}) (interface{}, error) {
 	params := append([]interface{}{cmd}, ctx...)
 	args := m.Called(params...)
 	return args.Get(0), args.Error(0)
 }
 
+func (m *MockUI) Notify(level, message string, ttl time.Duration) error {
+	args := m.Called(level, message, ttl)
+	return args.Error(0)
+}
+
+func (m *MockUI) Logger() types.Logger {
+	args := m.Called()
+	return args.Get(0).(types.Logger)
+}
+
+func (m *MockUI) Store() types.Store {
+	args := m.Called()
+	return args.Get(0).(types.Store)
+}
+
+func (m *MockUI) Describe(desc types.ModuleDescriptor) error {
+	args := m.Called(desc)
+	return args.Error(0)
+}
+
+func (m *MockUI) Modules() []types.ModuleInfo {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]types.ModuleInfo)
+}
+
+func (m *MockUI) Batch(fn func()) error {
+	args := m.Called(fn)
+	return args.Error(0)
+}
+
+func (m *MockUI) ViewportSize() (int, int, error) {
+	args := m.Called()
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockUI) Fetch(req *http.Request) (*http.Response, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
+func (m *MockUI) HTTPClient() *http.Client {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*http.Client)
+}
+
+func (m *MockUI) EvalAsync(cmd string, a ...interface{}) {
+	params := append([]interface{}{cmd}, a...)
+	m.Called(params...)
+}
+
+func (m *MockUI) EvalContext(ctx context.Context, cmd string, a ...interface{}) (interface{}, error) {
+	params := append([]interface{}{ctx, cmd}, a...)
+	args := m.Called(params...)
+	return args.Get(0), args.Error(1)
+}
+
+func (m *MockUI) EvalInto(out interface{}, cmd string, ctx ...interface{}) error {
+	params := append([]interface{}{out, cmd}, ctx...)
+	args := m.Called(params...)
+	return args.Error(0)
+}
+
+func (m *MockUI) EvalIntoStrict(out interface{}, cmd string, args ...interface{}) error {
+	params := append([]interface{}{out, cmd}, args...)
+	ret := m.Called(params...)
+	return ret.Error(0)
+}
+
+func (m *MockUI) EvalBool(cmd string, args ...interface{}) (bool, error) {
+	params := append([]interface{}{cmd}, args...)
+	ret := m.Called(params...)
+	return ret.Bool(0), ret.Error(1)
+}
+
+func (m *MockUI) EvalString(cmd string, args ...interface{}) (string, error) {
+	params := append([]interface{}{cmd}, args...)
+	ret := m.Called(params...)
+	return ret.String(0), ret.Error(1)
+}
+
+func (m *MockUI) EvalInt(cmd string, args ...interface{}) (int, error) {
+	params := append([]interface{}{cmd}, args...)
+	ret := m.Called(params...)
+	return ret.Int(0), ret.Error(1)
+}
+
+func (m *MockUI) EvalFloat(cmd string, args ...interface{}) (float64, error) {
+	params := append([]interface{}{cmd}, args...)
+	ret := m.Called(params...)
+	return ret.Get(0).(float64), ret.Error(1)
+}
+
 type MockLogger struct {
 	mock.Mock
 }
@@ -12,8 +12,11 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/glasslabs/looking-glass/internal/expr"
 	"github.com/glasslabs/looking-glass/internal/modules"
 	stypes "github.com/glasslabs/looking-glass/module/internal/types"
 	"github.com/glasslabs/looking-glass/module/types"
@@ -39,6 +42,28 @@ const (
 	Left   = "left"
 	Center = "center"
 	Right  = "right"
+	// Full spans the full width of the top or bottom of the page.
+	Full = "full"
+)
+
+// Module position aliases.
+const (
+	// CenterPosition is the dead center of the page.
+	CenterPosition = "center"
+	// TopBar is the full width bar at the top of the page.
+	TopBar = "top-bar"
+	// BottomBar is the full width bar at the bottom of the page.
+	BottomBar = "bottom-bar"
+)
+
+var (
+	positionAliases = map[string]Position{
+		CenterPosition: {Vertical: Middle, Horizontal: Center},
+		TopBar:         {Vertical: Top, Horizontal: Full},
+		BottomBar:      {Vertical: Bottom, Horizontal: Full},
+	}
+	verticalPositions   = []string{Top, Middle, Bottom}
+	horizontalPositions = []string{Left, Center, Right, Full}
 )
 
 // Position is a module position in the grid.
@@ -47,6 +72,29 @@ type Position struct {
 	Horizontal string
 }
 
+// ParsePosition parses a position in the form "vertical:horizontal",
+// or one of the position aliases.
+func ParsePosition(pos string) (Position, error) {
+	if p, ok := positionAliases[pos]; ok {
+		return p, nil
+	}
+
+	parts := strings.Split(pos, ":")
+	if len(parts) != 2 {
+		return Position{}, fmt.Errorf("invalid position: %s (valid: %s, %s, %s or vertical:horizontal)", pos, CenterPosition, TopBar, BottomBar)
+	}
+	if !contains(verticalPositions, parts[0]) {
+		return Position{}, fmt.Errorf("invalid vertical position: %s (valid: %s)", parts[0], strings.Join(verticalPositions, ", "))
+	}
+	if !contains(horizontalPositions, parts[1]) {
+		return Position{}, fmt.Errorf("invalid horizontal position: %s (valid: %s)", parts[1], strings.Join(horizontalPositions, ", "))
+	}
+	if parts[0] == Middle && parts[1] == Full {
+		return Position{}, errors.New("invalid position: " + pos + " (full is only valid at the top or bottom)")
+	}
+	return Position{Vertical: parts[0], Horizontal: parts[1]}, nil
+}
+
 // UnmarshalYAML unmarshals a Position from YAML.
 func (p *Position) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var pos string
@@ -54,37 +102,121 @@ func (p *Position) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
-	parts := strings.Split(pos, ":")
-	if len(parts) != 2 {
-		return errors.New("invalid position: " + pos)
-	}
-
-	switch parts[0] {
-	case Top:
-		p.Vertical = Top
-	case Middle:
-		p.Vertical = Middle
-	case Bottom:
-		p.Vertical = Bottom
-	default:
-		return errors.New("invalid vertical position: " + parts[0])
-	}
-
-	switch parts[1] {
-	case Left:
-		p.Horizontal = Left
-	case Center:
-		p.Horizontal = Center
-	case Right:
-		p.Horizontal = Right
-	default:
-		return errors.New("invalid horizontal position: " + parts[1])
+	parsed, err := ParsePosition(pos)
+	if err != nil {
+		return err
 	}
+	*p = parsed
 	return nil
 }
 
+func contains(vals []string, v string) bool {
+	for _, val := range vals {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the position in the form "vertical:horizontal".
+func (p Position) String() string {
+	return p.Vertical + ":" + p.Horizontal
+}
+
 var modNameRegex = regexp.MustCompile(`^[a-zA-Z0-9\-_]+$`)
 
+// GridPlacement is an explicit placement of a module in the columns
+// of its vertical region.
+type GridPlacement struct {
+	// Column is the first column of the module, starting at 1.
+	Column int `yaml:"column"`
+	// Span is the number of columns the module spans. Zero means 1.
+	Span int `yaml:"span"`
+	// Row is the row of the module, starting at 1. Zero means 1.
+	Row int `yaml:"row"`
+}
+
+// ColumnSpan returns the number of columns the module spans.
+func (p GridPlacement) ColumnSpan() int {
+	if p.Span <= 0 {
+		return 1
+	}
+	return p.Span
+}
+
+// GridRow returns the row of the module.
+func (p GridPlacement) GridRow() int {
+	if p.Row <= 0 {
+		return 1
+	}
+	return p.Row
+}
+
+// DefaultRetryDelay is the default delay before retrying a module.
+const DefaultRetryDelay = time.Second
+
+// Retry configures retrying a module that fails to initialise.
+type Retry struct {
+	// Attempts is the maximum number of attempts. Zero means a single attempt.
+	Attempts int `yaml:"attempts"`
+	// Delay is the delay before the first retry, doubling after each retry.
+	// Zero means DefaultRetryDelay.
+	Delay time.Duration `yaml:"delay"`
+}
+
+// MaxAttempts returns the maximum number of attempts.
+func (r Retry) MaxAttempts() int {
+	if r.Attempts <= 0 {
+		return 1
+	}
+	return r.Attempts
+}
+
+// BaseDelay returns the delay before the first retry.
+func (r Retry) BaseDelay() time.Duration {
+	if r.Delay <= 0 {
+		return DefaultRetryDelay
+	}
+	return r.Delay
+}
+
+// Font is a font file used by a module.
+type Font struct {
+	// Family is the css font family the font is registered as.
+	Family string `yaml:"family"`
+	// Src is the url of the font file, or its path relative to the module directory.
+	Src string `yaml:"src"`
+	// Weight is the optional css font weight of the font, e.g. "bold".
+	Weight string `yaml:"weight"`
+	// Style is the optional css font style of the font, e.g. "italic".
+	Style string `yaml:"style"`
+}
+
+// sandboxTokens are the permissions a sandboxed module may be allowed.
+var sandboxTokens = map[string]bool{
+	"allow-downloads":                         true,
+	"allow-forms":                             true,
+	"allow-modals":                            true,
+	"allow-orientation-lock":                  true,
+	"allow-pointer-lock":                      true,
+	"allow-popups":                            true,
+	"allow-popups-to-escape-sandbox":          true,
+	"allow-presentation":                      true,
+	"allow-same-origin":                       true,
+	"allow-scripts":                           true,
+	"allow-top-navigation":                    true,
+	"allow-top-navigation-by-user-activation": true,
+}
+
+// Sandbox configures rendering a module in a sandboxed iframe.
+type Sandbox struct {
+	// Enabled renders the module in a sandboxed iframe.
+	Enabled bool `yaml:"enabled"`
+	// Allow are the permissions of the iframe, e.g. "allow-scripts".
+	Allow []string `yaml:"allow"`
+}
+
 // Descriptor describes the module and its configuration.
 type Descriptor struct {
 	Name     string    `yaml:"name"`
@@ -93,6 +225,75 @@ type Descriptor struct {
 	Package  string    `yaml:"package"`
 	Position Position  `yaml:"position"`
 	Config   yaml.Node `yaml:"config"`
+
+	// ID is the optional id of the module instance, allowing a module
+	// name to be configured more than once.
+	ID string `yaml:"id"`
+
+	// When is an optional expression determining if the module is loaded.
+	When string `yaml:"when"`
+
+	// Area is the optional named grid area the module is placed in.
+	// It takes precedence over the position when a layout is configured.
+	Area string `yaml:"area"`
+
+	// Grid is the optional explicit placement of the module in the columns
+	// of its vertical region. It takes precedence over the horizontal position.
+	Grid *GridPlacement `yaml:"grid"`
+
+	// AllowOverlap allows the module to share its position with other
+	// modules, e.g. when modules intentionally stack.
+	AllowOverlap bool `yaml:"allowOverlap"`
+
+	// ZIndex is the optional stacking order of the module. Modules with a
+	// higher z-index are shown above others, with ties broken by page order.
+	ZIndex int `yaml:"zIndex"`
+
+	// DependsOn are the instance ids of the modules initialised before the
+	// module, e.g. modules it expects events from. A module name may be used
+	// if only one module has the name.
+	DependsOn []string `yaml:"dependsOn"`
+
+	// Enabled determines if the module is loaded. Modules are enabled by default.
+	Enabled *bool `yaml:"enabled"`
+
+	// Refresh is the optional interval the module is refreshed on,
+	// if it implements types.Refresher.
+	Refresh time.Duration `yaml:"refresh"`
+
+	// RefreshBudget is the optional time a refresh of the module should take.
+	// The refresh interval is backed off while refreshes take longer.
+	RefreshBudget time.Duration `yaml:"refreshBudget"`
+
+	// Retry configures retrying the module when it fails to initialise.
+	Retry Retry `yaml:"retry"`
+
+	// Fonts are font files registered for use in the module css.
+	Fonts []Font `yaml:"fonts"`
+
+	// Sandbox optionally isolates the module html, css and scripts in an iframe.
+	Sandbox Sandbox `yaml:"sandbox"`
+
+	// ScopedCSS limits the css loaded by the module to the module element,
+	// so its styles do not apply to other modules.
+	ScopedCSS bool `yaml:"scopedCSS"`
+}
+
+// InstanceID returns the id of the module instance, which is the
+// module id if set, otherwise the module name.
+//
+// The instance id identifies the module element, store, events and
+// bound functions.
+func (d Descriptor) InstanceID() string {
+	if d.ID != "" {
+		return d.ID
+	}
+	return d.Name
+}
+
+// IsEnabled determines if the module is enabled.
+func (d Descriptor) IsEnabled() bool {
+	return d.Enabled == nil || *d.Enabled
 }
 
 // Validate validates a module descriptor.
@@ -103,9 +304,56 @@ func (d Descriptor) Validate() error {
 	if !modNameRegex.Match([]byte(d.Name)) {
 		return fmt.Errorf("%s: module names may only contain letters, numbers, '-' and '_'", d.Name)
 	}
+	if d.ID != "" && !modNameRegex.Match([]byte(d.ID)) {
+		return fmt.Errorf("%s: module ids may only contain letters, numbers, '-' and '_'", d.ID)
+	}
+	// The page uses the prefix for its own elements.
+	if strings.HasPrefix(d.InstanceID(), "glass-") {
+		return fmt.Errorf("%s: module names starting with 'glass-' are reserved", d.InstanceID())
+	}
 
 	if d.Path == "" {
-		return fmt.Errorf("%s: module must have a path", d.Name)
+		return fmt.Errorf("%s: module must have a path", d.InstanceID())
+	}
+
+	if d.Refresh < 0 {
+		return fmt.Errorf("%s: refresh interval cannot be negative", d.InstanceID())
+	}
+	if d.RefreshBudget < 0 {
+		return fmt.Errorf("%s: refresh budget cannot be negative", d.InstanceID())
+	}
+
+	if d.Retry.Attempts < 0 || d.Retry.Delay < 0 {
+		return fmt.Errorf("%s: retry attempts and delay cannot be negative", d.InstanceID())
+	}
+
+	if d.Grid != nil && (d.Grid.Column < 1 || d.Grid.Span < 0 || d.Grid.Row < 0) {
+		return fmt.Errorf("%s: grid column must be at least 1 and span and row cannot be negative", d.InstanceID())
+	}
+
+	for _, font := range d.Fonts {
+		if font.Family == "" || font.Src == "" {
+			return fmt.Errorf("%s: fonts must have a family and src", d.InstanceID())
+		}
+	}
+
+	var scripts, sameOrigin bool
+	for _, tok := range d.Sandbox.Allow {
+		scripts = scripts || tok == "allow-scripts"
+		sameOrigin = sameOrigin || tok == "allow-same-origin"
+		if !sandboxTokens[tok] {
+			return fmt.Errorf("%s: unknown sandbox permission %q", d.InstanceID(), tok)
+		}
+	}
+	if scripts && sameOrigin {
+		// Scripts in a frame with the origin of the page can remove its sandbox.
+		return fmt.Errorf("%s: sandbox cannot allow both allow-scripts and allow-same-origin", d.InstanceID())
+	}
+
+	if d.When != "" {
+		if _, err := expr.Parse(d.When); err != nil {
+			return fmt.Errorf("%s: invalid when expression: %w", d.InstanceID(), err)
+		}
 	}
 
 	return nil
@@ -274,6 +522,11 @@ func (s Service) unzip(r io.Reader, modPath, version, path string) error {
 	return nil
 }
 
+// Dir returns the directory of the module.
+func (s Service) Dir(desc Descriptor) string {
+	return filepath.Join(s.path, srcPath, desc.Path)
+}
+
 var (
 	errorType  = reflect.TypeOf((*error)(nil)).Elem()
 	closerType = reflect.TypeOf((*io.Closer)(nil)).Elem()
@@ -300,7 +553,7 @@ func (s Service) Run(ctx context.Context, desc Descriptor, ui types.UI, log type
 
 	_, err := i.Eval(fmt.Sprintf(`import "%s"`, desc.Path))
 	if err != nil {
-		return nil, fmt.Errorf("%s: could not import module %q: %w", desc.Name, desc.Path, err)
+		return nil, fmt.Errorf("%s: could not import module %q: %w", desc.InstanceID(), desc.Path, err)
 	}
 
 	vCfg, err := i.Eval(pkg + ".NewConfig()")
@@ -308,7 +561,7 @@ func (s Service) Run(ctx context.Context, desc Descriptor, ui types.UI, log type
 		return nil, fmt.Errorf("module: could not run NewConfig: %w", err)
 	}
 	if err = desc.Config.Decode(vCfg.Interface()); err != nil {
-		return nil, fmt.Errorf("%s: could not decode configuration: %w", desc.Name, err)
+		return nil, fmt.Errorf("%s: could not decode configuration: %w", desc.InstanceID(), err)
 	}
 
 	vNew, err := i.Eval(pkg + ".New")
@@ -318,21 +571,82 @@ func (s Service) Run(ctx context.Context, desc Descriptor, ui types.UI, log type
 	if !vNew.IsValid() || vNew.Kind() != reflect.Func ||
 		vNew.Type().NumOut() != 2 ||
 		vNew.Type().Out(0) != closerType || vNew.Type().Out(1) != errorType {
-		return nil, fmt.Errorf("%s: module New must be a func with return '(io.Closer, error)'", desc.Name)
+		return nil, fmt.Errorf("%s: module New must be a func with return '(io.Closer, error)'", desc.InstanceID())
 	}
 	info := types.Info{
-		Name: desc.Name,
-		Path: filepath.Join(s.path, srcPath, desc.Path),
+		Name: desc.InstanceID(),
+		Path: s.Dir(desc),
 		Log:  log,
 	}
 	args := []reflect.Value{reflect.ValueOf(ctx), vCfg, reflect.ValueOf(info), reflect.ValueOf(ui)}
 	res := vNew.Call(args)
 	vMod, vErr := res[0], res[1]
 	if vErr.Interface() != nil {
-		return nil, fmt.Errorf("%s: error loading module: %w", desc.Name, vErr.Interface().(error))
+		return nil, fmt.Errorf("%s: error loading module: %w", desc.InstanceID(), vErr.Interface().(error))
 	}
 	if vMod.Interface() == nil {
-		return nil, fmt.Errorf("%s: nil module returned", desc.Name)
+		return nil, fmt.Errorf("%s: nil module returned", desc.InstanceID())
+	}
+	mod := vMod.Interface().(io.Closer)
+	if refresh := refreshMethod(i, desc.Path, pkg, vMod); refresh != nil {
+		return refresher{Closer: mod, refresh: refresh}, nil
 	}
-	return vMod.Interface().(io.Closer), nil
+	return mod, nil
+}
+
+// refreshMethod returns the Refresh method of the module, if it implements types.Refresher.
+//
+// The interpreter loses the methods of a module once it is returned as an io.Closer,
+// so the module is asserted against the exported types of its package instead.
+func refreshMethod(i *interp.Interpreter, path, pkg string, mod reflect.Value) func(context.Context) error {
+	syms := i.Symbols(path)[path]
+	names := make([]string, 0, len(syms))
+	for name := range syms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if _, err := i.Eval(`import (glassctx "context"; glassio "io")`); err != nil {
+		return nil
+	}
+	var n int
+	for _, name := range names {
+		for _, typ := range []string{"*" + pkg + "." + name, pkg + "." + name} {
+			n++
+			fn := fmt.Sprintf("glassRefresh%d", n)
+			// Types without a Refresh method fail to compile.
+			if _, err := i.Eval(fmt.Sprintf(refreshAssertion, fn, typ)); err != nil {
+				continue
+			}
+			vFn, err := i.Eval(fn)
+			if err != nil {
+				continue
+			}
+			refresh, _ := vFn.Call([]reflect.Value{mod})[0].Interface().(func(context.Context) error)
+			if refresh != nil {
+				return refresh
+			}
+		}
+	}
+	return nil
+}
+
+// refreshAssertion returns the Refresh method of a module of the given type.
+const refreshAssertion = `func %s(m glassio.Closer) func(glassctx.Context) error {
+	if r, ok := m.(%s); ok {
+		return r.Refresh
+	}
+	return nil
+}`
+
+// refresher is a module implementing types.Refresher.
+type refresher struct {
+	io.Closer
+
+	refresh func(context.Context) error
+}
+
+// Refresh refreshes the module.
+func (r refresher) Refresh(ctx context.Context) error {
+	return r.refresh(ctx)
 }
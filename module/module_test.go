@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/glasslabs/looking-glass/module"
+	"github.com/glasslabs/looking-glass/module/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	mod "golang.org/x/mod/module"
@@ -41,17 +42,41 @@ func TestPosition_UnmarshalYAML(t *testing.T) {
 			position: "top:right",
 			want:     module.Position{Vertical: module.Top, Horizontal: module.Right},
 		},
+		{
+			position: "top:full",
+			want:     module.Position{Vertical: module.Top, Horizontal: module.Full},
+		},
+		{
+			position: "center",
+			want:     module.Position{Vertical: module.Middle, Horizontal: module.Center},
+		},
+		{
+			position: "top-bar",
+			want:     module.Position{Vertical: module.Top, Horizontal: module.Full},
+		},
+		{
+			position: "bottom-bar",
+			want:     module.Position{Vertical: module.Bottom, Horizontal: module.Full},
+		},
 		{
 			position: "something:left",
-			wantErr:  "invalid vertical position: something",
+			wantErr:  "invalid vertical position: something (valid: top, middle, bottom)",
 		},
 		{
 			position: "top:something",
-			wantErr:  "invalid horizontal position: something",
+			wantErr:  "invalid horizontal position: something (valid: left, center, right, full)",
 		},
 		{
 			position: "top::left",
-			wantErr:  "invalid position: top::left",
+			wantErr:  "invalid position: top::left (valid: center, top-bar, bottom-bar or vertical:horizontal)",
+		},
+		{
+			position: "sidebar",
+			wantErr:  "invalid position: sidebar (valid: center, top-bar, bottom-bar or vertical:horizontal)",
+		},
+		{
+			position: "middle:full",
+			wantErr:  "invalid position: middle:full (full is only valid at the top or bottom)",
 		},
 	}
 
@@ -102,6 +127,23 @@ func TestDescriptor_Validate(t *testing.T) {
 			},
 			wantErr: "test@modile: module names may only contain letters, numbers, '-' and '_'",
 		},
+		{
+			name: "handles reserved name",
+			desc: module.Descriptor{
+				Name: "glass-background",
+				Path: "test",
+			},
+			wantErr: "glass-background: module names starting with 'glass-' are reserved",
+		},
+		{
+			name: "handles invalid id",
+			desc: module.Descriptor{
+				Name: "test-module",
+				ID:   "test module",
+				Path: "test",
+			},
+			wantErr: "test module: module ids may only contain letters, numbers, '-' and '_'",
+		},
 		{
 			name: "handles no path",
 			desc: module.Descriptor{
@@ -110,6 +152,51 @@ func TestDescriptor_Validate(t *testing.T) {
 			},
 			wantErr: "test-module: module must have a path",
 		},
+		{
+			name: "handles invalid grid column",
+			desc: module.Descriptor{
+				Name: "test-module",
+				Path: "test",
+				Grid: &module.GridPlacement{Column: 0, Span: 2},
+			},
+			wantErr: "test-module: grid column must be at least 1 and span and row cannot be negative",
+		},
+		{
+			name: "handles negative retry attempts",
+			desc: module.Descriptor{
+				Name:  "test-module",
+				Path:  "test",
+				Retry: module.Retry{Attempts: -1},
+			},
+			wantErr: "test-module: retry attempts and delay cannot be negative",
+		},
+		{
+			name: "handles font without src",
+			desc: module.Descriptor{
+				Name:  "test-module",
+				Path:  "test",
+				Fonts: []module.Font{{Family: "Icons"}},
+			},
+			wantErr: "test-module: fonts must have a family and src",
+		},
+		{
+			name: "handles unknown sandbox permission",
+			desc: module.Descriptor{
+				Name:    "test-module",
+				Path:    "test",
+				Sandbox: module.Sandbox{Enabled: true, Allow: []string{"allow-scripts", "allow-everything"}},
+			},
+			wantErr: `test-module: unknown sandbox permission "allow-everything"`,
+		},
+		{
+			name: "handles sandbox escape",
+			desc: module.Descriptor{
+				Name:    "test-module",
+				Path:    "test",
+				Sandbox: module.Sandbox{Enabled: true, Allow: []string{"allow-same-origin", "allow-scripts"}},
+			},
+			wantErr: "test-module: sandbox cannot allow both allow-scripts and allow-same-origin",
+		},
 	}
 
 	for _, test := range tests {
@@ -338,3 +425,37 @@ func TestService_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestService_RunReturnsRefresher(t *testing.T) {
+	desc := module.Descriptor{
+		Name:   "test",
+		Path:   "refresh",
+		Config: yaml.Node{},
+	}
+
+	svc, err := module.NewService("../testdata/mod", &MockClient{})
+	require.NoError(t, err)
+
+	mod, err := svc.Run(context.Background(), desc, &MockUI{}, &MockLogger{})
+
+	require.NoError(t, err)
+	require.Implements(t, (*types.Refresher)(nil), mod)
+	assert.NoError(t, mod.(types.Refresher).Refresh(context.Background()))
+}
+
+func TestService_RunValidModuleIsNotRefresher(t *testing.T) {
+	desc := module.Descriptor{
+		Name:   "test",
+		Path:   "valid",
+		Config: yaml.Node{},
+	}
+
+	svc, err := module.NewService("../testdata/mod", &MockClient{})
+	require.NoError(t, err)
+
+	mod, err := svc.Run(context.Background(), desc, &MockUI{}, &MockLogger{})
+
+	require.NoError(t, err)
+	_, ok := mod.(types.Refresher)
+	assert.False(t, ok)
+}
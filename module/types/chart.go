@@ -0,0 +1,35 @@
+package types
+
+// Chart types.
+const (
+	LineChart = "line"
+	BarChart  = "bar"
+)
+
+// ChartSpec describes a chart to render.
+type ChartSpec struct {
+	// Type is the type of chart. Either LineChart or BarChart.
+	Type string
+
+	// Width is the width of the chart in pixels.
+	Width int
+
+	// Height is the height of the chart in pixels.
+	Height int
+
+	// Series are the data series to plot.
+	Series []Series
+}
+
+// Series is a named series of chart values.
+type Series struct {
+	// Name is the name of the series.
+	Name string
+
+	// Color is the css color of the series.
+	// If empty a default color is used.
+	Color string
+
+	// Values are the values of the series.
+	Values []float64
+}
@@ -0,0 +1,8 @@
+package types
+
+// Notification levels.
+const (
+	NotifyInfo  = "info"
+	NotifyWarn  = "warn"
+	NotifyError = "error"
+)
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultPollTimeout is the default maximum time a poll may take.
+const DefaultPollTimeout = 30 * time.Second
+
+// DecodeFunc decodes polled data.
+type DecodeFunc func(r io.Reader) (interface{}, error)
+
+// PollingSource polls a url on an interval, passing the decoded
+// data to a callback.
+//
+// When polling fails, the source backs off exponentially until
+// polling succeeds again.
+type PollingSource struct {
+	// URL is the url to poll.
+	URL string
+
+	// Interval is the interval between polls.
+	Interval time.Duration
+
+	// Decode decodes the response body.
+	// If nil, the body is decoded as JSON.
+	Decode DecodeFunc
+
+	// OnData is called with the decoded data of each successful poll.
+	OnData func(v interface{})
+
+	// OnError is called with the error of each failed poll.
+	// This should be used to surface errors in the module.
+	OnError func(err error)
+
+	// MaxBackoff is the maximum time between polls while failing.
+	// If zero, ten times the interval is used.
+	MaxBackoff time.Duration
+
+	// Timeout is the maximum time a poll may take.
+	// If zero, DefaultPollTimeout is used.
+	Timeout time.Duration
+
+	// Client is the http client used to poll.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Run polls the url until the context is cancelled.
+func (s *PollingSource) Run(ctx context.Context) error {
+	if s.Interval <= 0 {
+		return errors.New("polling interval must be greater than zero")
+	}
+
+	var failures int
+	for {
+		v, err := s.poll(ctx)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			failures++
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+		default:
+			failures = 0
+			if s.OnData != nil {
+				s.OnData(v)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.delay(failures)):
+		}
+	}
+}
+
+func (s *PollingSource) poll(ctx context.Context) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	decode := s.Decode
+	if decode == nil {
+		decode = decodeJSON
+	}
+	v, err := decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode response: %w", err)
+	}
+	return v, nil
+}
+
+// timeout returns the maximum time a poll may take.
+func (s *PollingSource) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultPollTimeout
+	}
+	return s.Timeout
+}
+
+// delay returns the time to wait before the next poll.
+func (s *PollingSource) delay(failures int) time.Duration {
+	maxBackoff := s.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * s.Interval
+	}
+
+	d := s.Interval
+	for i := 0; i < failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if failures > 0 && d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+func decodeJSON(r io.Reader) (interface{}, error) {
+	var v interface{}
+	err := json.NewDecoder(r).Decode(&v)
+	return v, err
+}
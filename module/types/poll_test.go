@@ -0,0 +1,118 @@
+package types_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollingSource_Run(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{"temp":21}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []interface{}
+	src := &types.PollingSource{
+		URL:      srv.URL,
+		Interval: time.Millisecond,
+		OnData: func(v interface{}) {
+			got = append(got, v)
+			if len(got) == 2 {
+				cancel()
+			}
+		},
+		OnError: func(err error) {
+			t.Errorf("unexpected error: %v", err)
+		},
+	}
+
+	err := src.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	want := map[string]interface{}{"temp": float64(21)}
+	assert.Equal(t, []interface{}{want, want}, got)
+}
+
+func TestPollingSource_RunBacksOffOnErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var times []time.Time
+	src := &types.PollingSource{
+		URL:        srv.URL,
+		Interval:   10 * time.Millisecond,
+		MaxBackoff: 40 * time.Millisecond,
+		OnData: func(interface{}) {
+			t.Error("unexpected data")
+		},
+		OnError: func(err error) {
+			assert.EqualError(t, err, "unexpected status code 500")
+			times = append(times, time.Now())
+			if len(times) == 4 {
+				cancel()
+			}
+		},
+	}
+
+	err := src.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	require.Len(t, times, 4)
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, times[2].Sub(times[1]), 40*time.Millisecond)
+	assert.GreaterOrEqual(t, times[3].Sub(times[2]), 40*time.Millisecond)
+}
+
+func TestPollingSource_RunTimesOutPolls(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got error
+	src := &types.PollingSource{
+		URL:      srv.URL,
+		Interval: time.Hour,
+		Timeout:  10 * time.Millisecond,
+		OnError: func(err error) {
+			got = err
+			cancel()
+		},
+	}
+
+	err := src.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, got, context.DeadlineExceeded)
+}
+
+func TestPollingSource_RunHandlesInvalidInterval(t *testing.T) {
+	src := &types.PollingSource{URL: "http://example.com"}
+
+	err := src.Run(context.Background())
+
+	assert.EqualError(t, err, "polling interval must be greater than zero")
+}
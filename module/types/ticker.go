@@ -0,0 +1,12 @@
+package types
+
+// TickerOptions configures a scrolling ticker.
+type TickerOptions struct {
+	// Speed is the scroll speed in pixels per second.
+	// If zero a default speed is used.
+	Speed float64
+
+	// Gap is the gap between items in pixels.
+	// If zero a default gap is used.
+	Gap int
+}
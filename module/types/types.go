@@ -1,5 +1,29 @@
 package types
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrClosed is returned by UI methods once the ui has been closed.
+var ErrClosed = errors.New("ui closed")
+
+// ErrEmptyResult is returned by UI methods requiring a result when
+// the evaluated command returns nothing.
+var ErrEmptyResult = errors.New("eval result is empty")
+
+// ErrSandboxed is returned by UI eval methods of a module rendered in
+// a sandboxed iframe, as the commands cannot be evaluated in the iframe.
+var ErrSandboxed = errors.New("eval is not supported in sandboxed modules")
+
+// ErrBatching is returned by UI eval methods requiring a result when
+// called in a batch, as batched commands are only evaluated once the
+// batch is applied.
+var ErrBatching = errors.New("eval result is not available in a batch")
+
 // Info provides information about the module.
 type Info struct {
 	// Name is the instance name of the module.
@@ -15,6 +39,28 @@ type Info struct {
 	Log Logger
 }
 
+// ModuleDescriptor describes the capabilities a module provides to other modules.
+type ModuleDescriptor struct {
+	// Name is the name of the module, e.g. "weather".
+	Name string
+
+	// Version is the version of the module.
+	Version string
+
+	// Topics are the message topics the module publishes on.
+	Topics []string
+}
+
+// ModuleInfo describes a loaded module.
+type ModuleInfo struct {
+	// Instance is the instance name of the module.
+	Instance string
+
+	// ModuleDescriptor is the descriptor registered by the module.
+	// It is empty if the module did not describe itself.
+	ModuleDescriptor
+}
+
 // Logger represents a logger.
 type Logger interface {
 	Info(msg string, ctx ...interface{})
@@ -27,8 +73,103 @@ type UI interface {
 	LoadCSS(css string) error
 	// LoadHTML loads html into the element.
 	LoadHTML(html string) error
-	// Bind bind a function to javascript.
+	// RenderTemplate executes the html template with data,
+	// loading the result into the element. Data is escaped
+	// for the context it is used in.
+	RenderTemplate(tmpl string, data interface{}) error
+	// RenderTemplateFile executes the html template in the file
+	// with data, loading the result into the element.
+	RenderTemplateFile(path string, data interface{}) error
+	// AppendHTML appends html to the element.
+	AppendHTML(html string) error
+	// SetMaxNodes sets the maximum number of nodes kept
+	// in the element when appending html.
+	SetMaxNodes(n int)
+	// RenderChart renders a chart into the element.
+	RenderChart(spec ChartSpec) error
+	// Ticker renders a scrolling ticker of items into the element.
+	Ticker(items []string, opts TickerOptions) error
+	// ViewportSize returns the size of the page viewport in css pixels.
+	ViewportSize() (w, h int, err error)
+	// RefreshInterval returns the interval the module is refreshed on.
+	// It is zero when the module is not refreshed.
+	RefreshInterval() time.Duration
+	// SetData attaches a json payload to the element as its data property.
+	SetData(v interface{}) error
+	// SetVisible shows or hides the element, keeping its content.
+	SetVisible(visible bool) error
+	// Visible determines if the element is shown.
+	Visible() bool
+	// Screenshot captures the element as a png image.
+	Screenshot() ([]byte, error)
+	// Bind bind a function to javascript. Arguments and return values are
+	// converted to and from JSON, and a returned error rejects the promise.
+	// The function may return nothing, a value, an error or a value and an error.
 	Bind(name string, fun interface{}) error
+	// Unbind removes a function bound to javascript.
+	Unbind(name string) error
+	// On registers a handler for an event emitted by the element.
+	On(event string, handler func(json.RawMessage)) error
+	// Emit dispatches an event with a json payload on the element.
+	Emit(event string, payload interface{}) error
+	// Publish publishes a json payload on a topic to the modules subscribed to it.
+	Publish(topic string, payload interface{}) error
+	// Subscribe registers a handler for the messages published on a topic.
+	Subscribe(topic string, handler func(json.RawMessage)) error
+	// Describe registers the descriptor of the module for other modules to discover.
+	Describe(desc ModuleDescriptor) error
+	// Modules returns the loaded modules.
+	Modules() []ModuleInfo
+	// Batch coalesces the ui calls made by fn into a single evaluation, applied in order.
+	Batch(fn func()) error
 	// Eval evaluates a command in the ui.
 	Eval(cmd string, ctx ...interface{}) (interface{}, error)
+	// EvalAsync evaluates a command in the ui without waiting for the result.
+	// Errors are logged rather than returned.
+	EvalAsync(cmd string, args ...interface{})
+	// EvalContext evaluates a command in the ui, returning once ctx is done.
+	EvalContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+	// EvalInto evaluates a command in the ui, decoding the result into out.
+	EvalInto(out interface{}, cmd string, ctx ...interface{}) error
+	// EvalIntoStrict evaluates a command in the ui, decoding the result into out.
+	// If the result is empty, ErrEmptyResult is returned. While batching,
+	// the command is not evaluated and ErrBatching is returned.
+	EvalIntoStrict(out interface{}, cmd string, args ...interface{}) error
+	// EvalBool evaluates a command in the ui with a boolean result.
+	EvalBool(cmd string, args ...interface{}) (bool, error)
+	// EvalString evaluates a command in the ui with a string result.
+	EvalString(cmd string, args ...interface{}) (string, error)
+	// EvalInt evaluates a command in the ui with an integer result.
+	EvalInt(cmd string, args ...interface{}) (int, error)
+	// EvalFloat evaluates a command in the ui with a numeric result.
+	EvalFloat(cmd string, args ...interface{}) (float64, error)
+	// Fetch sends an http request using the shared http client, retrying on failure.
+	Fetch(req *http.Request) (*http.Response, error)
+	// HTTPClient returns the shared http client.
+	HTTPClient() *http.Client
+	// Notify shows a transient notification, dismissed after ttl.
+	// The level is one of NotifyInfo, NotifyWarn or NotifyError.
+	Notify(level, message string, ttl time.Duration) error
+	// Store returns the key/value store of the module.
+	Store() Store
+	// Logger returns the logger of the module, tagged with the module name and position.
+	Logger() Logger
+}
+
+// Store is a module key/value store that persists across restarts.
+type Store interface {
+	// Get returns the value of key, and if it was found.
+	Get(key string) (string, bool)
+	// Set sets the value of key.
+	Set(key, value string) error
+	// Delete removes key from the store.
+	Delete(key string) error
+}
+
+// Refresher is implemented by modules that are refreshed
+// on their configured refresh interval.
+//
+// A refresh is skipped while the previous one is still running.
+type Refresher interface {
+	Refresh(ctx context.Context) error
 }
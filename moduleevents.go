@@ -0,0 +1,178 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// eventDispatcher calls the event handlers of a module on a dedicated
+// goroutine, so slow handlers do not block the javascript bridge.
+type eventDispatcher struct {
+	mu       sync.Mutex
+	handlers map[string][]func(json.RawMessage)
+	queue    []func()
+	closed   bool
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+func newEventDispatcher() *eventDispatcher {
+	d := &eventDispatcher{
+		handlers: map[string][]func(json.RawMessage){},
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	go d.run()
+
+	return d
+}
+
+func (d *eventDispatcher) on(event string, fn func(json.RawMessage)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.handlers[event] = append(d.handlers[event], fn)
+}
+
+// dispatch queues the handlers of the event. It never blocks.
+func (d *eventDispatcher) dispatch(event string, payload json.RawMessage) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+	for _, fn := range d.handlers[event] {
+		fn := fn
+		d.queue = append(d.queue, func() { fn(payload) })
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *eventDispatcher) run() {
+	defer close(d.done)
+
+	for range d.wake {
+		for {
+			d.mu.Lock()
+			if len(d.queue) == 0 {
+				d.mu.Unlock()
+				break
+			}
+			fn := d.queue[0]
+			d.queue = d.queue[1:]
+			d.mu.Unlock()
+
+			fn()
+		}
+	}
+}
+
+// close stops the dispatcher once the queued handlers have been called.
+func (d *eventDispatcher) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+	d.closed = true
+	close(d.wake)
+}
+
+// bindModuleEvents binds the function modules emit events to go through.
+func (ui *UI) bindModuleEvents() error {
+	ui.mu.Lock()
+	if ui.eventsBound {
+		ui.mu.Unlock()
+		return nil
+	}
+	ui.eventsBound = true
+	ui.mu.Unlock()
+
+	if err := ui.Bind("moduleEvent", ui.moduleEvent); err != nil {
+		ui.mu.Lock()
+		ui.eventsBound = false
+		ui.mu.Unlock()
+		return fmt.Errorf("could not bind module events: %w", err)
+	}
+	return nil
+}
+
+// moduleEvent is called from javascript when a module element emits an event.
+func (ui *UI) moduleEvent(module, event, payload string) {
+	ui.mu.RLock()
+	d := ui.events[module]
+	ui.mu.RUnlock()
+
+	if d == nil {
+		return
+	}
+	d.dispatch(event, json.RawMessage(payload))
+}
+
+// On registers a handler called when the module element emits the event,
+// e.g. `document.getElementById("clock").emit("tap", {x: 1})`.
+//
+// Handlers are called on a dedicated goroutine per module, in order.
+func (u *UIContext) On(event string, handler func(json.RawMessage)) error {
+	if err := u.ui.bindModuleEvents(); err != nil {
+		return err
+	}
+
+	u.ui.mu.Lock()
+	if u.ui.events == nil {
+		u.ui.events = map[string]*eventDispatcher{}
+	}
+	d, ok := u.ui.events[u.name]
+	if !ok {
+		d = newEventDispatcher()
+		u.ui.events[u.name] = d
+	}
+	u.ui.mu.Unlock()
+
+	d.on(event, func(payload json.RawMessage) {
+		defer func() {
+			if v := recover(); v != nil {
+				u.ui.reportPanic(u.name, v, debug.Stack())
+			}
+		}()
+
+		handler(payload)
+	})
+	return nil
+}
+
+// Emit dispatches the event with the json encoded payload on the module
+// element. Javascript receives the payload as the event detail.
+func (u *UIContext) Emit(event string, payload interface{}) error {
+	name, _ := json.Marshal(u.name)
+	evt, _ := json.Marshal(event)
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: could not encode %q payload: %w", u.name, event, err)
+	}
+
+	_, err = u.eval(fmt.Sprintf("dispatchModuleEvent(%s, %s, %s);", name, evt, b))
+	return err
+}
+
+// closeEvents stops the event handling of the module.
+func (u *UIContext) closeEvents() {
+	u.ui.mu.Lock()
+	d := u.ui.events[u.name]
+	delete(u.ui.events, u.name)
+	u.ui.mu.Unlock()
+
+	if d != nil {
+		d.close()
+	}
+}
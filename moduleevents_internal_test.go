@@ -0,0 +1,89 @@
+package glass
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIContext_On(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	t.Cleanup(uiCtx.closeEvents)
+
+	got := make(chan json.RawMessage, 1)
+	err := uiCtx.On("tap", func(payload json.RawMessage) {
+		got <- payload
+	})
+	require.NoError(t, err)
+	fn, ok := win.Binding("moduleEvent")
+	require.True(t, ok)
+
+	fn.(func(string, string, string))("test", "tap", `{"x":1}`)
+
+	select {
+	case payload := <-got:
+		assert.JSONEq(t, `{"x":1}`, string(payload))
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+}
+
+func TestUIContext_OnDoesNotBlockOnSlowHandlers(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	release := make(chan struct{})
+	calls := make(chan string, 2)
+	err := uiCtx.On("tap", func(payload json.RawMessage) {
+		calls <- string(payload)
+		<-release
+	})
+	require.NoError(t, err)
+	fn, _ := win.Binding("moduleEvent")
+	emit := fn.(func(string, string, string))
+
+	done := make(chan struct{})
+	go func() {
+		emit("test", "tap", "1")
+		emit("test", "tap", "2")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("event dispatch blocked")
+	}
+	close(release)
+	assert.Equal(t, "1", <-calls)
+	assert.Equal(t, "2", <-calls)
+	uiCtx.closeEvents()
+}
+
+func TestUIContext_OnIgnoresOtherEvents(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	called := make(chan struct{}, 1)
+	err := uiCtx.On("tap", func(json.RawMessage) {
+		called <- struct{}{}
+	})
+	require.NoError(t, err)
+	fn, _ := win.Binding("moduleEvent")
+
+	fn.(func(string, string, string))("test", "swipe", "null")
+	fn.(func(string, string, string))("other", "tap", "null")
+	uiCtx.closeEvents()
+
+	assert.Empty(t, called)
+}
+
+func TestUIContext_Emit(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Emit("refresh", map[string]int{"x": 1})
+
+	require.NoError(t, err)
+	assert.Contains(t, win.Evals(), `dispatchModuleEvent("test", "refresh", {"x":1});`)
+}
@@ -0,0 +1,225 @@
+package glass
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/hamba/logger/v2"
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// DefaultMQTTTimeout is the default time connecting to the broker,
+// and each publish and subscribe, may take.
+const DefaultMQTTTimeout = 10 * time.Second
+
+// MQTTConfig contains configuration for bridging the event bus to MQTT.
+type MQTTConfig struct {
+	// Broker is the url of the broker, e.g. "tcp://localhost:1883".
+	// The bridge is disabled if empty.
+	Broker   string `yaml:"broker"`
+	ClientID string `yaml:"clientId"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Topics are the event bus topics that are published to the broker.
+	Topics []string `yaml:"topics"`
+	// Timeout is the time connecting, publishing and subscribing may take.
+	// Zero means DefaultMQTTTimeout.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Enabled determines if a broker has been configured.
+func (c MQTTConfig) Enabled() bool {
+	return c.Broker != ""
+}
+
+// Validate validates the mqtt configuration.
+func (c MQTTConfig) Validate() error {
+	if c.Broker == "" {
+		if len(c.Topics) > 0 {
+			return errors.New("config: mqtt broker is required to publish topics")
+		}
+		return nil
+	}
+	u, err := url.Parse(c.Broker)
+	if err != nil {
+		return fmt.Errorf("config: invalid mqtt broker: %w", err)
+	}
+	switch u.Scheme {
+	case "tcp", "ssl", "tls", "ws", "wss":
+	default:
+		return fmt.Errorf("config: mqtt broker %q must use tcp, ssl, tls, ws or wss", c.Broker)
+	}
+	if c.Timeout < 0 {
+		return errors.New("config: mqtt timeout cannot be negative")
+	}
+	return nil
+}
+
+func (c MQTTConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultMQTTTimeout
+	}
+	return c.Timeout
+}
+
+// MQTT topic prefixes.
+const (
+	mqttEventsPrefix  = "glass/events/"
+	mqttPublishPrefix = "glass/publish/"
+)
+
+// mqttOrigin is the origin of events received over MQTT.
+const mqttOrigin = "mqtt"
+
+// MQTTClient is a connected MQTT client.
+type MQTTClient interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(topic string, fn func(topic string, payload []byte)) error
+}
+
+// MQTTBridge bridges the event bus to MQTT.
+//
+// Events on the allowed topics are published to "glass/events/<topic>",
+// and messages received on "glass/publish/<topic>" are published on the
+// event bus. Events received over MQTT are never sent back out.
+type MQTTBridge struct {
+	bus    *EventBus
+	client MQTTClient
+	topics map[string]bool
+	log    *logger.Logger
+
+	unsub func()
+}
+
+// NewMQTTBridge returns a bridge between the event bus and MQTT for the given topics.
+func NewMQTTBridge(bus *EventBus, client MQTTClient, topics []string, log *logger.Logger) (*MQTTBridge, error) {
+	allowed := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		allowed[topic] = true
+	}
+
+	b := &MQTTBridge{
+		bus:    bus,
+		client: client,
+		topics: allowed,
+		log:    log,
+	}
+
+	if err := client.Subscribe(mqttPublishPrefix+"#", b.receive); err != nil {
+		return nil, fmt.Errorf("could not subscribe to mqtt: %w", err)
+	}
+	b.unsub = bus.Subscribe(AllTopics, b.send)
+
+	return b, nil
+}
+
+func (b *MQTTBridge) send(e Event) {
+	if e.Origin == mqttOrigin || !b.topics[e.Topic] {
+		return
+	}
+
+	if err := b.client.Publish(mqttEventsPrefix+e.Topic, e.Data); err != nil {
+		b.log.Error("could not publish mqtt event", logCtx.Str("topic", e.Topic), logCtx.Error("error", err))
+	}
+}
+
+func (b *MQTTBridge) receive(topic string, payload []byte) {
+	if !strings.HasPrefix(topic, mqttPublishPrefix) {
+		return
+	}
+	topic = strings.TrimPrefix(topic, mqttPublishPrefix)
+
+	data := json.RawMessage(payload)
+	if !json.Valid(payload) {
+		data, _ = json.Marshal(string(payload))
+	}
+	b.bus.Publish(Event{Topic: topic, Data: data, Origin: mqttOrigin})
+}
+
+// Close stops sending events to MQTT.
+func (b *MQTTBridge) Close() error {
+	b.unsub()
+	return nil
+}
+
+// MQTTConn is an MQTT client connected to a broker.
+//
+// The connection is restored when it is lost, resubscribing to
+// all subscribed topics.
+type MQTTConn struct {
+	client  mqtt.Client
+	timeout time.Duration
+
+	mu   sync.Mutex
+	subs map[string]mqtt.MessageHandler
+}
+
+// DialMQTT connects to the broker in cfg.
+func DialMQTT(cfg MQTTConfig) (*MQTTConn, error) {
+	c := &MQTTConn{
+		timeout: cfg.timeout(),
+		subs:    map[string]mqtt.MessageHandler{},
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetConnectTimeout(c.timeout).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(c.resubscribe)
+	c.client = mqtt.NewClient(opts)
+
+	if err := waitToken(c.client.Connect(), c.timeout); err != nil {
+		return nil, fmt.Errorf("could not connect to mqtt broker: %w", err)
+	}
+	return c, nil
+}
+
+// Publish publishes the payload on topic.
+func (c *MQTTConn) Publish(topic string, payload []byte) error {
+	return waitToken(c.client.Publish(topic, 0, false, payload), c.timeout)
+}
+
+// Subscribe calls fn with each message received on topic.
+func (c *MQTTConn) Subscribe(topic string, fn func(topic string, payload []byte)) error {
+	h := func(_ mqtt.Client, msg mqtt.Message) {
+		fn(msg.Topic(), msg.Payload())
+	}
+
+	c.mu.Lock()
+	c.subs[topic] = h
+	c.mu.Unlock()
+
+	return waitToken(c.client.Subscribe(topic, 0, h), c.timeout)
+}
+
+func (c *MQTTConn) resubscribe(client mqtt.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for topic, h := range c.subs {
+		client.Subscribe(topic, 0, h)
+	}
+}
+
+// Close disconnects from the broker.
+func (c *MQTTConn) Close() error {
+	c.client.Disconnect(uint(c.timeout / time.Millisecond))
+	return nil
+}
+
+// waitToken waits for the token to complete, returning its error.
+func waitToken(tok mqtt.Token, timeout time.Duration) error {
+	if !tok.WaitTimeout(timeout) {
+		return errors.New("timed out")
+	}
+	return tok.Error()
+}
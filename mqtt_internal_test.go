@@ -0,0 +1,86 @@
+package glass
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publishedMessage struct {
+	Topic   string
+	Payload string
+}
+
+type fakeMQTTClient struct {
+	mu        sync.Mutex
+	published []publishedMessage
+	subs      map[string]func(topic string, payload []byte)
+}
+
+func (c *fakeMQTTClient) Publish(topic string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.published = append(c.published, publishedMessage{Topic: topic, Payload: string(payload)})
+	return nil
+}
+
+func (c *fakeMQTTClient) Subscribe(topic string, fn func(topic string, payload []byte)) error {
+	if c.subs == nil {
+		c.subs = map[string]func(topic string, payload []byte){}
+	}
+	c.subs[topic] = fn
+	return nil
+}
+
+func (c *fakeMQTTClient) Receive(topic, payload string) {
+	c.subs["glass/publish/#"](topic, []byte(payload))
+}
+
+func TestMQTTBridge_PublishesAllowedTopics(t *testing.T) {
+	bus := NewEventBus()
+	client := &fakeMQTTClient{}
+	b, err := NewMQTTBridge(bus, client, []string{"presence"}, newTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+
+	bus.Publish(Event{Topic: "presence", Data: json.RawMessage(`{"home":true}`)})
+	bus.Publish(Event{Topic: "secret", Data: json.RawMessage(`"shh"`)})
+
+	want := []publishedMessage{{Topic: "glass/events/presence", Payload: `{"home":true}`}}
+	assert.Equal(t, want, client.published)
+}
+
+func TestMQTTBridge_RepublishesInbound(t *testing.T) {
+	bus := NewEventBus()
+	client := &fakeMQTTClient{}
+	b, err := NewMQTTBridge(bus, client, nil, newTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+
+	var got []Event
+	bus.Subscribe("theme", func(e Event) {
+		got = append(got, e)
+	})
+
+	client.Receive("glass/publish/theme", "night")
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "theme", got[0].Topic)
+	assert.Equal(t, `"night"`, string(got[0].Data))
+}
+
+func TestMQTTBridge_DoesNotEchoInbound(t *testing.T) {
+	bus := NewEventBus()
+	client := &fakeMQTTClient{}
+	b, err := NewMQTTBridge(bus, client, []string{"presence"}, newTestLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+
+	client.Receive("glass/publish/presence", `{"home":false}`)
+
+	assert.Empty(t, client.published)
+}
@@ -0,0 +1,207 @@
+package glass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// NetworkConfig contains configuration for network connectivity checks.
+type NetworkConfig struct {
+	ProbeURL       string        `yaml:"probeUrl"`
+	Timeout        time.Duration `yaml:"timeout"`
+	Interval       time.Duration `yaml:"interval"`
+	WaitForNetwork bool          `yaml:"waitForNetwork"`
+	MaxWait        time.Duration `yaml:"maxWait"`
+
+	// Monitor probes the network while running, publishing the network
+	// state on NetworkTopic and showing an indicator while offline.
+	Monitor bool `yaml:"monitor"`
+	// MonitorInterval is the interval between probes while monitoring.
+	MonitorInterval time.Duration `yaml:"monitorInterval"`
+}
+
+// NetworkTopic is the event bus topic changes to the network state are published on.
+const NetworkTopic = "glass.network"
+
+// Validate validates the network configuration.
+func (c NetworkConfig) Validate() error {
+	if c.WaitForNetwork && c.ProbeURL == "" {
+		return errors.New("config: network probe url is required to wait for the network")
+	}
+	if c.Monitor && c.ProbeURL == "" {
+		return errors.New("config: network probe url is required to monitor the network")
+	}
+	if c.Monitor && c.MonitorInterval <= 0 {
+		return errors.New("config: network monitor interval must be greater than zero")
+	}
+	if c.Timeout < 0 || c.Interval < 0 || c.MaxWait < 0 || c.MonitorInterval < 0 {
+		return errors.New("config: network durations cannot be negative")
+	}
+
+	return nil
+}
+
+// waitForNetwork blocks until the network probe succeeds or the maximum wait elapses.
+func (r *Runtime) waitForNetwork(ctx context.Context) {
+	cfg := r.cfg.Network
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.MaxWait)
+	defer cancel()
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	for attempt := 1; ; attempt++ {
+		err := probe(ctx, client, cfg.ProbeURL)
+		if err == nil {
+			r.log.Info("network is available", logCtx.Int("attempts", attempt))
+			return
+		}
+		r.log.Info("waiting for network", logCtx.Int("attempt", attempt), logCtx.Error("error", err))
+
+		select {
+		case <-ctx.Done():
+			r.log.Warn("network is not available, continuing", logCtx.Int("attempts", attempt))
+			return
+		case <-time.After(cfg.Interval):
+		}
+	}
+}
+
+// probe checks if the url is reachable.
+func probe(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Online determines if the network was available when last probed.
+//
+// The network is assumed to be available when it is not monitored.
+func (r *Runtime) Online() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return !r.offline
+}
+
+// startNetworkMonitor probes the network on an interval until ctx
+// is done or the monitor is restarted.
+func (r *Runtime) startNetworkMonitor(ctx context.Context) {
+	r.stopNetworkMonitor()
+
+	r.mu.Lock()
+	cfg := r.cfg.Network
+	r.mu.Unlock()
+
+	if !cfg.Monitor {
+		r.setOnline(true)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	r.mu.Lock()
+	r.netStop = func() {
+		cancel()
+		<-done
+	}
+	r.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		client := &http.Client{Timeout: cfg.Timeout}
+		for {
+			err := probe(ctx, client, cfg.ProbeURL)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				r.log.Debug("network probe failed", logCtx.Error("error", err))
+			}
+			r.setOnline(err == nil)
+
+			timer := time.NewTimer(cfg.MonitorInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// stopNetworkMonitor stops probing the network.
+func (r *Runtime) stopNetworkMonitor() {
+	r.mu.Lock()
+	stop := r.netStop
+	r.netStop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// setOnline updates the network state, publishing it on NetworkTopic
+// and toggling the offline indicator when it changes.
+func (r *Runtime) setOnline(online bool) {
+	r.mu.Lock()
+	changed := r.offline == online
+	r.offline = !online
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if online {
+		r.log.Info("network is available")
+	} else {
+		r.log.Warn("network is not available")
+	}
+	if err := r.ui.setOffline(!online); err != nil {
+		r.log.Error("could not update offline indicator", logCtx.Error("error", err))
+	}
+
+	b, _ := json.Marshal(struct {
+		Online bool `json:"online"`
+	}{Online: online})
+	r.bus.Publish(Event{Topic: NetworkTopic, Data: b})
+}
+
+// setOffline shows or hides the offline indicator.
+func (ui *UI) setOffline(offline bool) error {
+	label, _ := json.Marshal(ui.T("network.offline"))
+	js := fmt.Sprintf("setNetworkOffline(%t, %s);", offline, label)
+	if _, err := ui.Eval(js); err != nil {
+		return err
+	}
+
+	var setup []string
+	if offline {
+		setup = []string{js}
+	}
+	ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, "setNetworkOffline(")
+	}, setup)
+	return nil
+}
@@ -0,0 +1,41 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+// DefaultNotifyTTL is the default time a notification is shown.
+const DefaultNotifyTTL = 5 * time.Second
+
+// Notify shows a transient notification on top of the modules, dismissed
+// after ttl. Notifications stack, with the newest at the bottom.
+//
+// The level is added as a css class of the notification so it can be styled.
+func (ui *UI) Notify(module, level, message string, ttl time.Duration) error {
+	switch level {
+	case types.NotifyInfo, types.NotifyWarn, types.NotifyError:
+	default:
+		return fmt.Errorf("unknown notification level %q", level)
+	}
+	if ttl <= 0 {
+		ttl = DefaultNotifyTTL
+	}
+
+	mod, _ := json.Marshal(module)
+	lvl, _ := json.Marshal(level)
+	msg, _ := json.Marshal(message)
+	_, err := ui.Eval(fmt.Sprintf("notify(%s, %s, %s, %d);", mod, lvl, msg, ttl.Milliseconds()))
+	return err
+}
+
+// Notify shows a transient notification on top of the modules, dismissed after ttl.
+func (u *UIContext) Notify(level, message string, ttl time.Duration) error {
+	if err := u.ui.Notify(u.name, level, message, ttl); err != nil {
+		return fmt.Errorf("%s: %w", u.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package glass
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIContext_Notify(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Notify(types.NotifyWarn, `Train "S1" is delayed`, 10*time.Second)
+
+	require.NoError(t, err)
+	evals := win.Evals()
+	assert.Equal(t, `notify("test", "warn", "Train \"S1\" is delayed", 10000);`, evals[len(evals)-1])
+}
+
+func TestUIContext_NotifyUsesDefaultTTL(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Notify(types.NotifyInfo, "hello", 0)
+
+	require.NoError(t, err)
+	evals := win.Evals()
+	assert.Equal(t, `notify("test", "info", "hello", 5000);`, evals[len(evals)-1])
+}
+
+func TestUIContext_NotifyHandlesUnknownLevel(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	err := uiCtx.Notify("fatal", "hello", time.Second)
+
+	assert.EqualError(t, err, `test: unknown notification level "fatal"`)
+}
@@ -0,0 +1,149 @@
+package glass
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hamba/logger/v2"
+)
+
+// Reconfigure applies the changed settings of cfg to the running ui.
+//
+// The window size and position, custom css, zoom factor and eval timeout
+// are applied immediately, though the size and position are ignored while
+// the window is fullscreen and the position while a display is configured.
+// Each setting is applied on its own, and a setting that fails to apply is
+// kept at its old value while the others still apply. All other settings
+// only take effect when chrome is started, and the names of those that
+// changed are returned.
+func (ui *UI) Reconfigure(cfg UIConfig, log *logger.Logger) ([]string, error) {
+	if ui.isClosed() {
+		return nil, ErrClosed
+	}
+
+	ui.mu.Lock()
+	old := ui.cfg
+	ui.timeout = cfg.EvalTimeout
+	ui.mu.Unlock()
+
+	applied := cfg
+	var errs ConfigErrors
+	fullscreen := old.Fullscreen || cfg.Fullscreen
+	if !fullscreen && (cfg.Width != old.Width || cfg.Height != old.Height) {
+		if err := ui.Resize(cfg.Width, cfg.Height); err != nil {
+			applied.Width, applied.Height = old.Width, old.Height
+			errs = append(errs, err)
+		}
+	}
+	if !fullscreen && cfg.Display == 0 && (cfg.X != old.X || cfg.Y != old.Y) {
+		if err := ui.Move(cfg.X, cfg.Y); err != nil {
+			applied.X, applied.Y = old.X, old.Y
+			errs = append(errs, err)
+		}
+	}
+	if !reflect.DeepEqual(cfg.CustomCSS, old.CustomCSS) {
+		if err := ui.reloadCustomCSS(len(old.CustomCSS), cfg.CustomCSS, log); err != nil {
+			applied.CustomCSS = old.CustomCSS
+			errs = append(errs, err)
+		}
+	}
+	if cfg.ZoomFactor != old.ZoomFactor {
+		if err := ui.applyZoom(cfg.ZoomFactor); err != nil {
+			applied.ZoomFactor = old.ZoomFactor
+			errs = append(errs, err)
+		}
+	}
+
+	ui.mu.Lock()
+	ui.cfg = applied
+	ui.mu.Unlock()
+
+	var restart []string
+	for _, setting := range []struct {
+		name    string
+		old, nw interface{}
+	}{
+		{name: "ui.fullscreen", old: old.Fullscreen, nw: cfg.Fullscreen},
+		{name: "ui.loadTimeout", old: old.LoadTimeout, nw: cfg.LoadTimeout},
+		{name: "ui.cache", old: old.Cache, nw: cfg.Cache},
+		{name: "ui.lang", old: old.Lang, nw: cfg.Lang},
+		{name: "ui.locales", old: old.Locales, nw: cfg.Locales},
+		{name: "ui.fonts", old: old.Fonts, nw: cfg.Fonts},
+		{name: "ui.skipSelfTest", old: old.SkipSelfTest, nw: cfg.SkipSelfTest},
+		{name: "ui.preventSleep", old: old.PreventSleep, nw: cfg.PreventSleep},
+		{name: "ui.chromeArgs", old: old.ChromeArgs, nw: cfg.ChromeArgs},
+		{name: "ui.gridColumns", old: old.GridColumns, nw: cfg.GridColumns},
+		{name: "ui.windowOpacity", old: old.WindowOpacity, nw: cfg.WindowOpacity},
+		{name: "ui.splash", old: old.Splash, nw: cfg.Splash},
+		{name: "ui.display", old: old.Display, nw: cfg.Display},
+	} {
+		if !reflect.DeepEqual(setting.old, setting.nw) {
+			restart = append(restart, setting.name)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return restart, nil
+	case 1:
+		return restart, errs[0]
+	default:
+		return restart, errs
+	}
+}
+
+// reloadCustomCSS replaces the n loaded custom css files with the files at paths.
+func (ui *UI) reloadCustomCSS(n int, paths []string, log *logger.Logger) error {
+	css, err := customCSSJS(paths, log)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i <= n; i++ {
+		if _, err = ui.Eval(`removeCSS("customCSS` + strconv.Itoa(i) + `");`); err != nil {
+			return fmt.Errorf("could not remove custom css: %w", err)
+		}
+	}
+	for _, js := range css {
+		if _, err = ui.Eval(js); err != nil {
+			return fmt.Errorf("could not load custom css: %w", err)
+		}
+	}
+
+	ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("customCSS`)
+	}, css)
+	return nil
+}
+
+// applyZoom sets the page zoom factor. Zero resets the zoom.
+func (ui *UI) applyZoom(factor float64) error {
+	if factor <= 0 {
+		factor = 1
+	}
+	js := "setZoom(" + formatFloat(factor) + ");"
+	if _, err := ui.Eval(js); err != nil {
+		return fmt.Errorf("could not set zoom: %w", err)
+	}
+
+	ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, "setZoom(")
+	}, []string{js})
+	return nil
+}
+
+// replaceSetup replaces the page setup javascript matching fn with js,
+// so the setup is replayed as it is currently applied.
+func (ui *UI) replaceSetup(fn func(js string) bool, js []string) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	setup := make([]string, 0, len(ui.setup)+len(js))
+	for _, s := range ui.setup {
+		if !fn(s) {
+			setup = append(setup, s)
+		}
+	}
+	ui.setup = append(setup, js...)
+}
@@ -0,0 +1,141 @@
+package glass
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zserge/lorca"
+)
+
+func TestUI_Reconfigure(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{
+		win: win,
+		cfg: UIConfig{Width: 640, Height: 480, CustomCSS: []string{"old.css"}},
+		setup: []string{
+			`loadCSS("fonts", "fonts css");`,
+			`loadCSS("customCSS1", "old css");`,
+		},
+	}
+
+	restart, err := ui.Reconfigure(UIConfig{
+		Width:       1024,
+		Height:      768,
+		CustomCSS:   []string{"testdata/custom.css"},
+		ZoomFactor:  2,
+		EvalTimeout: time.Second,
+	}, newTestLogger())
+
+	require.NoError(t, err)
+	assert.Empty(t, restart)
+	b, _ := win.Bounds()
+	assert.Equal(t, 1024, b.Width)
+	assert.Equal(t, 768, b.Height)
+	assert.Equal(t, []string{
+		`removeCSS("customCSS1");`,
+		`loadCSS("customCSS1", "custom css");`,
+		"setZoom(2);",
+	}, win.Evals())
+	assert.Equal(t, []string{
+		`loadCSS("fonts", "fonts css");`,
+		`loadCSS("customCSS1", "custom css");`,
+		"setZoom(2);",
+	}, ui.setup)
+	assert.Equal(t, time.Second, ui.timeout)
+}
+
+func TestUI_ReconfigureReturnsRestartSettings(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win, cfg: UIConfig{Width: 640, Height: 480}}
+
+	restart, err := ui.Reconfigure(UIConfig{
+		Width:      640,
+		Height:     480,
+		Fullscreen: true,
+		Lang:       "de",
+		ChromeArgs: []string{"--kiosk"},
+	}, newTestLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ui.fullscreen", "ui.lang", "ui.chromeArgs"}, restart)
+	assert.Empty(t, win.Evals())
+	assert.True(t, ui.cfg.Fullscreen)
+}
+
+func TestUI_ReconfigureIgnoresPositionOnDisplay(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win, cfg: UIConfig{Width: 640, Height: 480}}
+
+	restart, err := ui.Reconfigure(UIConfig{
+		Width:   640,
+		Height:  480,
+		X:       100,
+		Y:       50,
+		Display: 2,
+	}, newTestLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ui.display"}, restart)
+	b, _ := win.Bounds()
+	assert.Equal(t, 0, b.Left)
+	assert.Equal(t, 0, b.Top)
+}
+
+func TestUI_ReconfigureIgnoresBoundsWhenFullscreen(t *testing.T) {
+	win := NewRecordingWindow()
+	_ = win.SetBounds(lorca.Bounds{Width: 1920, Height: 1080, WindowState: lorca.WindowStateFullscreen})
+	ui := &UI{win: win, cfg: UIConfig{Width: 640, Height: 480, Fullscreen: true}}
+
+	restart, err := ui.Reconfigure(UIConfig{
+		Width:      1024,
+		Height:     768,
+		X:          100,
+		Y:          50,
+		Fullscreen: true,
+	}, newTestLogger())
+
+	require.NoError(t, err)
+	assert.Empty(t, restart)
+	b, _ := win.Bounds()
+	assert.Equal(t, lorca.Bounds{Width: 1920, Height: 1080, WindowState: lorca.WindowStateFullscreen}, b)
+	assert.Equal(t, 100, ui.cfg.X)
+	assert.Equal(t, 1024, ui.cfg.Width)
+}
+
+func TestUI_ReconfigureHandlesMissingCustomCSS(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win, cfg: UIConfig{CustomCSS: []string{"old.css"}}}
+
+	_, err := ui.Reconfigure(UIConfig{
+		CustomCSS:   []string{"testdata/missing.css"},
+		ZoomFactor:  2,
+		EvalTimeout: time.Second,
+	}, newTestLogger())
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"setZoom(2);"}, win.Evals())
+	assert.Equal(t, []string{"old.css"}, ui.cfg.CustomCSS)
+	assert.Equal(t, 2.0, ui.cfg.ZoomFactor)
+	assert.Equal(t, time.Second, ui.timeout)
+}
+
+func TestUI_ReconfigureReturnsAllErrors(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Bounds").Return(lorca.Bounds{}, errors.New("test error"))
+	ui := &UI{win: win, cfg: UIConfig{Width: 640, Height: 480}}
+
+	_, err := ui.Reconfigure(UIConfig{
+		Width:     1024,
+		Height:    768,
+		CustomCSS: []string{"testdata/missing.css"},
+	}, newTestLogger())
+
+	var errs ConfigErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 640, ui.cfg.Width)
+	assert.Empty(t, ui.cfg.CustomCSS)
+}
@@ -0,0 +1,98 @@
+package glass
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+// ModuleRegistry keeps the descriptors of the loaded modules,
+// allowing modules to discover each other.
+type ModuleRegistry struct {
+	mu     sync.RWMutex
+	loaded []string
+	descs  map[string]types.ModuleDescriptor
+}
+
+// NewModuleRegistry returns a module registry.
+func NewModuleRegistry() *ModuleRegistry {
+	return &ModuleRegistry{
+		descs: map[string]types.ModuleDescriptor{},
+	}
+}
+
+// Modules returns the loaded modules in the order they were loaded.
+func (r *ModuleRegistry) Modules() []types.ModuleInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]types.ModuleInfo, 0, len(r.loaded))
+	for _, name := range r.loaded {
+		desc := r.descs[name]
+		// The topics are copied, so modules cannot change the registry.
+		desc.Topics = append([]string(nil), desc.Topics...)
+		infos = append(infos, types.ModuleInfo{Instance: name, ModuleDescriptor: desc})
+	}
+	return infos
+}
+
+// add marks the module as loaded.
+func (r *ModuleRegistry) add(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.loaded {
+		if n == name {
+			return
+		}
+	}
+	r.loaded = append(r.loaded, name)
+}
+
+// remove removes the module and its descriptor.
+func (r *ModuleRegistry) remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, n := range r.loaded {
+		if n == name {
+			r.loaded = append(r.loaded[:i:i], r.loaded[i+1:]...)
+			break
+		}
+	}
+	delete(r.descs, name)
+}
+
+// describe sets the descriptor of the module. Modules usually describe
+// themselves while initialising, before they are loaded.
+func (r *ModuleRegistry) describe(name string, desc types.ModuleDescriptor) {
+	desc.Topics = append([]string(nil), desc.Topics...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.descs[name] = desc
+}
+
+// Describe registers the descriptor of the module, making its
+// capabilities discoverable by other modules.
+func (u *UIContext) Describe(desc types.ModuleDescriptor) error {
+	if u.reg == nil {
+		return fmt.Errorf("%s: module registry is not supported", u.name)
+	}
+
+	u.reg.describe(u.name, desc)
+	return nil
+}
+
+// Modules returns the loaded modules, including the module itself.
+//
+// The returned modules are a copy, modules can only change
+// their own descriptor using Describe.
+func (u *UIContext) Modules() []types.ModuleInfo {
+	if u.reg == nil {
+		return nil
+	}
+	return u.reg.Modules()
+}
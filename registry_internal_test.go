@@ -0,0 +1,127 @@
+package glass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newRegistryContexts(t *testing.T) (weather, dashboard *UIContext) {
+	t.Helper()
+
+	ui := &UI{win: NewRecordingWindow()}
+	reg := NewModuleRegistry()
+	weather, err := NewUIContext(ui, "weather", module.Position{Vertical: module.Top, Horizontal: module.Left})
+	require.NoError(t, err)
+	dashboard, err = NewUIContext(ui, "dashboard", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	weather.reg, dashboard.reg = reg, reg
+	return weather, dashboard
+}
+
+func TestUIContext_Modules(t *testing.T) {
+	weather, dashboard := newRegistryContexts(t)
+
+	err := weather.Describe(types.ModuleDescriptor{Name: "weather", Version: "v1.2.0", Topics: []string{"weather.current"}})
+	require.NoError(t, err)
+	weather.reg.add("weather")
+	dashboard.reg.add("dashboard")
+
+	got := dashboard.Modules()
+
+	assert.Equal(t, []types.ModuleInfo{
+		{
+			Instance: "weather",
+			ModuleDescriptor: types.ModuleDescriptor{
+				Name:    "weather",
+				Version: "v1.2.0",
+				Topics:  []string{"weather.current"},
+			},
+		},
+		{Instance: "dashboard"},
+	}, got)
+}
+
+func TestUIContext_ModulesCannotChangeRegistry(t *testing.T) {
+	weather, dashboard := newRegistryContexts(t)
+
+	topics := []string{"weather.current"}
+	err := weather.Describe(types.ModuleDescriptor{Name: "weather", Topics: topics})
+	require.NoError(t, err)
+	weather.reg.add("weather")
+	topics[0] = "changed"
+
+	got := dashboard.Modules()
+	got[0].Name = "changed"
+	got[0].Topics[0] = "changed"
+
+	got = dashboard.Modules()
+	require.Len(t, got, 1)
+	assert.Equal(t, "weather", got[0].Name)
+	assert.Equal(t, []string{"weather.current"}, got[0].Topics)
+}
+
+func TestUIContext_ModulesExcludesClosedModules(t *testing.T) {
+	weather, dashboard := newRegistryContexts(t)
+
+	err := weather.Describe(types.ModuleDescriptor{Name: "weather"})
+	require.NoError(t, err)
+	weather.reg.add("weather")
+	dashboard.reg.add("dashboard")
+
+	err = weather.Close()
+	require.NoError(t, err)
+
+	got := dashboard.Modules()
+	require.Len(t, got, 1)
+	assert.Equal(t, "dashboard", got[0].Instance)
+}
+
+func TestUIContext_DescribeWithoutRegistry(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow()}
+	uiCtx, err := NewUIContext(ui, "weather", module.Position{Vertical: module.Top, Horizontal: module.Left})
+	require.NoError(t, err)
+
+	err = uiCtx.Describe(types.ModuleDescriptor{Name: "weather"})
+
+	assert.EqualError(t, err, "weather: module registry is not supported")
+	assert.Nil(t, uiCtx.Modules())
+}
+
+func TestRuntime_LoadRegistersModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	mod := &MockModule{}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.AnythingOfType("*glass.UIContext"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			_ = args.Get(2).(types.UI).Describe(types.ModuleDescriptor{Name: "clock", Version: "v1.0.0"})
+		}).
+		Return(mod, nil)
+
+	cfg := Config{Modules: []module.Descriptor{desc}}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []types.ModuleInfo{
+		{
+			Instance:         "test",
+			ModuleDescriptor: types.ModuleDescriptor{Name: "clock", Version: "v1.0.0"},
+		},
+	}, rt.Modules().Modules())
+}
@@ -0,0 +1,144 @@
+package glass
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hamba/logger/v2"
+	"github.com/zserge/lorca"
+)
+
+// moduleRecord records the javascript needed to restore a module.
+type moduleRecord struct {
+	name    string
+	create  string
+	sandbox []string
+	data    string
+	css     []string
+	load    []string
+	html    []string
+	hidden  string
+}
+
+func (ui *UI) recordModule(name, js string) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	for _, rec := range ui.mods {
+		if rec.name == name {
+			rec.create = js
+			return
+		}
+	}
+	ui.mods = append(ui.mods, &moduleRecord{name: name, create: js})
+}
+
+func (ui *UI) forgetModule(name string) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	for i, rec := range ui.mods {
+		if rec.name == name {
+			ui.mods = append(ui.mods[:i], ui.mods[i+1:]...)
+			return
+		}
+	}
+}
+
+func (ui *UI) updateModule(name string, fn func(rec *moduleRecord)) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	for _, rec := range ui.mods {
+		if rec.name == name {
+			fn(rec)
+			return
+		}
+	}
+}
+
+// Replay restores the ui state after the page has been reloaded.
+//
+// The page setup, such as the global css, is applied first, followed by each module's element,
+// sandbox, data, css, html and visibility, in that order. The grid layout is applied last.
+func (ui *UI) Replay() error {
+	ui.mu.RLock()
+	js := append([]string(nil), ui.setup...)
+	if ui.animations != "" {
+		js = append(js, ui.animations)
+	}
+	for _, rec := range ui.mods {
+		js = append(js, rec.create)
+		js = append(js, rec.sandbox...)
+		if rec.data != "" {
+			js = append(js, rec.data)
+		}
+		js = append(js, rec.css...)
+		js = append(js, rec.load...)
+		js = append(js, rec.html...)
+		if rec.hidden != "" {
+			js = append(js, rec.hidden)
+		}
+	}
+	if ui.layout != "" {
+		js = append(js, ui.layout)
+	}
+	ui.mu.RUnlock()
+
+	for _, s := range js {
+		if _, err := ui.Eval(s); err != nil {
+			return fmt.Errorf("could not replay ui: %w", err)
+		}
+	}
+	return nil
+}
+
+// Relaunch replaces the chrome window with a new one, restoring its state.
+//
+// It is used to recover when chrome has stopped unexpectedly. Bound functions
+// are bound to the new window before the ui state is replayed.
+func (ui *UI) Relaunch(log *logger.Logger) error {
+	ui.mu.RLock()
+	closed := ui.closed
+	ui.mu.RUnlock()
+	if closed {
+		return ErrClosed
+	}
+
+	win, profile, err := openWindow(resolveDisplay(ui.cfg, listDisplays, log))
+	if err != nil {
+		return err
+	}
+
+	ui.mu.Lock()
+	if rec, ok := ui.win.(*sessionRecorder); ok {
+		win = &sessionRecorder{UI: win, mu: rec.mu, enc: rec.enc, start: rec.start}
+	}
+	old, oldProfile := ui.win, ui.profile
+	ui.win, ui.profile = win, profile
+	// Calls abandoned on the old window may never return, so the new
+	// window gets its own queue.
+	ui.cmds = nil
+	ui.unbound = nil
+	ui.splash = false
+	fns := make(map[string]interface{}, len(ui.fns))
+	for name, fn := range ui.fns {
+		fns[name] = fn
+	}
+	ui.mu.Unlock()
+
+	_ = old.Close()
+	if oldProfile != "" {
+		_ = os.RemoveAll(oldProfile)
+	}
+
+	for name, fn := range fns {
+		fn := fn
+		if err = ui.do("bind "+name, func(win lorca.UI) error {
+			return win.Bind(name, fn)
+		}); err != nil {
+			return fmt.Errorf("could not bind %q: %w", name, err)
+		}
+	}
+	return ui.Replay()
+}
@@ -0,0 +1,211 @@
+package glass
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/agiledragon/gomonkey/v2"
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zserge/lorca"
+)
+
+func TestUI_Replay(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win, setup: []string{"loadCSS(`fonts`, `fonts css`);"}}
+
+	clock, err := NewUIContext(ui, "clock", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	err = clock.LoadHTML("clock html")
+	require.NoError(t, err)
+	err = clock.LoadCSS("clock css")
+	require.NoError(t, err)
+	news, err := NewUIContext(ui, "news", module.Position{Vertical: module.Bottom, Horizontal: module.Left})
+	require.NoError(t, err)
+	err = news.LoadCSS("news css")
+	require.NoError(t, err)
+	news.SetMaxNodes(1)
+	err = news.AppendHTML("news 1")
+	require.NoError(t, err)
+	err = news.AppendHTML("news 2")
+	require.NoError(t, err)
+	err = news.AppendHTML("news 3")
+	require.NoError(t, err)
+
+	reloaded := NewRecordingWindow()
+	ui.win = reloaded
+
+	err = ui.Replay()
+
+	require.NoError(t, err)
+	want := []string{
+		"loadCSS(`fonts`, `fonts css`);",
+		`createModule("clock", "top", "right");`,
+		"loadCSS(`clock`, `clock css`);",
+		"loadModuleHTML(`clock`, `clock html`);",
+		`createModule("news", "bottom", "left");`,
+		"loadCSS(`news`, `news css`);",
+		"appendModuleHTML(`news`, `news 2`, 1);",
+		"appendModuleHTML(`news`, `news 3`, 1);",
+	}
+	assert.Equal(t, want, reloaded.Evals())
+}
+
+func TestUI_ReplayRestoresHiddenModules(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	clock, err := NewUIContext(ui, "clock", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	err = clock.LoadHTML("clock html")
+	require.NoError(t, err)
+	err = clock.SetVisible(false)
+	require.NoError(t, err)
+	news, err := NewUIContext(ui, "news", module.Position{Vertical: module.Bottom, Horizontal: module.Left})
+	require.NoError(t, err)
+	err = news.SetVisible(false)
+	require.NoError(t, err)
+	err = news.SetVisible(true)
+	require.NoError(t, err)
+
+	reloaded := NewRecordingWindow()
+	ui.win = reloaded
+
+	err = ui.Replay()
+
+	require.NoError(t, err)
+	want := []string{
+		`createModule("clock", "top", "right");`,
+		"loadModuleHTML(`clock`, `clock html`);",
+		`setModuleVisible("clock", false);`,
+		`createModule("news", "bottom", "left");`,
+	}
+	assert.Equal(t, want, reloaded.Evals())
+}
+
+func TestUI_ReplayRestoresModuleData(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow()}
+	clock, err := NewUIContext(ui, "clock", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	err = clock.LoadHTML("clock html")
+	require.NoError(t, err)
+	err = clock.SetData("10:00")
+	require.NoError(t, err)
+	err = clock.SetData("10:01")
+	require.NoError(t, err)
+
+	reloaded := NewRecordingWindow()
+	ui.win = reloaded
+
+	err = ui.Replay()
+
+	require.NoError(t, err)
+	want := []string{
+		`createModule("clock", "top", "right");`,
+		`setModuleData("clock", "10:01");`,
+		"loadModuleHTML(`clock`, `clock html`);",
+	}
+	assert.Equal(t, want, reloaded.Evals())
+}
+
+func TestUI_Relaunch(t *testing.T) {
+	old := NewRecordingWindow()
+	ui := &UI{win: old, cfg: UIConfig{SkipSelfTest: true}, setup: []string{"loadCSS(`fonts`, `fonts css`);"}}
+	clock, err := NewUIContext(ui, "clock", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	err = clock.LoadHTML("clock html")
+	require.NoError(t, err)
+	err = clock.Bind("tick", func() {})
+	require.NoError(t, err)
+
+	relaunched := NewRecordingWindow()
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return relaunched, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	err = ui.Relaunch(newTestLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, relaunched, ui.window())
+	select {
+	case <-old.Done():
+	default:
+		assert.Fail(t, "old window was not closed")
+	}
+	_, ok := relaunched.Binding("tick")
+	assert.True(t, ok)
+	evals := relaunched.Evals()
+	assert.Equal(t, []string{
+		"loadCSS(`fonts`, `fonts css`);",
+		`createModule("clock", "top", "right");`,
+		"loadModuleHTML(`clock`, `clock html`);",
+	}, evals[len(evals)-3:])
+}
+
+// hungWindow is a window whose evaluations do not return until released,
+// as happens when chrome stops during an evaluation.
+type hungWindow struct {
+	*RecordingWindow
+
+	release chan struct{}
+}
+
+func (w *hungWindow) Eval(js string) lorca.Value {
+	<-w.release
+	return w.RecordingWindow.Eval(js)
+}
+
+func TestUI_RelaunchAfterWindowStopsDuringEval(t *testing.T) {
+	old := &hungWindow{RecordingWindow: NewRecordingWindow(), release: make(chan struct{})}
+	t.Cleanup(func() { close(old.release) })
+	ui := &UI{win: old, cfg: UIConfig{SkipSelfTest: true}, timeout: 50 * time.Millisecond}
+	err := ui.Bind("tick", func() {})
+	require.NoError(t, err)
+
+	_, err = ui.Eval("hung js")
+	require.ErrorIs(t, err, ErrEvalTimeout)
+	_ = old.Close()
+
+	err = ui.Bind("tock", func() {})
+	require.NoError(t, err)
+
+	relaunched := NewRecordingWindow()
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return relaunched, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Relaunch(newTestLogger())
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "relaunch did not complete")
+	}
+	require.NoError(t, err)
+	_, ok := relaunched.Binding("tick")
+	assert.True(t, ok)
+	_, ok = relaunched.Binding("tock")
+	assert.True(t, ok)
+}
+
+func TestUI_RelaunchReturnsErrClosed(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow()}
+	_ = ui.Close()
+
+	err := ui.Relaunch(newTestLogger())
+
+	assert.ErrorIs(t, err, ErrClosed)
+}
@@ -0,0 +1,149 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// ErrRestartLimit is returned when the maximum number of restarts has been reached.
+var ErrRestartLimit = errors.New("restart limit reached")
+
+// RestartConfig contains configuration for automatic restarts.
+type RestartConfig struct {
+	// Enabled relaunches chrome when it stops unexpectedly.
+	Enabled bool `yaml:"enabled"`
+	// MinInterval is the minimum time between restarts.
+	MinInterval time.Duration `yaml:"minInterval"`
+	// MaxPerHour is the maximum number of restarts in an hour.
+	// Zero means no limit.
+	MaxPerHour int `yaml:"maxPerHour"`
+}
+
+// Validate validates the restart configuration.
+func (c RestartConfig) Validate() error {
+	if c.MinInterval < 0 || c.MaxPerHour < 0 {
+		return errors.New("config: restart interval and limit cannot be negative")
+	}
+	return nil
+}
+
+// RestartGovernor limits the rate of automatic restarts.
+type RestartGovernor struct {
+	cfg RestartConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	restarts []time.Time
+	stopped  bool
+}
+
+// NewRestartGovernor returns a restart governor.
+func NewRestartGovernor(cfg RestartConfig) *RestartGovernor {
+	return &RestartGovernor{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Restart registers a restart, returning the time to wait before restarting.
+//
+// Once the maximum number of restarts in an hour has been reached,
+// ErrRestartLimit is returned and all further restarts are refused.
+func (g *RestartGovernor) Restart() (time.Duration, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stopped {
+		return 0, ErrRestartLimit
+	}
+
+	now := g.now()
+	hourAgo := now.Add(-time.Hour)
+	i := 0
+	for i < len(g.restarts) && !g.restarts[i].After(hourAgo) {
+		i++
+	}
+	g.restarts = g.restarts[i:]
+
+	if g.cfg.MaxPerHour > 0 && len(g.restarts) >= g.cfg.MaxPerHour {
+		g.stopped = true
+		return 0, ErrRestartLimit
+	}
+
+	var delay time.Duration
+	if len(g.restarts) > 0 {
+		next := g.restarts[len(g.restarts)-1].Add(g.cfg.MinInterval)
+		if next.After(now) {
+			delay = next.Sub(now)
+		}
+	}
+	g.restarts = append(g.restarts, now.Add(delay))
+
+	return delay, nil
+}
+
+// Stopped determines if the governor has stopped allowing restarts.
+func (g *RestartGovernor) Stopped() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.stopped
+}
+
+// Recover relaunches the ui window after chrome has stopped, limiting
+// the rate of relaunches with gov.
+//
+// Failed relaunches are retried until the restart limit is reached. The
+// window then shows a persistent error instead of the modules, and
+// ErrRestartLimit is returned.
+func (ui *UI) Recover(ctx context.Context, gov *RestartGovernor, log *logger.Logger) error {
+	for {
+		delay, err := gov.Restart()
+		if err != nil {
+			if errors.Is(err, ErrRestartLimit) {
+				ui.showRestartLimit(log)
+			}
+			return err
+		}
+		if delay > 0 {
+			log.Info("waiting to relaunch chrome", logCtx.Duration("delay", delay))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if err = ui.Relaunch(log); err != nil {
+			if errors.Is(err, ErrClosed) {
+				return err
+			}
+			log.Error("could not relaunch chrome", logCtx.Error("error", err))
+			continue
+		}
+		return nil
+	}
+}
+
+// showRestartLimit shows the restart limit error in the window, relaunching
+// chrome a last time if it is not running.
+func (ui *UI) showRestartLimit(log *logger.Logger) {
+	select {
+	case <-ui.Done():
+		if err := ui.Relaunch(log); err != nil {
+			log.Error("could not relaunch chrome to show the restart limit", logCtx.Error("error", err))
+			return
+		}
+	default:
+	}
+
+	if err := ui.ShowError(ui.T("error.restartLimit"), ErrRestartLimit); err != nil {
+		log.Error("could not show restart limit error", logCtx.Error("error", err))
+	}
+}
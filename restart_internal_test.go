@@ -0,0 +1,137 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zserge/lorca"
+)
+
+func TestRestartGovernor_EnforcesMinInterval(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewRestartGovernor(RestartConfig{MinInterval: time.Minute})
+	g.now = func() time.Time { return now }
+
+	delay, err := g.Restart()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+
+	now = now.Add(20 * time.Second)
+	delay, err = g.Restart()
+	require.NoError(t, err)
+	assert.Equal(t, 40*time.Second, delay)
+
+	now = now.Add(3 * time.Minute)
+	delay, err = g.Restart()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func TestRestartGovernor_EnforcesMaxPerHour(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewRestartGovernor(RestartConfig{MinInterval: time.Minute, MaxPerHour: 3})
+	g.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := g.Restart()
+		require.NoError(t, err)
+		now = now.Add(5 * time.Minute)
+	}
+
+	_, err := g.Restart()
+	require.ErrorIs(t, err, ErrRestartLimit)
+	assert.True(t, g.Stopped())
+
+	// The governor stays stopped even once the hour has passed.
+	now = now.Add(2 * time.Hour)
+	_, err = g.Restart()
+	assert.ErrorIs(t, err, ErrRestartLimit)
+}
+
+func TestRestartGovernor_ForgetsRestartsOlderThanAnHour(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewRestartGovernor(RestartConfig{MaxPerHour: 2})
+	g.now = func() time.Time { return now }
+
+	_, err := g.Restart()
+	require.NoError(t, err)
+	now = now.Add(40 * time.Minute)
+	_, err = g.Restart()
+	require.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+
+	_, err = g.Restart()
+
+	assert.NoError(t, err)
+	assert.False(t, g.Stopped())
+}
+
+func TestUI_Recover(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow(), cfg: UIConfig{SkipSelfTest: true}}
+	relaunched := NewRecordingWindow()
+	calls := 0
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		calls++
+		if calls == 1 {
+			return nil, errors.New("test error")
+		}
+		return relaunched, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	err := ui.Recover(context.Background(), NewRestartGovernor(RestartConfig{}), newTestLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, relaunched, ui.window())
+}
+
+func TestUI_RecoverStopsAtRestartLimit(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow(), cfg: UIConfig{SkipSelfTest: true}}
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return nil, errors.New("test error")
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	err := ui.Recover(context.Background(), NewRestartGovernor(RestartConfig{MaxPerHour: 3}), newTestLogger())
+
+	assert.ErrorIs(t, err, ErrRestartLimit)
+}
+
+func TestUI_RecoverShowsRestartLimit(t *testing.T) {
+	win := NewRecordingWindow()
+	_ = win.Close()
+	ui := &UI{win: win, cfg: UIConfig{SkipSelfTest: true}}
+	relaunched := NewRecordingWindow()
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return relaunched, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+	gov := NewRestartGovernor(RestartConfig{MaxPerHour: 1})
+	_, err := gov.Restart()
+	require.NoError(t, err)
+
+	err = ui.Recover(context.Background(), gov, newTestLogger())
+
+	assert.ErrorIs(t, err, ErrRestartLimit)
+	assert.Equal(t, relaunched, ui.window())
+	calls := relaunched.Calls()
+	require.NotEmpty(t, calls)
+	assert.Equal(t, "Load", calls[len(calls)-1].Method)
+	assert.Contains(t, calls[len(calls)-1].Arg, "data:text/html")
+}
@@ -0,0 +1,680 @@
+package glass
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/looking-glass/internal/expr"
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/hamba/logger/v2"
+	logCtx "github.com/hamba/logger/v2/ctx"
+	"gopkg.in/yaml.v3"
+)
+
+// ModuleRunner extracts and runs modules.
+type ModuleRunner interface {
+	Extract(desc module.Descriptor) error
+	Run(ctx context.Context, desc module.Descriptor, ui types.UI, log types.Logger) (io.Closer, error)
+}
+
+// Runtime manages the modules running in a ui.
+type Runtime struct {
+	cfg  Config
+	ui   *UI
+	svc  ModuleRunner
+	log  *logger.Logger
+	bus  *EventBus
+	reg  *ModuleRegistry
+	http *Fetcher
+
+	metrics *Metrics
+	sched   *Scheduler
+	awake   KeepAwaker
+	watch   *moduleWatcher
+
+	themeMu sync.Mutex
+	// blankMu serialises blanking and waking the screen.
+	blankMu sync.Mutex
+
+	mu     sync.Mutex
+	states []*moduleState
+	mods   []io.Closer
+
+	theme      string
+	themeFiles int
+	themeStop  func()
+
+	offline bool
+	netStop func()
+
+	blanked   bool
+	blankStop func()
+	// blankAnimations is whether animations were enabled before the screen was blanked.
+	blankAnimations bool
+
+	backgroundStop func()
+}
+
+// NewRuntime returns a runtime.
+func NewRuntime(cfg Config, ui *UI, svc ModuleRunner, log *logger.Logger) *Runtime {
+	states := make([]*moduleState, len(cfg.Modules))
+	for i, desc := range cfg.Modules {
+		states[i] = &moduleState{desc: desc}
+	}
+
+	var awake KeepAwaker
+	if cfg.UI.PreventSleep {
+		awake = newKeepAwaker()
+	}
+
+	metrics := NewMetrics()
+	ui.UseMetrics(metrics)
+
+	return &Runtime{
+		cfg:     cfg,
+		ui:      ui,
+		svc:     svc,
+		log:     log,
+		bus:     NewEventBus(),
+		reg:     NewModuleRegistry(),
+		http:    NewFetcher(cfg.HTTP),
+		metrics: metrics,
+		sched:   NewScheduler(DefaultMaxBackoff),
+		awake:   awake,
+		states:  states,
+	}
+}
+
+// Metrics returns the metrics of the runtime.
+func (r *Runtime) Metrics() *Metrics {
+	return r.metrics
+}
+
+// Events returns the event bus of the runtime.
+func (r *Runtime) Events() *EventBus {
+	return r.bus
+}
+
+// Modules returns the module registry of the runtime.
+func (r *Runtime) Modules() *ModuleRegistry {
+	return r.reg
+}
+
+// Load extracts and runs the configured modules.
+func (r *Runtime) Load(ctx context.Context) error {
+	r.startKeepAwake()
+	r.ui.OnScriptError(r.scriptError)
+	r.ui.OnPanic(r.modulePanic)
+	r.ui.OnResize(r.viewportResized)
+	r.sched.OnOverrun(r.refreshOverrun)
+
+	// Modules that fail are only returned once their retries are exhausted,
+	// so the splash is hidden even if modules do not load.
+	defer func() {
+		if err := r.ui.hideSplash(); err != nil {
+			r.log.Error("could not hide splash", logCtx.Error("error", err))
+		}
+	}()
+
+	if r.cfg.Network.WaitForNetwork {
+		r.waitForNetwork(ctx)
+	}
+
+	if err := r.startThemes(ctx); err != nil {
+		r.log.Error("could not apply theme", logCtx.Error("error", err))
+	}
+	if err := r.startBackground(ctx); err != nil {
+		r.log.Error("could not apply background", logCtx.Error("error", err))
+	}
+	r.startNetworkMonitor(ctx)
+	r.startBlank(ctx)
+	if r.cfg.Activity.Enabled {
+		if err := r.startActivity(); err != nil {
+			r.log.Error("could not watch activity", logCtx.Error("error", err))
+		}
+	}
+
+	if r.cfg.Dev {
+		if err := r.enableModuleDrag(); err != nil {
+			r.log.Error("could not enable module dragging", logCtx.Error("error", err))
+		}
+	}
+
+	return r.load(ctx, r.states, false)
+}
+
+// Reload applies a new configuration.
+//
+// Modules that were removed or changed are faded out and closed, while
+// modules that were added or changed are loaded and faded in. Modules that
+// did not change keep running. Ui settings are applied where possible.
+func (r *Runtime) Reload(ctx context.Context, cfg Config) error {
+	restart, err := r.ui.Reconfigure(cfg.UI, r.log)
+	if err != nil {
+		r.log.Error("could not apply ui configuration", logCtx.Error("error", err))
+	}
+	if len(restart) > 0 {
+		r.log.Warn("ui settings require a restart to apply", logCtx.Strs("settings", restart))
+	}
+
+	r.mu.Lock()
+	oldStates := r.states
+	r.mu.Unlock()
+
+	old := make(map[string]*moduleState, len(oldStates))
+	for _, state := range oldStates {
+		old[state.desc.InstanceID()] = state
+	}
+
+	keep := map[string]bool{}
+	states := make([]*moduleState, 0, len(cfg.Modules))
+	var added []*moduleState
+	for _, desc := range cfg.Modules {
+		if state, ok := old[desc.InstanceID()]; ok && sameDescriptor(state.desc, desc) {
+			keep[desc.InstanceID()] = true
+			states = append(states, state)
+			continue
+		}
+		state := &moduleState{desc: desc}
+		states = append(states, state)
+		added = append(added, state)
+	}
+
+	for _, state := range oldStates {
+		if !keep[state.desc.InstanceID()] {
+			r.unload(state)
+		}
+	}
+
+	r.mu.Lock()
+	if r.cfg.HTTP != cfg.HTTP {
+		r.http.configure(cfg.HTTP)
+	}
+	themesChanged := !reflect.DeepEqual(r.cfg.Themes, cfg.Themes)
+	networkChanged := r.cfg.Network != cfg.Network
+	blankChanged := !reflect.DeepEqual(r.cfg.Blank, cfg.Blank)
+	backgroundChanged := r.cfg.Background != cfg.Background
+	activityChanged := r.cfg.Activity != cfg.Activity
+	activityWasEnabled := r.cfg.Activity.Enabled
+	devChanged := r.cfg.Dev != cfg.Dev
+	r.cfg = cfg
+	r.states = states
+	r.mu.Unlock()
+
+	if themesChanged {
+		if err = r.startThemes(ctx); err != nil {
+			r.log.Error("could not apply theme", logCtx.Error("error", err))
+		}
+	}
+	if backgroundChanged {
+		if err = r.startBackground(ctx); err != nil {
+			r.log.Error("could not apply background", logCtx.Error("error", err))
+		}
+	}
+	if networkChanged {
+		r.startNetworkMonitor(ctx)
+	}
+	if blankChanged {
+		r.startBlank(ctx)
+	}
+	if activityChanged {
+		r.restartActivity(activityWasEnabled, cfg.Activity.Enabled)
+	}
+	if devChanged {
+		// Added modules are watched when they are loaded.
+		r.restartDev(ctx, cfg.Dev, oldStates)
+	}
+
+	return r.load(ctx, added, true)
+}
+
+// sameDescriptor determines if two module descriptors are equivalent.
+func sameDescriptor(a, b module.Descriptor) bool {
+	aCfg, err := yaml.Marshal(&a.Config)
+	if err != nil {
+		return false
+	}
+	bCfg, err := yaml.Marshal(&b.Config)
+	if err != nil {
+		return false
+	}
+	a.Config, b.Config = yaml.Node{}, yaml.Node{}
+	return reflect.DeepEqual(a, b) && bytes.Equal(aCfg, bCfg)
+}
+
+// unload fades out and closes a module, removing its element.
+func (r *Runtime) unload(state *moduleState) {
+	_ = r.sched.Unschedule(state.desc.InstanceID())
+	r.unwatchModule(state.desc.InstanceID())
+
+	r.mu.Lock()
+	mod, uiCtx := state.mod, state.ui
+	state.running = false
+	state.mod = nil
+	for i, m := range r.mods {
+		if m == mod {
+			r.mods = append(r.mods[:i], r.mods[i+1:]...)
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if uiCtx != nil {
+		if err := uiCtx.fade(0); err != nil {
+			r.log.Error("could not fade out module", logCtx.Str("module", state.desc.InstanceID()), logCtx.Error("error", err))
+		}
+	}
+	if mod != nil {
+		if err := mod.Close(); err != nil {
+			r.log.Error("could not close module", logCtx.Str("module", state.desc.InstanceID()), logCtx.Error("error", err))
+		}
+	}
+	if uiCtx != nil {
+		if err := uiCtx.Close(); err != nil {
+			r.log.Error("could not remove module", logCtx.Str("module", state.desc.InstanceID()), logCtx.Error("error", err))
+		}
+	}
+	r.log.Info("module unloaded", logCtx.Str("module", state.desc.InstanceID()))
+}
+
+// load loads the given modules, fading them in if required.
+//
+// Modules are initialised concurrently, while their elements are created in
+// their configured order. Module errors do not stop the other modules from
+// loading, they are returned together once all modules have loaded.
+func (r *Runtime) load(ctx context.Context, states []*moduleState, fadeIn bool) error {
+	whenCtx := r.whenContext()
+	var active []*moduleState
+	for _, state := range states {
+		if !state.desc.IsEnabled() {
+			r.log.Info("module is disabled", logCtx.Str("module", state.desc.InstanceID()))
+			r.mu.Lock()
+			state.disabled = true
+			r.mu.Unlock()
+			continue
+		}
+		if state.desc.When != "" {
+			e, err := expr.Parse(state.desc.When)
+			if err != nil {
+				return fmt.Errorf("%s: invalid when expression: %w", state.desc.InstanceID(), err)
+			}
+			if !e.Eval(whenCtx) {
+				r.log.Info("skipping module", logCtx.Str("module", state.desc.InstanceID()), logCtx.Str("when", state.desc.When))
+				r.mu.Lock()
+				state.skipped = true
+				r.mu.Unlock()
+				continue
+			}
+		}
+		active = append(active, state)
+	}
+
+	if err := r.checkOverlaps(active); err != nil {
+		return err
+	}
+
+	var errs []error
+	extracted := make([]*moduleState, 0, len(active))
+	descs := make([]module.Descriptor, 0, len(active))
+	for _, state := range active {
+		if err := r.svc.Extract(state.desc); err != nil {
+			r.fail(state, err)
+			errs = append(errs, err)
+			continue
+		}
+		extracted = append(extracted, state)
+		descs = append(descs, state.desc)
+	}
+
+	if len(extracted) == 0 {
+		return moduleErrors(errs)
+	}
+	uiCtxs, uiErrs, err := newUIContexts(r.ui, descs)
+	if err != nil {
+		for _, state := range extracted {
+			r.fail(state, err)
+		}
+		return moduleErrors(append(errs, err))
+	}
+	created := make([]*moduleState, 0, len(extracted))
+	for i, state := range extracted {
+		if uiErrs[i] != nil {
+			r.fail(state, uiErrs[i])
+			errs = append(errs, uiErrs[i])
+			continue
+		}
+
+		uiCtxs[i].log = r.moduleLogger(state.desc)
+		uiCtxs[i].bus = r.bus
+		uiCtxs[i].reg = r.reg
+		uiCtxs[i].http = r.http
+		uiCtxs[i].log.Debug("module created")
+		r.loadFonts(uiCtxs[i], state.desc)
+
+		r.mu.Lock()
+		state.ui = uiCtxs[i]
+		r.mu.Unlock()
+		created = append(created, state)
+	}
+
+	if r.cfg.Layout.Enabled() {
+		if fadeIn {
+			err = r.applyCurrentLayout()
+		} else {
+			err = r.applyLayout()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The module elements are already in their configured order, so the
+	// modules can be initialised concurrently.
+	errs = append(errs, r.runAll(ctx, created, fadeIn)...)
+	return moduleErrors(errs)
+}
+
+func (r *Runtime) fail(state *moduleState, err error) {
+	r.moduleLogger(state.desc).Error("module failed", logCtx.Error("error", err))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.err = err
+}
+
+// moduleLogger returns a logger with the context of the module.
+func (r *Runtime) moduleLogger(desc module.Descriptor) *logger.Logger {
+	fields := []logger.Field{logCtx.Str("module", desc.InstanceID())}
+	if desc.Area != "" {
+		fields = append(fields, logCtx.Str("area", desc.Area))
+	} else {
+		fields = append(fields, logCtx.Str("position", desc.Position.String()))
+	}
+	return r.log.With(fields...)
+}
+
+// scriptError records an error thrown by a module script.
+func (r *Runtime) scriptError(name, msg string) {
+	r.log.Error("module script error", logCtx.Str("module", name), logCtx.Str("error", msg))
+
+	state, err := r.moduleState(name)
+	if err != nil {
+		return
+	}
+	r.fail(state, fmt.Errorf("script error: %s", msg))
+}
+
+func (r *Runtime) run(ctx context.Context, state *moduleState) error {
+	mod, panicked, err := r.retryModule(ctx, state)
+	if panicked {
+		// The module has been isolated, the rest of the modules keep loading.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	state.running = true
+	state.mod = mod
+	r.mods = append(r.mods, mod)
+	r.mu.Unlock()
+
+	r.reg.add(state.ui.name)
+	state.ui.logger().Info("module loaded")
+
+	r.watchModule(ctx, state)
+
+	if ref, ok := mod.(types.Refresher); ok {
+		r.sched.Schedule(ctx, state.desc.InstanceID(), state.desc.Refresh, r.refreshFunc(state.desc.InstanceID(), ref))
+		r.sched.SetBudget(state.desc.InstanceID(), state.desc.RefreshBudget)
+	}
+	return nil
+}
+
+// retryModule runs the module, retrying with an exponential backoff
+// on error until its attempts are exhausted or the context is done.
+func (r *Runtime) retryModule(ctx context.Context, state *moduleState) (io.Closer, bool, error) {
+	attempts := state.desc.Retry.MaxAttempts()
+	delay := state.desc.Retry.BaseDelay()
+	for attempt := 1; ; attempt++ {
+		mod, panicked, err := r.runModule(ctx, state)
+		if err == nil || panicked || attempt >= attempts {
+			return mod, panicked, err
+		}
+
+		r.log.Warn("could not run module, retrying",
+			logCtx.Str("module", state.desc.InstanceID()),
+			logCtx.Int("attempt", attempt),
+			logCtx.Duration("delay", delay),
+			logCtx.Error("error", err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false, err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+func (r *Runtime) runModule(ctx context.Context, state *moduleState) (mod io.Closer, panicked bool, err error) {
+	defer r.recoverModule(state.desc.InstanceID(), &panicked)
+
+	mod, err = r.svc.Run(ctx, state.desc, state.ui, state.ui.Logger())
+	return mod, false, err
+}
+
+// refreshFunc returns the function refreshing a module.
+func (r *Runtime) refreshFunc(name string, ref types.Refresher) RefreshFunc {
+	return func(ctx context.Context) (err error) {
+		start := time.Now()
+		defer func() {
+			r.metrics.observeRefresh(name, time.Since(start), err)
+		}()
+
+		var panicked bool
+		defer func() {
+			if panicked {
+				err = errors.New("refresh panicked")
+			}
+		}()
+		defer r.recoverModule(name, &panicked)
+
+		if err := ref.Refresh(ctx); err != nil {
+			r.log.Error("could not refresh module", logCtx.Str("module", name), logCtx.Error("error", err))
+			return err
+		}
+		r.log.Debug("module refreshed", logCtx.Str("module", name))
+		r.markRefreshed(name)
+		return nil
+	}
+}
+
+// markRefreshed records a successful refresh of the module.
+func (r *Runtime) markRefreshed(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.states {
+		if state.desc.InstanceID() == name {
+			state.refreshed = time.Now()
+			return
+		}
+	}
+}
+
+// loadFonts loads the fonts declared by the module. Fonts that cannot be loaded
+// are logged, the module falls back to the other fonts in its css.
+func (r *Runtime) loadFonts(uiCtx *UIContext, desc module.Descriptor) {
+	var dir string
+	if direr, ok := r.svc.(moduleDirer); ok {
+		dir = direr.Dir(desc)
+	}
+	for _, font := range desc.Fonts {
+		if err := r.ui.LoadFont(font, dir); err != nil {
+			uiCtx.log.Error("could not load font", logCtx.Error("error", err))
+		}
+	}
+}
+
+// checkOverlaps reports modules sharing the same position, unless they allow
+// overlapping. Overlaps are logged, or returned as an error listing every
+// overlapping pair when the loader is strict.
+//
+// The modules are checked against the running modules as well, though only
+// positions shared with one of the given modules are reported.
+func (r *Runtime) checkOverlaps(states []*moduleState) error {
+	loading := make(map[string]bool, len(states))
+	for _, state := range states {
+		loading[state.desc.InstanceID()] = true
+	}
+
+	r.mu.Lock()
+	strict := r.cfg.Loader.StrictPositions
+	descs := make([]module.Descriptor, 0, len(r.states)+len(states))
+	for _, state := range r.states {
+		if state.running && !loading[state.desc.InstanceID()] {
+			descs = append(descs, state.desc)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, state := range states {
+		descs = append(descs, state.desc)
+	}
+	positions, names := positionOverlaps(descs)
+	shared := positions[:0]
+	for _, pos := range positions {
+		if !anyOf(names[pos], loading) {
+			delete(names, pos)
+			continue
+		}
+		shared = append(shared, pos)
+	}
+	positions = shared
+
+	if strict {
+		return overlapError(positions, names)
+	}
+
+	for _, pos := range positions {
+		r.log.Warn("modules share the same position and may overlap",
+			logCtx.Str("position", pos.String()),
+			logCtx.Strs("modules", names[pos]),
+		)
+	}
+	return nil
+}
+
+// anyOf determines if any of the names is set in set.
+func anyOf(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if set[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// positionOverlaps returns the positions shared by modules, in the order
+// they are first used, with the names of the modules sharing them.
+//
+// Modules placed in an area or allowing overlaps are ignored.
+func positionOverlaps(descs []module.Descriptor) ([]module.Position, map[module.Position][]string) {
+	var positions []module.Position
+	names := map[module.Position][]string{}
+	for _, desc := range descs {
+		if desc.Area != "" || desc.AllowOverlap {
+			continue
+		}
+		if _, ok := names[desc.Position]; !ok {
+			positions = append(positions, desc.Position)
+		}
+		names[desc.Position] = append(names[desc.Position], desc.InstanceID())
+	}
+
+	shared := positions[:0]
+	for _, pos := range positions {
+		if len(names[pos]) < 2 {
+			delete(names, pos)
+			continue
+		}
+		shared = append(shared, pos)
+	}
+	return shared, names
+}
+
+// overlapError returns an error listing every pair of modules sharing a position.
+func overlapError(positions []module.Position, names map[module.Position][]string) error {
+	var pairs []string
+	for _, pos := range positions {
+		mods := names[pos]
+		for i := range mods {
+			for _, other := range mods[i+1:] {
+				pairs = append(pairs, fmt.Sprintf("%s and %s at %s", mods[i], other, pos))
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("modules share the same position: %s", strings.Join(pairs, "; "))
+}
+
+// whenContext returns the context module when expressions are evaluated against.
+func (r *Runtime) whenContext() map[string]interface{} {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+
+	return map[string]interface{}{
+		"features": r.cfg.Features,
+		"vars":     r.cfg.Vars,
+		"env":      env,
+	}
+}
+
+// Close closes the running modules.
+func (r *Runtime) Close() error {
+	r.stopWatching()
+	r.stopThemes()
+	r.stopBackground()
+	r.stopNetworkMonitor()
+	r.stopBlank()
+	if r.cfg.Activity.Enabled {
+		r.stopActivity()
+	}
+	r.sched.Stop()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.mods) - 1; i >= 0; i-- {
+		_ = r.mods[i].Close()
+	}
+	r.mods = nil
+	for _, state := range r.states {
+		state.running = false
+	}
+
+	r.stopKeepAwake()
+	return nil
+}
@@ -0,0 +1,1110 @@
+package glass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/hamba/logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_Load(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	mod := &MockModule{}
+	mod.On("Close").Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.AnythingOfType("*glass.UIContext"), mock.Anything).Return(mod, nil)
+
+	cfg := Config{Modules: []module.Descriptor{desc}}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	err = rt.Close()
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+	svc.AssertExpectations(t)
+	mod.AssertExpectations(t)
+}
+
+func TestRuntime_LoadCreatesModulesInBatch(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"right"},{"name":"weather","vert":"top","horiz":"left"}]);`).
+		Return(NewValue(`{"clock":"","weather":""}`, nil)).Once()
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+	win.AssertNumberOfCalls(t, "Eval", 1)
+	svc.AssertNumberOfCalls(t, "Run", 2)
+}
+
+func TestRuntime_LoadFailsEachModuleWithItsOwnError(t *testing.T) {
+	win := NewRecordingWindow()
+	_ = win.OnEval(`createModules([{"name":"clock","vert":"top","horiz":"right"},{"name":"weather","vert":"top","horiz":"left"},{"name":"news","vert":"bottom","horiz":"left"}]);`,
+		map[string]string{"clock": "clock error", "weather": "weather error", "news": ""}, nil)
+	ui := &UI{win: win}
+
+	descs := []module.Descriptor{
+		{Name: "clock", Path: "clock", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}},
+		{Name: "weather", Path: "weather", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}},
+		{Name: "news", Path: "news", Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left}},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, descs[2], mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: descs}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	var merrs ModuleErrors
+	require.ErrorAs(t, err, &merrs)
+	assert.Len(t, merrs, 2)
+	assert.EqualError(t, merrs[0], "clock: could not create module ui element: clock error")
+	assert.EqualError(t, merrs[1], "weather: could not create module ui element: weather error")
+	state := rt.RuntimeState()
+	assert.Equal(t, "clock: could not create module ui element: clock error", state[0].LastError)
+	assert.Equal(t, "weather: could not create module ui element: weather error", state[1].LastError)
+	assert.Equal(t, ModuleRunning, rt.ModuleStatus("news"))
+	svc.AssertNumberOfCalls(t, "Run", 1)
+}
+
+func TestRuntime_LoadPlacesModulesInGrid(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"left","grid":{"columns":12,"column":1,"span":6,"row":1}},{"name":"weather","vert":"top","horiz":"left","grid":{"columns":12,"column":7,"span":6,"row":1}},{"name":"news","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"clock":"","weather":"","news":""}`, nil)).Once()
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+		Grid:     &module.GridPlacement{Column: 1, Span: 6},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+		Grid:     &module.GridPlacement{Column: 7, Span: 6, Row: 1},
+	}
+	news := module.Descriptor{
+		Name:     "news",
+		Path:     "news",
+		Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather, news}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestRuntime_LoadRetriesModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+		Retry:    module.Retry{Attempts: 3, Delay: time.Millisecond},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Twice().Return(nil, errors.New("network is down"))
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Once().Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	svc.AssertNumberOfCalls(t, "Run", 3)
+	assert.Equal(t, ModuleRunning, rt.RuntimeState()[0].Status)
+}
+
+func TestRuntime_LoadFailsModuleAfterRetries(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+		Retry:    module.Retry{Attempts: 2, Delay: time.Millisecond},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(nil, errors.New("network is down"))
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	assert.EqualError(t, err, "network is down")
+	svc.AssertNumberOfCalls(t, "Run", 2)
+	state := rt.RuntimeState()[0]
+	assert.Equal(t, ModuleFailed, state.Status)
+	assert.Equal(t, "network is down", state.LastError)
+}
+
+func TestRuntime_LoadStopsRetryingOnCancel(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+		Retry:    module.Retry{Attempts: 5, Delay: time.Hour},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(nil, errors.New("network is down"))
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+
+	done := make(chan error, 1)
+	go func() { done <- rt.Load(ctx) }()
+
+	select {
+	case err := <-done:
+		assert.EqualError(t, err, "network is down")
+	case <-time.After(time.Second):
+		require.Fail(t, "load did not stop retrying")
+	}
+	svc.AssertNumberOfCalls(t, "Run", 1)
+}
+
+func TestRuntime_LoadTagsModuleLogs(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"good","vert":"top","horiz":"right"},{"name":"bad","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"good":"","bad":""}`, nil))
+	ui := &UI{win: win}
+
+	good := module.Descriptor{
+		Name:     "good",
+		Path:     "good-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	bad := module.Descriptor{
+		Name:     "bad",
+		Path:     "bad-module",
+		Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, good, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(3).(types.Logger).Info("fetching weather")
+	}).Return(&MockModule{}, nil)
+	svc.On("Run", mock.Anything, bad, mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+	rt := NewRuntime(Config{Modules: []module.Descriptor{good, bad}}, ui, svc, log)
+
+	err := rt.Load(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), `msg="fetching weather" module=good position=top:right`)
+	assert.Contains(t, buf.String(), `msg="module loaded" module=good position=top:right`)
+	assert.Contains(t, buf.String(), `msg="module failed" module=bad position=bottom:left error="test error"`)
+}
+
+func TestRuntime_LoadHandlesModuleCreateError(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"right"},{"name":"weather","vert":"top","horiz":"left"}]);`).
+		Return(NewValue(`{"clock":"","weather":"TypeError: cont is null"}`, nil))
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, clock, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	assert.EqualError(t, err, "weather: could not create module ui element: TypeError: cont is null")
+	state := rt.RuntimeState()
+	assert.Empty(t, state[0].LastError)
+	assert.True(t, state[0].Healthy)
+	assert.Equal(t, "weather: could not create module ui element: TypeError: cont is null", state[1].LastError)
+	svc.AssertNotCalled(t, "Run", mock.Anything, weather, mock.Anything, mock.Anything)
+}
+
+func TestRuntime_LoadCollectsModuleErrors(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"right"},{"name":"weather","vert":"top","horiz":"left"},{"name":"news","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"clock":"","weather":"","news":""}`, nil))
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+	}
+	news := module.Descriptor{
+		Name:     "news",
+		Path:     "news",
+		Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, clock, mock.Anything, mock.Anything).Return(nil, errors.New("clock error"))
+	svc.On("Run", mock.Anything, weather, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+	svc.On("Run", mock.Anything, news, mock.Anything, mock.Anything).Return(nil, errors.New("news error"))
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather, news}}, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	assert.EqualError(t, err, "clock error; news error")
+	var merrs ModuleErrors
+	require.True(t, errors.As(err, &merrs))
+	assert.Len(t, merrs, 2)
+	state := rt.RuntimeState()
+	assert.Equal(t, "clock error", state[0].LastError)
+	assert.True(t, state[1].Healthy)
+	assert.Equal(t, "news error", state[2].LastError)
+	svc.AssertExpectations(t)
+}
+
+func TestRuntime_LoadRunsModulesConcurrently(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"a","vert":"top","horiz":"left"},{"name":"b","vert":"top","horiz":"right"},{"name":"c","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"a":"","b":"","c":""}`, nil))
+	ui := &UI{win: win}
+
+	descs := []module.Descriptor{
+		{Name: "a", Path: "a", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}},
+		{Name: "b", Path: "b", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}},
+		{Name: "c", Path: "c", Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left}},
+	}
+	var running, peak int32
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}).Return(&MockModule{}, nil)
+
+	cfg := Config{Loader: LoaderConfig{Concurrency: 2}, Modules: descs}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+	svc.AssertNumberOfCalls(t, "Run", 3)
+}
+
+func TestRuntime_LoadRunsDependenciesFirst(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"commute","vert":"top","horiz":"left"},{"name":"weather","vert":"top","horiz":"right"},{"name":"clock","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"commute":"","weather":"","clock":""}`, nil))
+	ui := &UI{win: win}
+
+	descs := []module.Descriptor{
+		{Name: "commute", Path: "commute", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}, DependsOn: []string{"weather"}},
+		{Name: "weather", Path: "weather", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}},
+		{Name: "clock", Path: "clock", Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left}},
+	}
+	var mu sync.Mutex
+	var order []string
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		desc := args.Get(1).(module.Descriptor)
+		if desc.Name == "weather" {
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, desc.Name)
+	}).Return(&MockModule{}, nil)
+
+	cfg := Config{Loader: LoaderConfig{Concurrency: 3}, Modules: descs}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clock", "weather", "commute"}, order)
+}
+
+func TestRuntime_LoadResolvesDependencyNames(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow()}
+
+	descs := []module.Descriptor{
+		{Name: "commute", Path: "commute", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}, DependsOn: []string{"weather"}},
+		{Name: "weather", ID: "weather-home", Path: "weather", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}},
+	}
+	var mu sync.Mutex
+	var order []string
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		desc := args.Get(1).(module.Descriptor)
+		if desc.Name == "weather" {
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, desc.InstanceID())
+	}).Return(&MockModule{}, nil)
+
+	cfg := Config{Loader: LoaderConfig{Concurrency: 2}, Modules: descs}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"weather-home", "commute"}, order)
+}
+
+func TestRuntime_LoadWaitsForNetwork(t *testing.T) {
+	var probes int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&probes, 1) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Run(func(mock.Arguments) {
+		assert.Equal(t, int32(3), atomic.LoadInt32(&probes))
+	}).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	cfg := Config{
+		Network: NetworkConfig{
+			ProbeURL:       srv.URL,
+			Timeout:        time.Second,
+			Interval:       time.Millisecond,
+			WaitForNetwork: true,
+			MaxWait:        10 * time.Second,
+		},
+		Modules: []module.Descriptor{desc},
+	}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&probes))
+	svc.AssertExpectations(t)
+}
+
+func TestRuntime_LoadContinuesWithoutNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	cfg := Config{
+		Network: NetworkConfig{
+			ProbeURL:       srv.URL,
+			Timeout:        time.Second,
+			Interval:       time.Millisecond,
+			WaitForNetwork: true,
+			MaxWait:        20 * time.Millisecond,
+		},
+		Modules: []module.Descriptor{desc},
+	}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	svc.AssertExpectations(t)
+}
+
+func TestRuntime_NetworkMonitorPublishesChanges(t *testing.T) {
+	var down int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	win := NewRecordingWindow()
+	cfg := Config{
+		Network: NetworkConfig{
+			ProbeURL:        srv.URL,
+			Timeout:         time.Second,
+			Monitor:         true,
+			MonitorInterval: time.Millisecond,
+		},
+	}
+	rt := NewRuntime(cfg, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	t.Cleanup(rt.stopNetworkMonitor)
+
+	got := make(chan json.RawMessage, 2)
+	unsub := rt.Events().Subscribe(NetworkTopic, func(e Event) {
+		got <- e.Data
+	})
+	defer unsub()
+
+	rt.startNetworkMonitor(context.Background())
+
+	assert.JSONEq(t, `{"online":false}`, string(<-got))
+	assert.False(t, rt.Online())
+	assert.Equal(t, []string{`setNetworkOffline(true, "Offline");`}, rt.ui.setup)
+
+	atomic.StoreInt32(&down, 0)
+
+	assert.JSONEq(t, `{"online":true}`, string(<-got))
+	assert.True(t, rt.Online())
+	assert.Contains(t, win.Evals(), `setNetworkOffline(false, "Offline");`)
+	assert.Empty(t, rt.ui.setup)
+}
+
+func TestRuntime_NetworkMonitorIsDisabledByDefault(t *testing.T) {
+	win := NewRecordingWindow()
+	rt := NewRuntime(Config{}, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+
+	rt.startNetworkMonitor(context.Background())
+
+	assert.True(t, rt.Online())
+	assert.Empty(t, win.Evals())
+}
+
+func TestRuntime_KeepsDisplayAwake(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	awake := &MockKeepAwaker{}
+	awake.On("Start").Return(nil).Once()
+	awake.On("Stop").Return(nil).Once()
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Run(func(mock.Arguments) {
+		awake.AssertCalled(t, "Start")
+	}).Return(nil)
+	mod := &MockModule{}
+	mod.On("Close").Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(mod, nil)
+
+	cfg := Config{UI: UIConfig{PreventSleep: true}, Modules: []module.Descriptor{desc}}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+	rt.awake = awake
+
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+	awake.AssertNotCalled(t, "Stop")
+
+	err = rt.Close()
+
+	require.NoError(t, err)
+	awake.AssertExpectations(t)
+}
+
+func newTestLogger() *logger.Logger {
+	return logger.New(io.Discard, logger.LogfmtFormat(), logger.Info)
+}
+
+type MockModuleRunner struct {
+	mock.Mock
+}
+
+func (m *MockModuleRunner) Extract(desc module.Descriptor) error {
+	args := m.Called(desc)
+	return args.Error(0)
+}
+
+func (m *MockModuleRunner) Run(ctx context.Context, desc module.Descriptor, ui types.UI, log types.Logger) (io.Closer, error) {
+	args := m.Called(ctx, desc, ui, log)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.Closer), args.Error(1)
+}
+
+type MockModule struct {
+	mock.Mock
+}
+
+func (m *MockModule) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockRefreshModule struct {
+	MockModule
+}
+
+func (m *MockRefreshModule) Refresh(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestRuntime_RefreshesModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+		Refresh:  30 * time.Second,
+	}
+	refreshed := make(chan struct{})
+	mod := &MockRefreshModule{}
+	mod.On("Refresh", mock.Anything).Run(func(mock.Arguments) {
+		refreshed <- struct{}{}
+	}).Return(nil)
+	mod.On("Close").Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.MatchedBy(func(ui types.UI) bool {
+		return ui.RefreshInterval() == 30*time.Second
+	}), mock.Anything).Return(mod, nil)
+
+	clock := newFakeClock()
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+	rt.sched.after = clock.After
+
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 30*time.Second, clock.Tick(t))
+	<-refreshed
+	go func() { <-clock.waits }()
+
+	err = rt.Close()
+
+	require.NoError(t, err)
+	svc.AssertExpectations(t)
+	mod.AssertExpectations(t)
+}
+
+func TestRuntime_RuntimeState(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"good","vert":"top","horiz":"right"},{"name":"bad","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"good":"","bad":""}`, nil))
+	win.On("Eval", "loadModuleHTML(`good`, `<p>hello</p>`);").Return(emptyVal)
+	ui := &UI{win: win}
+
+	good := module.Descriptor{
+		Name:     "good",
+		Path:     "good-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	bad := module.Descriptor{
+		Name:     "bad",
+		Path:     "bad-module",
+		Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, good, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		err := args.Get(2).(types.UI).LoadHTML("<p>hello</p>")
+		require.NoError(t, err)
+	}).Return(&MockModule{}, nil)
+	svc.On("Run", mock.Anything, bad, mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
+
+	cfg := Config{Modules: []module.Descriptor{good, bad}}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+	require.Error(t, err)
+
+	got := rt.RuntimeState()
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "good", got[0].Name)
+	assert.Equal(t, "top:right", got[0].Position)
+	assert.True(t, got[0].Enabled)
+	assert.True(t, got[0].Healthy)
+	assert.Empty(t, got[0].LastError)
+	assert.False(t, got[0].LastRenderTime.IsZero())
+	assert.Equal(t, "bad", got[1].Name)
+	assert.Equal(t, "bottom:left", got[1].Position)
+	assert.True(t, got[1].Enabled)
+	assert.False(t, got[1].Healthy)
+	assert.Equal(t, "test error", got[1].LastError)
+	assert.True(t, got[1].LastRenderTime.IsZero())
+}
+
+func TestNewServer_State(t *testing.T) {
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, &UI{}, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/state")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var got []ModuleState
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	require.NoError(t, err)
+	assert.Equal(t, []ModuleState{{Name: "test", Position: "top:right", Enabled: true, Status: ModulePending}}, got)
+}
+
+func TestRuntime_RecordsRefreshTime(t *testing.T) {
+	desc := module.Descriptor{Name: "test", Path: "test-module"}
+	mod := &MockRefreshModule{}
+	mod.On("Refresh", mock.Anything).Once().Return(errors.New("test error"))
+	mod.On("Refresh", mock.Anything).Once().Return(nil)
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, &UI{}, &MockModuleRunner{}, newTestLogger())
+	refresh := rt.refreshFunc("test", mod)
+
+	err := refresh(context.Background())
+	require.Error(t, err)
+	assert.True(t, rt.RuntimeState()[0].LastRefreshTime.IsZero())
+
+	start := time.Now()
+	err = refresh(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, rt.RuntimeState()[0].LastRefreshTime.Before(start))
+}
+
+func TestNewServer_Health(t *testing.T) {
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, &UI{}, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/health")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var got Health
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	require.NoError(t, err)
+	want := Health{
+		Status:  HealthOK,
+		Modules: []ModuleState{{Name: "test", Position: "top:right", Enabled: true, Status: ModulePending}},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestNewServer_HealthReportsClosedUI(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+	win := &MockLorcaUI{}
+	win.On("Done").Return(done)
+	rt := NewRuntime(Config{}, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/health")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	var got Health
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	require.NoError(t, err)
+	assert.Equal(t, HealthUnavailable, got.Status)
+}
+
+func TestRuntime_LoadSkipsModulesWhenFalse(t *testing.T) {
+	t.Setenv("GLASS_TEST_ROOM", "kitchen")
+
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"kitchen","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"kitchen":""}`, nil))
+	ui := &UI{win: win}
+
+	kitchen := module.Descriptor{
+		Name:     "kitchen",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+		When:     `features.foo && env.GLASS_TEST_ROOM == "kitchen"`,
+	}
+	bedroom := module.Descriptor{
+		Name:     "bedroom",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+		When:     `features.foo && env.GLASS_TEST_ROOM == "bedroom"`,
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", kitchen).Return(nil)
+	svc.On("Run", mock.Anything, kitchen, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	cfg := Config{
+		Features: map[string]bool{"foo": true},
+		Modules:  []module.Descriptor{kitchen, bedroom},
+	}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	svc.AssertExpectations(t)
+	svc.AssertNotCalled(t, "Extract", bedroom)
+	state := rt.RuntimeState()
+	require.Len(t, state, 2)
+	assert.True(t, state[0].Enabled)
+	assert.False(t, state[1].Enabled)
+}
+
+func TestNewServer_Bindings(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+	err := uiCtx.Bind("toggle", func() {})
+	require.NoError(t, err)
+	rt := NewRuntime(Config{}, uiCtx.ui, &MockModuleRunner{}, newTestLogger())
+	srv := httptest.NewServer(NewServer(rt))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/bindings")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var got map[string][]BindingInfo
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]BindingInfo{"test": {{Name: "toggle"}}}, got)
+}
+
+func TestRuntime_LoadWarnsAboutOverlappingModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", mock.Anything).Return(NewValue(`{"clock":"","date":"","weather":""}`, nil))
+	ui := &UI{win: win}
+
+	pos := module.Position{Vertical: module.Top, Horizontal: module.Left}
+	descs := []module.Descriptor{
+		{Name: "clock", Path: "clock", Position: pos},
+		{Name: "date", Path: "date", Position: pos},
+		{Name: "weather", Path: "weather", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+	rt := NewRuntime(Config{Modules: descs}, ui, svc, log)
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(buf.String(), "lvl=warn"))
+	assert.Contains(t, buf.String(), `msg="modules share the same position and may overlap" position=top:left modules=clock,date`)
+}
+
+func TestRuntime_LoadErrorsOnOverlappingModulesWhenStrict(t *testing.T) {
+	win := &MockLorcaUI{}
+	ui := &UI{win: win}
+
+	left := module.Position{Vertical: module.Top, Horizontal: module.Left}
+	right := module.Position{Vertical: module.Top, Horizontal: module.Right}
+	descs := []module.Descriptor{
+		{Name: "clock", Path: "clock", Position: left},
+		{Name: "date", Path: "date", Position: left},
+		{Name: "weather", Path: "weather", Position: right},
+		{Name: "calendar", Path: "calendar", Position: left},
+		{Name: "news", Path: "news", Position: right},
+	}
+	svc := &MockModuleRunner{}
+
+	cfg := Config{Loader: LoaderConfig{StrictPositions: true}, Modules: descs}
+	rt := NewRuntime(cfg, ui, svc, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	assert.EqualError(t, err, "modules share the same position: "+
+		"clock and date at top:left; clock and calendar at top:left; date and calendar at top:left; "+
+		"weather and news at top:right")
+	win.AssertNotCalled(t, "Eval", mock.Anything)
+	svc.AssertNotCalled(t, "Extract", mock.Anything)
+}
+
+func TestRuntime_ReloadChecksOverlapsWithRunningModules(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	pos := module.Position{Vertical: module.Top, Horizontal: module.Left}
+	clock := module.Descriptor{Name: "clock", Path: "clock", Position: pos}
+	date := module.Descriptor{Name: "date", Path: "date", Position: pos}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	cfg := Config{Loader: LoaderConfig{StrictPositions: true}, Modules: []module.Descriptor{clock}}
+	rt := NewRuntime(cfg, uiCtx.ui, svc, newTestLogger())
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+	evals := len(win.Evals())
+
+	cfg.Modules = []module.Descriptor{clock, date}
+	err = rt.Reload(context.Background(), cfg)
+
+	assert.EqualError(t, err, "modules share the same position: clock and date at top:left")
+	assert.Len(t, win.Evals(), evals)
+	svc.AssertNotCalled(t, "Extract", date)
+}
+
+func TestRuntime_LoadAllowsOverlappingModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", mock.Anything).Return(NewValue(`{"clock":"","date":""}`, nil))
+	ui := &UI{win: win}
+
+	pos := module.Position{Vertical: module.Top, Horizontal: module.Left}
+	descs := []module.Descriptor{
+		{Name: "clock", Path: "clock", Position: pos},
+		{Name: "date", Path: "date", Position: pos, AllowOverlap: true},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+	cfg := Config{Loader: LoaderConfig{StrictPositions: true}, Modules: descs}
+	rt := NewRuntime(cfg, ui, svc, log)
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "lvl=warn")
+}
+
+type MockKeepAwaker struct {
+	mock.Mock
+}
+
+func (m *MockKeepAwaker) Start() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockKeepAwaker) Stop() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestRuntime_RecordsModuleScriptErrors(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"right"},{"name":"weather","vert":"top","horiz":"left"}]);`).
+		Return(NewValue(`{"clock":"","weather":""}`, nil))
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather}}, ui, svc, log)
+
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	// Simulate the page reporting a throwing clock script.
+	ui.reportScriptError("clock", "ReferenceError: tick is not defined")
+
+	states := rt.RuntimeState()
+	require.Len(t, states, 2)
+	assert.False(t, states[0].Healthy)
+	assert.Equal(t, "script error: ReferenceError: tick is not defined", states[0].LastError)
+	assert.True(t, states[1].Healthy)
+	assert.Contains(t, buf.String(), `msg="module script error" module=clock`)
+}
+
+func TestRuntime_ReloadFadesOutRemovedModules(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"clock","vert":"top","horiz":"right"},{"name":"weather","vert":"top","horiz":"left"}]);`).
+		Return(NewValue(`{"clock":"","weather":""}`, nil))
+	win.On("Eval", `fadeModule("weather", 0, 500);`).Once().Return(NewValue("", nil))
+	win.On("Eval", `removeModule("weather");`).Once().Return(NewValue("", nil))
+	win.On("Eval", `createModules([{"name":"news","vert":"bottom","horiz":"left"}]);`).
+		Return(NewValue(`{"news":""}`, nil))
+	win.On("Eval", `fadeModule("news", 1, 500);`).Once().Return(NewValue("", nil))
+	ui := &UI{win: win}
+
+	clock := module.Descriptor{
+		Name:     "clock",
+		Path:     "clock",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	weather := module.Descriptor{
+		Name:     "weather",
+		Path:     "weather",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Left},
+	}
+	news := module.Descriptor{
+		Name:     "news",
+		Path:     "news",
+		Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left},
+	}
+	clockMod := &MockModule{}
+	weatherMod := &MockModule{}
+	weatherMod.On("Close").Once().Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", mock.Anything).Return(nil)
+	svc.On("Run", mock.Anything, clock, mock.Anything, mock.Anything).Once().Return(clockMod, nil)
+	svc.On("Run", mock.Anything, weather, mock.Anything, mock.Anything).Once().Return(weatherMod, nil)
+	svc.On("Run", mock.Anything, news, mock.Anything, mock.Anything).Once().Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{clock, weather}}, ui, svc, newTestLogger())
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	err = rt.Reload(context.Background(), Config{Modules: []module.Descriptor{clock, news}})
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+	svc.AssertExpectations(t)
+	weatherMod.AssertExpectations(t)
+	clockMod.AssertNotCalled(t, "Close")
+
+	var evals []string
+	for _, call := range win.Calls {
+		evals = append(evals, call.Arguments.String(0))
+	}
+	fadeIdx := indexOf(evals, `fadeModule("weather", 0, 500);`)
+	removeIdx := indexOf(evals, `removeModule("weather");`)
+	assert.True(t, fadeIdx >= 0 && fadeIdx < removeIdx, "expected fade out before removal")
+
+	states := rt.RuntimeState()
+	require.Len(t, states, 2)
+	assert.Equal(t, "clock", states[0].Name)
+	assert.Equal(t, "news", states[1].Name)
+}
+
+func indexOf(s []string, v string) int {
+	for i, str := range s {
+		if str == v {
+			return i
+		}
+	}
+	return -1
+}
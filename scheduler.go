@@ -0,0 +1,272 @@
+package glass
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBackoff is the default maximum refresh interval after errors.
+const DefaultMaxBackoff = 30 * time.Minute
+
+// Refresh budget enforcement.
+const (
+	// budgetOverruns is the number of consecutive refreshes over budget
+	// after which the refresh interval is backed off.
+	budgetOverruns = 3
+	// budgetQuarantine is the multiple of the budget a single refresh
+	// may take before the module is no longer refreshed.
+	budgetQuarantine = 10
+)
+
+// RefreshFunc refreshes a module.
+type RefreshFunc func(ctx context.Context) error
+
+// Scheduler runs module refreshes on their intervals.
+//
+// The next interval starts once a refresh has completed, so ticks
+// falling within a slow refresh are skipped rather than queued.
+//
+// When a refresh fails, the next interval of the module is doubled,
+// up to the maximum backoff. The interval is reset on the next success.
+//
+// Modules with a budget are backed off the same way while their refreshes
+// consistently take longer than the budget. A module with a refresh taking
+// far longer than its budget is no longer refreshed.
+type Scheduler struct {
+	maxBackoff time.Duration
+	after      func(time.Duration) <-chan time.Time
+	since      func(time.Time) time.Duration
+
+	mu         sync.Mutex
+	jobs       map[string]*refreshJob
+	overrunFns []OverrunHandler
+	wg         sync.WaitGroup
+}
+
+type refreshJob struct {
+	base     time.Duration
+	next     time.Duration
+	budget   time.Duration
+	overruns int
+	fn       RefreshFunc
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Overrun describes a module refresh that took longer than its budget.
+type Overrun struct {
+	Module string
+	Took   time.Duration
+	Budget time.Duration
+	// Overruns is the number of consecutive refreshes over budget.
+	Overruns int
+	// Interval is the next refresh interval of the module.
+	Interval time.Duration
+	// Quarantined is true if the module is no longer refreshed.
+	Quarantined bool
+}
+
+// OverrunHandler handles a module refresh that took longer than its budget.
+type OverrunHandler func(o Overrun)
+
+// NewScheduler returns a scheduler with the given maximum backoff.
+func NewScheduler(maxBackoff time.Duration) *Scheduler {
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	return &Scheduler{
+		maxBackoff: maxBackoff,
+		after:      time.After,
+		since:      time.Since,
+		jobs:       map[string]*refreshJob{},
+	}
+}
+
+// Schedule runs fn every interval for the named module until ctx is done
+// or the module is unscheduled. Any existing schedule of the module is replaced.
+func (s *Scheduler) Schedule(ctx context.Context, name string, interval time.Duration, fn RefreshFunc) {
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	job := &refreshJob{base: interval, next: interval, fn: fn, cancel: cancel, done: make(chan struct{})}
+	s.mu.Lock()
+	if old, ok := s.jobs[name]; ok {
+		old.cancel()
+	}
+	s.jobs[name] = job
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(job.done)
+		defer cancel()
+
+		for {
+			s.mu.Lock()
+			next := job.next
+			s.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.after(next):
+			}
+
+			start := time.Now()
+			err := job.fn(ctx)
+			o := s.update(name, job, err, s.since(start))
+			if o == nil {
+				continue
+			}
+
+			s.notifyOverrun(*o)
+			if o.Quarantined {
+				s.mu.Lock()
+				if s.jobs[name] == job {
+					delete(s.jobs, name)
+				}
+				s.mu.Unlock()
+				return
+			}
+		}
+	}()
+}
+
+// update updates the next interval of the job after a refresh,
+// returning the overrun if the refresh took longer than its budget.
+func (s *Scheduler) update(name string, job *refreshJob, err error, took time.Duration) *Overrun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	over := job.budget > 0 && took > job.budget
+	if !over {
+		job.overruns = 0
+	} else {
+		job.overruns++
+	}
+
+	switch {
+	case err != nil, over && job.overruns >= budgetOverruns:
+		s.backoff(job)
+	case !over:
+		job.next = job.base
+	}
+
+	if !over {
+		return nil
+	}
+	return &Overrun{
+		Module:      name,
+		Took:        took,
+		Budget:      job.budget,
+		Overruns:    job.overruns,
+		Interval:    job.next,
+		Quarantined: took > budgetQuarantine*job.budget,
+	}
+}
+
+// backoff doubles the next interval of the job, up to the maximum backoff.
+func (s *Scheduler) backoff(job *refreshJob) {
+	maxBackoff := s.maxBackoff
+	if job.base > maxBackoff {
+		maxBackoff = job.base
+	}
+	job.next *= 2
+	if job.next > maxBackoff {
+		job.next = maxBackoff
+	}
+}
+
+// SetBudget sets the time a refresh of the named module should take.
+// Zero means the refresh time is not limited.
+func (s *Scheduler) SetBudget(name string, budget time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[name]; ok {
+		job.budget = budget
+		job.overruns = 0
+	}
+}
+
+// OnOverrun adds handlers called when a refresh takes longer than its budget.
+func (s *Scheduler) OnOverrun(fns ...OverrunHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overrunFns = append(s.overrunFns, fns...)
+}
+
+func (s *Scheduler) notifyOverrun(o Overrun) {
+	s.mu.Lock()
+	fns := s.overrunFns
+	s.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(o)
+	}
+}
+
+// Overruns returns the number of consecutive refreshes of the
+// named module that took longer than its budget.
+func (s *Scheduler) Overruns(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		return 0
+	}
+	return job.overruns
+}
+
+// Interval returns the current refresh interval of the named module.
+func (s *Scheduler) Interval(name string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		return 0
+	}
+	return job.next
+}
+
+// Unschedule stops refreshing the named module. The returned channel
+// is closed once an in-flight refresh of the module has completed.
+func (s *Scheduler) Unschedule(name string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	job.cancel()
+	delete(s.jobs, name)
+	return job.done
+}
+
+// Stop stops all scheduled refreshes, waiting for running refreshes to complete.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	for name, job := range s.jobs {
+		job.cancel()
+		delete(s.jobs, name)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Wait waits for all scheduled refreshes to stop.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
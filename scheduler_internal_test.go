@@ -0,0 +1,236 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	waits chan time.Duration
+	fire  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		waits: make(chan time.Duration),
+		fire:  make(chan time.Time),
+	}
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits <- d
+	return c.fire
+}
+
+// Tick waits for the next timer, returning its duration, then fires it.
+func (c *fakeClock) Tick(t *testing.T) time.Duration {
+	t.Helper()
+
+	select {
+	case d := <-c.waits:
+		c.fire <- time.Time{}
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for timer")
+		return 0
+	}
+}
+
+func TestScheduler_BacksOffOnErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock()
+	s := NewScheduler(time.Minute)
+	s.after = clock.After
+
+	results := []error{errors.New("test"), errors.New("test"), errors.New("test"), errors.New("test"), nil}
+	calls := 0
+	s.Schedule(ctx, "weather", 10*time.Second, func(context.Context) error {
+		err := results[calls%len(results)]
+		calls++
+		return err
+	})
+
+	var got []time.Duration
+	for i := 0; i < 6; i++ {
+		got = append(got, clock.Tick(t))
+	}
+
+	want := []time.Duration{
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		time.Minute,
+		time.Minute,
+		10 * time.Second,
+	}
+	assert.Equal(t, want, got)
+
+	cancel()
+	go func() { <-clock.waits }()
+	s.Wait()
+}
+
+func TestScheduler_ResetsOnSuccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock()
+	s := NewScheduler(time.Minute)
+	s.after = clock.After
+
+	results := []error{errors.New("test"), errors.New("test"), nil, nil}
+	calls := 0
+	s.Schedule(ctx, "weather", 10*time.Second, func(context.Context) error {
+		err := results[calls%len(results)]
+		calls++
+		return err
+	})
+
+	assert.Equal(t, 10*time.Second, clock.Tick(t))
+	assert.Equal(t, 20*time.Second, clock.Tick(t))
+	assert.Equal(t, 40*time.Second, clock.Tick(t))
+	assert.Equal(t, 10*time.Second, clock.Tick(t))
+	assert.Equal(t, 10*time.Second, s.Interval("weather"))
+
+	cancel()
+	go func() { <-clock.waits }()
+	s.Wait()
+}
+
+func TestScheduler_SkipsTicksWhileRefreshing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock()
+	s := NewScheduler(time.Minute)
+	s.after = clock.After
+
+	started, release := make(chan struct{}), make(chan struct{})
+	s.Schedule(ctx, "weather", 10*time.Second, func(context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	clock.Tick(t)
+	<-started
+
+	select {
+	case <-clock.waits:
+		t.Fatal("next tick scheduled while refreshing")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, 10*time.Second, clock.Tick(t))
+	<-started
+
+	cancel()
+	go func() { <-clock.waits }()
+	s.Wait()
+}
+
+func TestScheduler_Unschedule(t *testing.T) {
+	clock := newFakeClock()
+	s := NewScheduler(time.Minute)
+	s.after = clock.After
+
+	s.Schedule(context.Background(), "weather", 10*time.Second, func(context.Context) error {
+		return nil
+	})
+	<-clock.waits
+
+	s.Unschedule("weather")
+
+	s.Wait()
+	assert.Equal(t, time.Duration(0), s.Interval("weather"))
+}
+
+func TestScheduler_BacksOffOverBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock()
+	s := NewScheduler(time.Minute)
+	s.after = clock.After
+
+	calls := 0
+	s.since = func(time.Time) time.Duration {
+		calls++
+		if calls > 4 {
+			return 500 * time.Millisecond
+		}
+		return 2 * time.Second
+	}
+	overruns := make(chan Overrun, 10)
+	s.OnOverrun(func(o Overrun) {
+		overruns <- o
+	})
+	s.Schedule(ctx, "weather", 10*time.Second, func(context.Context) error { return nil })
+	s.SetBudget("weather", time.Second)
+
+	var intervals []time.Duration
+	for i := 0; i < 6; i++ {
+		intervals = append(intervals, clock.Tick(t))
+	}
+
+	want := []time.Duration{
+		10 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		10 * time.Second,
+	}
+	assert.Equal(t, want, intervals)
+
+	cancel()
+	go func() { <-clock.waits }()
+	s.Wait()
+
+	assert.Equal(t, 0, s.Overruns("weather"))
+	close(overruns)
+	var got []Overrun
+	for o := range overruns {
+		got = append(got, o)
+	}
+	if assert.Len(t, got, 4) {
+		assert.Equal(t, Overrun{
+			Module:   "weather",
+			Took:     2 * time.Second,
+			Budget:   time.Second,
+			Overruns: 4,
+			Interval: 40 * time.Second,
+		}, got[3])
+	}
+}
+
+func TestScheduler_QuarantinesFarOverBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock()
+	s := NewScheduler(time.Minute)
+	s.after = clock.After
+	s.since = func(time.Time) time.Duration { return 11 * time.Second }
+
+	got := make(chan Overrun, 1)
+	s.OnOverrun(func(o Overrun) {
+		got <- o
+	})
+	s.Schedule(ctx, "weather", 10*time.Second, func(context.Context) error { return nil })
+	s.SetBudget("weather", time.Second)
+
+	clock.Tick(t)
+	s.Wait()
+
+	o := <-got
+	assert.True(t, o.Quarantined)
+	assert.Equal(t, time.Duration(0), s.Interval("weather"))
+}
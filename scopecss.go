@@ -0,0 +1,246 @@
+package glass
+
+import "strings"
+
+// scopedAtRules are the at-rules containing style rules that are scoped.
+// Other at-rules with a block, such as @keyframes and @font-face, are
+// left as they are.
+var scopedAtRules = map[string]bool{
+	"@media":     true,
+	"@supports":  true,
+	"@container": true,
+	"@layer":     true,
+	"@document":  true,
+}
+
+// rootSelectors are the selectors of the page root. A scoped selector
+// starting with one of them is scoped below it instead, so rules relying
+// on classes of the page root, such as themes, keep applying.
+var rootSelectors = []string{":root", "html", "body"}
+
+// moduleScope returns the css selector of the module element.
+func moduleScope(name string) string {
+	// Css identifiers cannot start with a digit, which would need escaping
+	// in the css template string. An attribute selector is used instead.
+	if name != "" && (name[0] >= '0' && name[0] <= '9' || strings.HasPrefix(name, "-")) {
+		return `[id="` + name + `"]`
+	}
+	return "#" + name
+}
+
+// scopeCSS prefixes the selectors of the style rules in css with scope,
+// so the rules only apply inside the scope element.
+//
+// This is a basic rewrite of the selectors rather than a full css parser.
+// Comments in selectors are removed.
+func scopeCSS(css, scope string) string {
+	var b strings.Builder
+	b.Grow(len(css) + len(css)/4)
+
+	i := 0
+	for i < len(css) {
+		j := indexCSS(css, i, "{;}")
+		if j < 0 {
+			b.WriteString(css[i:])
+			break
+		}
+		if css[j] != '{' {
+			// Statements, like @import, and stray closing braces are kept.
+			b.WriteString(css[i : j+1])
+			i = j + 1
+			continue
+		}
+
+		prelude := css[i:j]
+		end := matchingBrace(css, j)
+		body := css[j+1 : end]
+
+		name := strings.TrimSpace(stripCSSComments(prelude))
+		if strings.HasPrefix(name, "@") {
+			if scopedAtRules[atRuleName(name)] {
+				body = scopeCSS(body, scope)
+			}
+		} else {
+			prelude = scopeSelectors(prelude, scope)
+		}
+
+		b.WriteString(prelude)
+		b.WriteByte('{')
+		b.WriteString(body)
+		if end < len(css) {
+			b.WriteByte('}')
+		}
+		i = end + 1
+	}
+	return b.String()
+}
+
+// atRuleName returns the name of the at-rule, e.g. "@media".
+func atRuleName(prelude string) string {
+	if idx := strings.IndexAny(prelude, " \t\n\r("); idx > 0 {
+		return strings.ToLower(prelude[:idx])
+	}
+	return strings.ToLower(prelude)
+}
+
+// scopeSelectors scopes each selector in the comma separated list.
+func scopeSelectors(list, scope string) string {
+	list = stripCSSComments(list)
+
+	var parts []string
+	start := 0
+	for {
+		idx := indexCSS(list, start, ",")
+		if idx < 0 {
+			parts = append(parts, list[start:])
+			break
+		}
+		parts = append(parts, list[start:idx])
+		start = idx + 1
+	}
+
+	for i, part := range parts {
+		sel := strings.TrimSpace(part)
+		if sel == "" {
+			continue
+		}
+		lead := part[:strings.Index(part, sel)]
+		trail := part[len(lead)+len(sel):]
+		parts[i] = lead + scopeSelector(sel, scope) + trail
+	}
+	return strings.Join(parts, ",")
+}
+
+// scopeSelector scopes a single selector.
+func scopeSelector(sel, scope string) string {
+	if sel == scope || strings.HasPrefix(sel, scope+" ") || strings.HasPrefix(sel, scope+">") {
+		return sel
+	}
+
+	for _, root := range rootSelectors {
+		if !strings.HasPrefix(sel, root) {
+			continue
+		}
+		if len(sel) > len(root) && isIdentChar(sel[len(root)]) {
+			// The selector only starts with the root name, e.g. "htmlfoo".
+			continue
+		}
+
+		// The compound selector of the root, e.g. "html.dark".
+		end := len(root)
+		for end < len(sel) && !strings.ContainsRune(" >+~", rune(sel[end])) {
+			end++
+		}
+
+		rest := strings.TrimLeft(sel[end:], " ")
+		if end == len(root) && rest == "" {
+			return scope
+		}
+		if rest == "" {
+			return sel[:end] + " " + scope
+		}
+		return sel[:end] + " " + scope + " " + rest
+	}
+	return scope + " " + sel
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// indexCSS returns the index of the first of chars in css from start,
+// outside of strings, comments, parentheses and brackets, or -1.
+func indexCSS(css string, start int, chars string) int {
+	depth := 0
+	for i := start; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case c == '"' || c == '\'':
+			i = skipCSSString(css, i)
+			continue
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			i = skipCSSComment(css, i)
+			continue
+		case c == '(' || c == '[':
+			depth++
+			continue
+		case (c == ')' || c == ']') && depth > 0:
+			depth--
+			continue
+		}
+		if depth == 0 && strings.IndexByte(chars, c) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchingBrace returns the index of the brace closing the block opened
+// at open, or the length of css if the block is not closed.
+func matchingBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch c := css[i]; {
+		case c == '"' || c == '\'':
+			i = skipCSSString(css, i)
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			i = skipCSSComment(css, i)
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(css)
+}
+
+// skipCSSString returns the index of the quote closing the string opened at i.
+func skipCSSString(css string, i int) int {
+	quote := css[i]
+	for i++; i < len(css); i++ {
+		switch css[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(css)
+}
+
+// skipCSSComment returns the index of the end of the comment opened at i.
+func skipCSSComment(css string, i int) int {
+	end := strings.Index(css[i+2:], "*/")
+	if end < 0 {
+		return len(css)
+	}
+	return i + 2 + end + 1
+}
+
+// stripCSSComments removes the comments from css.
+func stripCSSComments(css string) string {
+	if !strings.Contains(css, "/*") {
+		return css
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(css); i++ {
+		switch c := css[i]; {
+		case c == '"' || c == '\'':
+			end := skipCSSString(css, i)
+			if end >= len(css) {
+				end = len(css) - 1
+			}
+			b.WriteString(css[i : end+1])
+			i = end
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			i = skipCSSComment(css, i)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
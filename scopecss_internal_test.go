@@ -0,0 +1,103 @@
+package glass
+
+import (
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		css  string
+		want string
+	}{
+		{
+			name: "selectors",
+			css:  ".title { color: red; }\np, a:hover{margin: 0}",
+			want: "#clock .title { color: red; }\n#clock p, #clock a:hover{margin: 0}",
+		},
+		{
+			name: "commas in selectors",
+			css:  `a:is(.b, .c), [title="x,y"] {}`,
+			want: `#clock a:is(.b, .c), #clock [title="x,y"] {}`,
+		},
+		{
+			name: "root selectors",
+			css:  ":root { --gap: 1px; } body{} html > .x {}",
+			want: "#clock { --gap: 1px; } #clock{} html #clock > .x {}",
+		},
+		{
+			name: "theme selectors",
+			css:  "html.dark .title {} body.night{}",
+			want: "html.dark #clock .title {} body.night #clock{}",
+		},
+		{
+			name: "selectors starting with root names",
+			css:  "htmlish {}",
+			want: "#clock htmlish {}",
+		},
+		{
+			name: "already scoped",
+			css:  "#clock .title {} #clock{}",
+			want: "#clock .title {} #clock{}",
+		},
+		{
+			name: "grouping at-rules",
+			css:  "@media (max-width: 600px) { .title { display: none; } }",
+			want: "@media (max-width: 600px) { #clock .title { display: none; } }",
+		},
+		{
+			name: "other at-rules",
+			css:  "@import url(\"a.css\");\n@keyframes spin { from { opacity: 0; } to { opacity: 1; } }\n@font-face { font-family: x; }",
+			want: "@import url(\"a.css\");\n@keyframes spin { from { opacity: 0; } to { opacity: 1; } }\n@font-face { font-family: x; }",
+		},
+		{
+			name: "strings and comments",
+			css:  "/* .a { */ .b { content: \"}\"; } .c /* d */ {}",
+			want: " #clock .b { content: \"}\"; } #clock .c  {}",
+		},
+		{
+			name: "unclosed block",
+			css:  ".a { color: red;",
+			want: "#clock .a { color: red;",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := scopeCSS(test.css, "#clock")
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestModuleScope(t *testing.T) {
+	assert.Equal(t, "#clock", moduleScope("clock"))
+	assert.Equal(t, `[id="1clock"]`, moduleScope("1clock"))
+}
+
+func TestUIContext_LoadCSSScoped(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	descs := []module.Descriptor{
+		{Name: "clock", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}, ScopedCSS: true},
+		{Name: "news", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}, ScopedCSS: true, Sandbox: module.Sandbox{Enabled: true}},
+	}
+	uiCtxs, err := NewUIContexts(ui, descs)
+	require.NoError(t, err)
+
+	err = uiCtxs[0].LoadCSS(".title { color: red; }")
+	require.NoError(t, err)
+	err = uiCtxs[1].LoadCSS(".title { color: red; }")
+	require.NoError(t, err)
+
+	assert.Contains(t, win.Evals(), "loadCSS(`clock`, `#clock .title { color: red; }`);")
+	assert.Contains(t, win.Evals(), "loadCSS(`news`, `.title { color: red; }`);")
+}
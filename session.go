@@ -0,0 +1,129 @@
+package glass
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zserge/lorca"
+)
+
+// SessionEvent is a recorded call to the window.
+type SessionEvent struct {
+	// Offset is the time since the recording started.
+	Offset time.Duration `json:"offset"`
+	// Method is the window method that was called.
+	Method string `json:"method"`
+	// Arg is the javascript, binding name or url of the call.
+	Arg string `json:"arg"`
+}
+
+// Record records all subsequent calls made to the window to w.
+//
+// Each call is written as a line of JSON. Record must be called
+// before the ui is used.
+func (ui *UI) Record(w io.Writer) {
+	ui.win = &sessionRecorder{
+		UI:    ui.win,
+		mu:    &sync.Mutex{},
+		enc:   json.NewEncoder(w),
+		start: time.Now(),
+	}
+}
+
+// sessionRecorder records calls to a window.
+type sessionRecorder struct {
+	lorca.UI
+
+	mu    *sync.Mutex
+	enc   *json.Encoder
+	start time.Time
+}
+
+func (r *sessionRecorder) Load(url string) error {
+	r.record("Load", url)
+	return r.UI.Load(url)
+}
+
+func (r *sessionRecorder) Bind(name string, fn interface{}) error {
+	r.record("Bind", name)
+	return r.UI.Bind(name, fn)
+}
+
+func (r *sessionRecorder) Eval(js string) lorca.Value {
+	r.record("Eval", js)
+	return r.UI.Eval(js)
+}
+
+func (r *sessionRecorder) record(method, arg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_ = r.enc.Encode(SessionEvent{
+		Offset: time.Since(r.start),
+		Method: method,
+		Arg:    arg,
+	})
+}
+
+// ReadSession reads a recorded session.
+func ReadSession(r io.Reader) ([]SessionEvent, error) {
+	var events []SessionEvent
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		var event SessionEvent
+		if err := json.Unmarshal(sc.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("session: line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+	return events, nil
+}
+
+// ReplaySession re-issues the recorded events in order against the ui.
+//
+// If paced is true, the events are issued with their recorded timing.
+// Bindings are replayed as functions that do nothing.
+func ReplaySession(ctx context.Context, ui *UI, events []SessionEvent, paced bool) error {
+	start := time.Now()
+	for _, event := range events {
+		if paced {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Until(start.Add(event.Offset))):
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		switch event.Method {
+		case "Eval":
+			err = newEvalResult(ui.win.Eval(event.Arg)).err
+		case "Bind":
+			err = ui.win.Bind(event.Arg, func() {})
+		case "Load":
+			err = ui.win.Load(event.Arg)
+		default:
+			err = fmt.Errorf("unknown method %q", event.Method)
+		}
+		if err != nil {
+			return fmt.Errorf("session: could not replay %s: %w", event.Method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package glass
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUI_RecordAndReplaySession(t *testing.T) {
+	var buf bytes.Buffer
+	ui := &UI{win: NewRecordingWindow()}
+	ui.Record(&buf)
+
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	err = uiCtx.LoadCSS("test css")
+	require.NoError(t, err)
+	err = uiCtx.Bind("test", func() {})
+	require.NoError(t, err)
+	err = uiCtx.LoadHTML("test html")
+	require.NoError(t, err)
+
+	events, err := ReadSession(&buf)
+	require.NoError(t, err)
+
+	win := NewRecordingWindow()
+	err = ReplaySession(context.Background(), &UI{win: win}, events, true)
+
+	require.NoError(t, err)
+	want := []Call{
+		{Method: "Eval", Arg: `createModule("test", "top", "right");`},
+		{Method: "Eval", Arg: "loadCSS(`test`, `test css`);"},
+		{Method: "Bind", Arg: "test.test"},
+		{Method: "Bind", Arg: "test"},
+		{Method: "Eval", Arg: "loadModuleHTML(`test`, `test html`);"},
+	}
+	assert.Equal(t, want, win.Calls())
+}
+
+func TestReadSession_HandlesInvalidJSON(t *testing.T) {
+	_, err := ReadSession(bytes.NewBufferString("{\"method\":\"Eval\"}\nnot json\n"))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package glass
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// DefaultShutdownTimeout is the default time modules have to finish on shutdown.
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ErrShutdownTimeout is returned when modules do not finish before the shutdown timeout.
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// ShutdownConfig contains configuration for graceful shutdown.
+type ShutdownConfig struct {
+	// Timeout is the maximum time to wait for modules to finish.
+	// Zero means DefaultShutdownTimeout.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Validate validates the shutdown configuration.
+func (c ShutdownConfig) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("config: shutdown timeout cannot be negative")
+	}
+	return nil
+}
+
+// Shutdown closes the ui, stops the module refreshes and closes the running
+// modules, waiting for in-flight module work to finish.
+//
+// If modules are still busy once the configured timeout has passed, they are
+// logged and ErrShutdownTimeout is returned.
+func (r *Runtime) Shutdown() error {
+	// Display sleep is restored even if the modules do not shut down in time.
+	defer r.stopKeepAwake()
+
+	r.stopWatching()
+	if err := r.ui.Close(); err != nil {
+		r.log.Error("could not close ui", logCtx.Error("error", err))
+	}
+
+	type closing struct {
+		name string
+		mod  io.Closer
+	}
+
+	r.mu.Lock()
+	timeout := r.cfg.Shutdown.Timeout
+	var mods []closing
+	for _, state := range r.states {
+		if state.mod != nil {
+			mods = append(mods, closing{name: state.desc.InstanceID(), mod: state.mod})
+		}
+		state.running = false
+		state.mod = nil
+	}
+	r.mods = nil
+	r.mu.Unlock()
+
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	done := make(chan string, len(mods))
+	pending := make(map[string]bool, len(mods))
+	for _, m := range mods {
+		pending[m.name] = true
+
+		go func(m closing) {
+			<-r.sched.Unschedule(m.name)
+			if err := m.mod.Close(); err != nil {
+				r.log.Error("could not close module", logCtx.Str("module", m.name), logCtx.Error("error", err))
+			}
+			done <- m.name
+		}(m)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case name := <-done:
+			delete(pending, name)
+		case <-timer.C:
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			r.log.Error("modules did not shut down in time", logCtx.Strs("modules", names))
+			return ErrShutdownTimeout
+		}
+	}
+	return nil
+}
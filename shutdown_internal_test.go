@@ -0,0 +1,82 @@
+package glass
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/hamba/logger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_Shutdown(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	win.On("Close").Once().Return(nil)
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	mod := &MockModule{}
+	mod.On("Close").Once().Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(mod, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+
+	err = rt.Shutdown()
+
+	require.NoError(t, err)
+	assert.False(t, rt.RuntimeState()[0].Healthy)
+	win.AssertExpectations(t)
+	mod.AssertExpectations(t)
+}
+
+func TestRuntime_ShutdownTimesOut(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModules([{"name":"test","vert":"top","horiz":"right"}]);`).Return(NewValue(`{"test":""}`, nil))
+	win.On("Close").Once().Return(nil)
+	ui := &UI{win: win}
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	mod := &MockModule{}
+	mod.On("Close").Run(func(mock.Arguments) { <-release }).Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(mod, nil)
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+	cfg := Config{
+		Shutdown: ShutdownConfig{Timeout: 10 * time.Millisecond},
+		Modules:  []module.Descriptor{desc},
+	}
+	rt := NewRuntime(cfg, ui, svc, log)
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+	awake := &MockKeepAwaker{}
+	awake.On("Stop").Return(nil).Once()
+	rt.awake = awake
+
+	err = rt.Shutdown()
+
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+	assert.Contains(t, buf.String(), `msg="modules did not shut down in time" modules=test`)
+	awake.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SplashConfig contains configuration for the startup splash screen.
+type SplashConfig struct {
+	// Enabled shows the splash screen until all modules have loaded.
+	Enabled bool `yaml:"enabled"`
+	// HTML is the path of the splash screen html.
+	// The built-in logo and spinner are shown if empty.
+	HTML string `yaml:"html"`
+	// CSS is the path of the splash screen css.
+	CSS string `yaml:"css"`
+}
+
+// showSplash covers the page with the splash screen.
+func (ui *UI) showSplash(cfg SplashConfig) error {
+	var html, css []byte
+	if cfg.HTML != "" {
+		b, err := readAsset(cfg.HTML)
+		if err != nil {
+			return fmt.Errorf("could not read splash html %q: %w", cfg.HTML, err)
+		}
+		html = b
+	}
+	if cfg.CSS != "" {
+		b, err := readAsset(cfg.CSS)
+		if err != nil {
+			return fmt.Errorf("could not read splash css %q: %w", cfg.CSS, err)
+		}
+		css = b
+	}
+
+	h, _ := json.Marshal(string(html))
+	c, _ := json.Marshal(string(css))
+	if _, err := ui.Eval(fmt.Sprintf("showSplash(%s, %s);", h, c)); err != nil {
+		return fmt.Errorf("could not show splash: %w", err)
+	}
+
+	ui.mu.Lock()
+	ui.splash = true
+	ui.mu.Unlock()
+	return nil
+}
+
+// hideSplash fades out the splash screen, if it is shown.
+func (ui *UI) hideSplash() error {
+	ui.mu.Lock()
+	shown := ui.splash
+	ui.splash = false
+	ui.mu.Unlock()
+	if !shown {
+		return nil
+	}
+
+	_, err := ui.Eval("hideSplash();")
+	return err
+}
@@ -0,0 +1,88 @@
+package glass
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUI_ShowSplash(t *testing.T) {
+	dir := t.TempDir()
+	htmlPath := filepath.Join(dir, "splash.html")
+	err := os.WriteFile(htmlPath, []byte(`<img src="logo.svg">`), 0o600)
+	require.NoError(t, err)
+	cssPath := filepath.Join(dir, "splash.css")
+	err = os.WriteFile(cssPath, []byte(`.splash { background: #111; }`), 0o600)
+	require.NoError(t, err)
+
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	err = ui.showSplash(SplashConfig{Enabled: true, HTML: htmlPath, CSS: cssPath})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`showSplash("\u003cimg src=\"logo.svg\"\u003e", ".splash { background: #111; }");`}, win.Evals())
+}
+
+func TestUI_ShowSplashUsesDefault(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+
+	err := ui.showSplash(SplashConfig{Enabled: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`showSplash("", "");`}, win.Evals())
+}
+
+func TestUI_ShowSplashHandlesMissingFile(t *testing.T) {
+	ui := &UI{win: NewRecordingWindow()}
+
+	err := ui.showSplash(SplashConfig{Enabled: true, HTML: filepath.Join(t.TempDir(), "missing.html")})
+
+	assert.Error(t, err)
+}
+
+func TestUI_HideSplashOnlyOnce(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	err := ui.showSplash(SplashConfig{Enabled: true})
+	require.NoError(t, err)
+
+	err = ui.hideSplash()
+	require.NoError(t, err)
+	err = ui.hideSplash()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`showSplash("", "");`, "hideSplash();"}, win.Evals())
+}
+
+func TestRuntime_LoadHidesSplashWhenModulesFail(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	err := ui.showSplash(SplashConfig{Enabled: true})
+	require.NoError(t, err)
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(nil, errors.New("test error"))
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, ui, svc, newTestLogger())
+
+	err = rt.Load(context.Background())
+
+	assert.Error(t, err)
+	evals := win.Evals()
+	assert.Equal(t, "hideSplash();", evals[len(evals)-1])
+}
@@ -0,0 +1,178 @@
+package glass
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module"
+)
+
+// ModuleState describes the state of a module in the runtime.
+type ModuleState struct {
+	Name           string       `json:"name"`
+	Position       string       `json:"position"`
+	Enabled        bool         `json:"enabled"`
+	Healthy        bool         `json:"healthy"`
+	Status         ModuleStatus `json:"status"`
+	LastError      string       `json:"lastError,omitempty"`
+	LastRenderTime time.Time    `json:"lastRenderTime"`
+	// LastRefreshTime is the time the module was last refreshed successfully.
+	LastRefreshTime time.Time `json:"lastRefreshTime"`
+	// RefreshInterval is the current refresh interval, including any backoff.
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+	// RefreshOverruns is the number of consecutive refreshes over budget.
+	RefreshOverruns int `json:"refreshOverruns,omitempty"`
+}
+
+// moduleState tracks a module in the runtime.
+type moduleState struct {
+	desc     module.Descriptor
+	ui       *UIContext
+	mod      io.Closer
+	running  bool
+	skipped  bool
+	disabled bool
+	failed   bool
+	err      error
+
+	refreshed time.Time
+}
+
+// status returns the lifecycle status of the module.
+func (s *moduleState) status() ModuleStatus {
+	switch {
+	case s.disabled:
+		return ModuleDisabled
+	case s.skipped:
+		return ModuleSkipped
+	case s.failed, !s.running && s.err != nil:
+		return ModuleFailed
+	case s.running:
+		return ModuleRunning
+	default:
+		return ModulePending
+	}
+}
+
+// RuntimeState returns the state of all configured modules.
+func (r *Runtime) RuntimeState() []ModuleState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make([]ModuleState, 0, len(r.states))
+	for _, s := range r.states {
+		state := ModuleState{
+			Name:     s.desc.InstanceID(),
+			Position: s.desc.Position.String(),
+			Enabled:  !s.skipped && !s.disabled,
+			Healthy:  s.running && !s.failed && s.err == nil,
+			Status:   s.status(),
+
+			LastRefreshTime: s.refreshed,
+		}
+		if s.err != nil {
+			state.LastError = s.err.Error()
+		}
+		if s.ui != nil {
+			state.LastRenderTime = s.ui.lastRendered()
+		}
+		if interval := r.sched.Interval(s.desc.InstanceID()); interval > 0 {
+			state.RefreshInterval = interval.String()
+		}
+		state.RefreshOverruns = r.sched.Overruns(s.desc.InstanceID())
+		states = append(states, state)
+	}
+	return states
+}
+
+// Health statuses.
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// Health describes the liveness of the ui and its modules.
+type Health struct {
+	Status  string        `json:"status"`
+	Modules []ModuleState `json:"modules"`
+}
+
+// Health returns the liveness of the ui and its modules.
+//
+// The status is unavailable once the ui has been closed.
+func (r *Runtime) Health() Health {
+	status := HealthOK
+	if !r.ui.alive() {
+		status = HealthUnavailable
+	}
+	return Health{
+		Status:  status,
+		Modules: r.RuntimeState(),
+	}
+}
+
+// ServerConfig contains configuration for the HTTP server.
+type ServerConfig struct {
+	// Addr is the address the server listens on.
+	// The server is disabled if empty.
+	Addr string `yaml:"addr"`
+	// Metrics serves prometheus metrics on /metrics.
+	Metrics bool `yaml:"metrics"`
+}
+
+// NewServer returns an HTTP handler exposing the runtime.
+func NewServer(rt *Runtime) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(rw, rt.RuntimeState())
+	})
+	mux.HandleFunc("/health", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		health := rt.Health()
+		if health.Status != HealthOK {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(rw).Encode(health)
+			return
+		}
+		writeJSON(rw, health)
+	})
+	mux.HandleFunc("/screenshot", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		img, err := rt.ui.Screenshot()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		rw.Header().Set("Content-Type", "image/png")
+		_, _ = rw.Write(img)
+	})
+	mux.HandleFunc("/bindings", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(rw, rt.ui.Bindings())
+	})
+	if rt.cfg.Server.Metrics {
+		mux.Handle("/metrics", rt.metrics.Handler())
+	}
+	return mux
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(v)
+}
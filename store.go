@@ -0,0 +1,175 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+// FileStore is a module key/value store persisted as a json file.
+//
+// A store without a path keeps its values in memory only.
+type FileStore struct {
+	path string
+
+	mu     sync.Mutex
+	vals   map[string]string
+	loaded bool
+}
+
+// NewFileStore returns a store persisted at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Get returns the value of key, and if it was found.
+func (s *FileStore) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", false
+	}
+	v, ok := s.vals[key]
+	return v, ok
+}
+
+// Set sets the value of key.
+func (s *FileStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	old, had := s.vals[key]
+	s.vals[key] = value
+	if err := s.save(); err != nil {
+		if had {
+			s.vals[key] = old
+		} else {
+			delete(s.vals, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes key from the store.
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	old, ok := s.vals[key]
+	if !ok {
+		return nil
+	}
+	delete(s.vals, key)
+	if err := s.save(); err != nil {
+		s.vals[key] = old
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	s.vals = map[string]string{}
+	if s.path != "" {
+		b, err := os.ReadFile(s.path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return fmt.Errorf("could not read store: %w", err)
+		default:
+			if err = json.Unmarshal(b, &s.vals); err != nil {
+				return fmt.Errorf("could not decode store: %w", err)
+			}
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+// save writes the store to a temporary file before renaming it into place,
+// so the store is never left partially written.
+func (s *FileStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(s.vals)
+	if err != nil {
+		return fmt.Errorf("could not encode store: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create store: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err = f.Write(b); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not write store: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not write store: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("could not write store: %w", err)
+	}
+	if err = os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("could not write store: %w", err)
+	}
+	return nil
+}
+
+// SetStoreDir sets the directory module stores are persisted in.
+//
+// Each module is stored in its own file, named after the module. Without
+// a directory, module stores are kept in memory.
+func (ui *UI) SetStoreDir(dir string) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.storeDir = dir
+}
+
+// store returns the store of the named module.
+func (ui *UI) store(name string) *FileStore {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	if s, ok := ui.stores[name]; ok {
+		return s
+	}
+	if ui.stores == nil {
+		ui.stores = map[string]*FileStore{}
+	}
+	var path string
+	if ui.storeDir != "" {
+		path = filepath.Join(ui.storeDir, name+".json")
+	}
+	s := NewFileStore(path)
+	ui.stores[name] = s
+	return s
+}
+
+// Store returns the key/value store of the module.
+//
+// Values are persisted across restarts when a store directory is set.
+func (u *UIContext) Store() types.Store {
+	return u.ui.store(u.name)
+}
@@ -0,0 +1,96 @@
+package glass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	s := NewFileStore(path)
+
+	_, ok := s.Get("last-id")
+	assert.False(t, ok)
+
+	err := s.Set("last-id", "42")
+	require.NoError(t, err)
+	err = s.Set("dismissed", "true")
+	require.NoError(t, err)
+	err = s.Delete("dismissed")
+	require.NoError(t, err)
+
+	got, ok := NewFileStore(path).Get("last-id")
+	assert.True(t, ok)
+	assert.Equal(t, "42", got)
+	_, ok = NewFileStore(path).Get("dismissed")
+	assert.False(t, ok)
+}
+
+func TestFileStore_LeavesNoTemporaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(filepath.Join(dir, "test.json"))
+
+	err := s.Set("key", "value")
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "test.json", entries[0].Name())
+}
+
+func TestFileStore_KeepsValuesOnWriteError(t *testing.T) {
+	s := NewFileStore(filepath.Join(t.TempDir(), "missing", "test.json"))
+
+	err := s.Set("key", "value")
+
+	assert.Error(t, err)
+	_, ok := s.Get("key")
+	assert.False(t, ok)
+}
+
+func TestFileStore_HandlesCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	err := os.WriteFile(path, []byte("{"), 0o600)
+	require.NoError(t, err)
+	s := NewFileStore(path)
+
+	_, ok := s.Get("key")
+	assert.False(t, ok)
+	err = s.Set("key", "value")
+	assert.Error(t, err)
+}
+
+func TestUIContext_Store(t *testing.T) {
+	dir := t.TempDir()
+	ui := &UI{}
+	ui.SetStoreDir(dir)
+	clock := &UIContext{ui: ui, name: "clock"}
+	weather := &UIContext{ui: ui, name: "weather"}
+
+	err := clock.Store().Set("key", "clock")
+	require.NoError(t, err)
+	err = weather.Store().Set("key", "weather")
+	require.NoError(t, err)
+
+	got, ok := clock.Store().Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "clock", got)
+	assert.FileExists(t, filepath.Join(dir, "clock.json"))
+	assert.FileExists(t, filepath.Join(dir, "weather.json"))
+}
+
+func TestUIContext_StoreWithoutDir(t *testing.T) {
+	uiCtx := &UIContext{ui: &UI{}, name: "test"}
+
+	err := uiCtx.Store().Set("key", "value")
+	require.NoError(t, err)
+
+	got, ok := uiCtx.Store().Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", got)
+}
@@ -0,0 +1,46 @@
+package glass
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+)
+
+// templateLiteralEscaper escapes html for use in a javascript template literal.
+var templateLiteralEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "${", `\${`)
+
+// RenderTemplate executes the html template with data, loading the result
+// into the module.
+//
+// Data is escaped for the context it is used in, so it cannot change the
+// structure of the html.
+func (u *UIContext) RenderTemplate(tmpl string, data interface{}) error {
+	t, err := template.New(u.name).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("%s: could not parse template: %w", u.name, err)
+	}
+	return u.renderTemplate(t, data)
+}
+
+// RenderTemplateFile executes the html template in the file at path with
+// data, loading the result into the module.
+//
+// Data is escaped for the context it is used in, so it cannot change the
+// structure of the html.
+func (u *UIContext) RenderTemplateFile(path string, data interface{}) error {
+	t, err := template.New(filepath.Base(path)).ParseFiles(path)
+	if err != nil {
+		return fmt.Errorf("%s: could not parse template: %w", u.name, err)
+	}
+	return u.renderTemplate(t, data)
+}
+
+func (u *UIContext) renderTemplate(t *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("%s: could not execute template: %w", u.name, err)
+	}
+	return u.loadHTML(buf.String(), true)
+}
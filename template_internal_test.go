@@ -0,0 +1,67 @@
+package glass
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIContext_RenderTemplate(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.RenderTemplate(`<ul>{{ range . }}<li title="{{ . }}">{{ . }}</li>{{ end }}</ul>`, []string{"Standup", "<b>Lunch</b>"})
+
+	require.NoError(t, err)
+	want := `<ul><li title="Standup">Standup</li><li title="&lt;b&gt;Lunch&lt;/b&gt;">&lt;b&gt;Lunch&lt;/b&gt;</li></ul>`
+	assert.Equal(t, want, win.HTML(TestModuleName))
+}
+
+func TestUIContext_RenderTemplateEscapesTemplateLiteral(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.RenderTemplate(`<p>{{ . }}</p>`, "`); alert(1); (` ${x} \\n")
+
+	require.NoError(t, err)
+	assert.Equal(t, "<p>\\`); alert(1); (\\` \\${x} \\\\n</p>", win.HTML(TestModuleName))
+}
+
+func TestUIContext_RenderTemplateHandlesParseError(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	err := uiCtx.RenderTemplate(`<p>{{ .Title </p>`, nil)
+
+	assert.Error(t, err)
+}
+
+func TestUIContext_RenderTemplateHandlesExecuteError(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.RenderTemplate(`<p>{{ .Title }}</p>`, 1)
+
+	assert.Error(t, err)
+	assert.Empty(t, win.HTML(TestModuleName))
+}
+
+func TestUIContext_RenderTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.html")
+	err := os.WriteFile(path, []byte(`<h1>{{ .Title }}</h1>`), 0o600)
+	require.NoError(t, err)
+
+	uiCtx, win := NewTestUIContext()
+
+	err = uiCtx.RenderTemplateFile(path, struct{ Title string }{Title: "Q&A"})
+
+	require.NoError(t, err)
+	assert.Equal(t, `<h1>Q&amp;A</h1>`, win.HTML(TestModuleName))
+}
+
+func TestUIContext_RenderTemplateFileHandlesMissingFile(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	err := uiCtx.RenderTemplateFile(filepath.Join(t.TempDir(), "missing.html"), nil)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package hello
+
+import (
+	"context"
+	"io"
+
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+type Config struct {
+	Name string `yaml:"name"`
+}
+
+func NewConfig() *Config {
+	return &Config{Name: "World"}
+}
+
+type Module struct{}
+
+func New(ctx context.Context, cfg *Config, info types.Info, ui types.UI) (io.Closer, error) {
+	if err := ui.LoadHTML("<p>Hello " + cfg.Name + "</p>"); err != nil {
+		return nil, err
+	}
+	return &Module{}, nil
+}
+
+func (m *Module) Close() error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package refresh
+
+import (
+	"context"
+	"io"
+
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+type Config struct{}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+type Module struct {
+	n int
+}
+
+func New(ctx context.Context, cfg *Config, info types.Info, ui types.UI) (io.Closer, error) {
+	return &Module{}, nil
+}
+
+func (m *Module) Refresh(ctx context.Context) error {
+	m.n++
+	return nil
+}
+
+func (m *Module) Close() error {
+	return nil
+}
@@ -0,0 +1,318 @@
+package glass
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// ThemeTopic is the event bus topic a theme change is published on.
+const ThemeTopic = "glass.theme"
+
+// Color schemes.
+const (
+	Light = "light"
+	Dark  = "dark"
+)
+
+// ThemesConfig contains configuration for switching between
+// named css bundles.
+type ThemesConfig struct {
+	// Default is the theme applied at startup.
+	Default string `yaml:"default"`
+
+	// ColorScheme maps the preferred color scheme of the display,
+	// "light" or "dark", to a theme. The theme follows the color scheme
+	// when it changes.
+	ColorScheme map[string]string `yaml:"colorScheme"`
+
+	// Schedule switches the theme at times of the day.
+	Schedule []ThemeSchedule `yaml:"schedule"`
+
+	// Bundles are the css files of each theme.
+	Bundles map[string][]string `yaml:"bundles"`
+}
+
+// ThemeSchedule switches to a theme at a time of the day.
+type ThemeSchedule struct {
+	// At is the time of the day, e.g. "19:30".
+	At    string `yaml:"at"`
+	Theme string `yaml:"theme"`
+}
+
+// Validate validates the themes configuration.
+func (c ThemesConfig) Validate() error {
+	if c.Default != "" && !c.hasTheme(c.Default) {
+		return fmt.Errorf("config: default theme %q is not defined", c.Default)
+	}
+	for scheme, theme := range c.ColorScheme {
+		if scheme != Light && scheme != Dark {
+			return fmt.Errorf("config: unknown color scheme %q", scheme)
+		}
+		if !c.hasTheme(theme) {
+			return fmt.Errorf("config: %s color scheme theme %q is not defined", scheme, theme)
+		}
+	}
+	for _, s := range c.Schedule {
+		if _, err := parseTimeOfDay(s.At); err != nil {
+			return fmt.Errorf("config: invalid theme schedule time %q", s.At)
+		}
+		if !c.hasTheme(s.Theme) {
+			return fmt.Errorf("config: scheduled theme %q is not defined", s.Theme)
+		}
+	}
+	return nil
+}
+
+// Enabled determines if themes have been configured.
+func (c ThemesConfig) Enabled() bool {
+	return len(c.Bundles) > 0
+}
+
+func (c ThemesConfig) hasTheme(name string) bool {
+	_, ok := c.Bundles[name]
+	return ok
+}
+
+// parseTimeOfDay parses a "15:04" time, returning the offset from midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// themeSwitch is a scheduled theme switch.
+type themeSwitch struct {
+	at    time.Duration
+	theme string
+}
+
+// themeSwitches returns the theme schedule ordered by time of day.
+func themeSwitches(schedule []ThemeSchedule) []themeSwitch {
+	switches := make([]themeSwitch, 0, len(schedule))
+	for _, s := range schedule {
+		at, err := parseTimeOfDay(s.At)
+		if err != nil {
+			continue
+		}
+		switches = append(switches, themeSwitch{at: at, theme: s.Theme})
+	}
+	sort.SliceStable(switches, func(i, j int) bool {
+		return switches[i].at < switches[j].at
+	})
+	return switches
+}
+
+// scheduledTheme returns the theme scheduled at now, and the time of the next switch.
+func scheduledTheme(switches []themeSwitch, now time.Time) (string, time.Time) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := now.Sub(midnight)
+
+	// The last switch of the previous day applies until the first switch of the day.
+	theme, next := switches[len(switches)-1].theme, midnight.AddDate(0, 0, 1).Add(switches[0].at)
+	for _, s := range switches {
+		if s.at > since {
+			next = midnight.Add(s.at)
+			break
+		}
+		theme = s.theme
+	}
+	return theme, next
+}
+
+// Theme returns the name of the current theme.
+func (r *Runtime) Theme() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.theme
+}
+
+// SetTheme swaps the css of the current theme for the css of the named theme,
+// publishing the change on ThemeTopic so modules can restyle.
+//
+// Module html is left untouched.
+func (r *Runtime) SetTheme(name string) error {
+	r.themeMu.Lock()
+	defer r.themeMu.Unlock()
+
+	r.mu.Lock()
+	themes := r.cfg.Themes
+	files := r.themeFiles
+	current := r.theme
+	r.mu.Unlock()
+
+	paths, ok := themes.Bundles[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+	if name == current {
+		return nil
+	}
+
+	css, err := cssFilesJS("theme css", "themeCSS", paths, r.log)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= files; i++ {
+		if _, err = r.ui.Eval(`removeCSS("themeCSS` + strconv.Itoa(i) + `");`); err != nil {
+			return fmt.Errorf("could not remove theme css: %w", err)
+		}
+	}
+	for _, js := range css {
+		if _, err = r.ui.Eval(js); err != nil {
+			return fmt.Errorf("could not load theme css: %w", err)
+		}
+	}
+	r.ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("themeCSS`)
+	}, css)
+
+	r.mu.Lock()
+	r.theme = name
+	r.themeFiles = len(paths)
+	r.mu.Unlock()
+
+	b, _ := json.Marshal(struct {
+		Theme string `json:"theme"`
+	}{Theme: name})
+	r.bus.Publish(Event{Topic: ThemeTopic, Data: b})
+
+	r.log.Info("theme applied", logCtx.Str("theme", name))
+	return nil
+}
+
+// startThemes applies the configured theme, following the color scheme
+// and theme schedule until ctx is done or the themes are restarted.
+func (r *Runtime) startThemes(ctx context.Context) error {
+	r.stopThemes()
+
+	r.mu.Lock()
+	themes := r.cfg.Themes
+	// The theme bundles may have changed, so the theme is always applied.
+	r.theme = ""
+	r.mu.Unlock()
+
+	if !themes.Enabled() {
+		return r.removeTheme()
+	}
+
+	theme := themes.Default
+	if len(themes.ColorScheme) > 0 {
+		res, err := r.ui.Eval("currentColorScheme();")
+		if err != nil {
+			return fmt.Errorf("could not determine color scheme: %w", err)
+		}
+		scheme, _ := res.(string)
+		if t, ok := themes.ColorScheme[scheme]; ok {
+			theme = t
+		}
+
+		err = r.ui.Bind("colorSchemeChanged", func(scheme string) {
+			r.mu.Lock()
+			t, ok := r.cfg.Themes.ColorScheme[scheme]
+			r.mu.Unlock()
+			if !ok {
+				return
+			}
+
+			if err := r.SetTheme(t); err != nil {
+				r.log.Error("could not change theme", logCtx.Error("error", err))
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var switches []themeSwitch
+	if len(themes.Schedule) > 0 {
+		switches = themeSwitches(themes.Schedule)
+		theme, _ = scheduledTheme(switches, time.Now())
+	}
+
+	if theme != "" {
+		if err := r.SetTheme(theme); err != nil {
+			return err
+		}
+	}
+
+	if len(switches) > 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		r.mu.Lock()
+		r.themeStop = func() {
+			cancel()
+			<-done
+		}
+		r.mu.Unlock()
+
+		go func() {
+			defer close(done)
+
+			r.runThemeSchedule(ctx, switches)
+		}()
+	}
+	return nil
+}
+
+// runThemeSchedule switches the theme on schedule until ctx is done.
+func (r *Runtime) runThemeSchedule(ctx context.Context, switches []themeSwitch) {
+	for {
+		_, next := scheduledTheme(switches, time.Now())
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		theme, _ := scheduledTheme(switches, time.Now())
+		if err := r.SetTheme(theme); err != nil {
+			r.log.Error("could not change theme", logCtx.Error("error", err))
+		}
+	}
+}
+
+// removeTheme removes the css of the current theme.
+func (r *Runtime) removeTheme() error {
+	r.themeMu.Lock()
+	defer r.themeMu.Unlock()
+
+	r.mu.Lock()
+	files := r.themeFiles
+	r.themeFiles = 0
+	r.mu.Unlock()
+
+	for i := 1; i <= files; i++ {
+		if _, err := r.ui.Eval(`removeCSS("themeCSS` + strconv.Itoa(i) + `");`); err != nil {
+			return fmt.Errorf("could not remove theme css: %w", err)
+		}
+	}
+	r.ui.replaceSetup(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("themeCSS`)
+	}, nil)
+	return nil
+}
+
+// stopThemes stops following the theme schedule.
+func (r *Runtime) stopThemes() {
+	r.mu.Lock()
+	stop := r.themeStop
+	r.themeStop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
@@ -0,0 +1,199 @@
+package glass
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThemesConfig_Validate(t *testing.T) {
+	bundles := map[string][]string{"day": {"day.css"}, "night": {"night.css"}}
+
+	tests := []struct {
+		name    string
+		cfg     ThemesConfig
+		wantErr string
+	}{
+		{
+			name: "valid themes",
+			cfg: ThemesConfig{
+				Default:     "day",
+				ColorScheme: map[string]string{"light": "day", "dark": "night"},
+				Schedule:    []ThemeSchedule{{At: "07:00", Theme: "day"}, {At: "19:30", Theme: "night"}},
+				Bundles:     bundles,
+			},
+		},
+		{
+			name:    "handles unknown default theme",
+			cfg:     ThemesConfig{Default: "dusk", Bundles: bundles},
+			wantErr: `config: default theme "dusk" is not defined`,
+		},
+		{
+			name:    "handles unknown color scheme",
+			cfg:     ThemesConfig{ColorScheme: map[string]string{"sepia": "day"}, Bundles: bundles},
+			wantErr: `config: unknown color scheme "sepia"`,
+		},
+		{
+			name:    "handles unknown color scheme theme",
+			cfg:     ThemesConfig{ColorScheme: map[string]string{"dark": "dusk"}, Bundles: bundles},
+			wantErr: `config: dark color scheme theme "dusk" is not defined`,
+		},
+		{
+			name:    "handles invalid schedule time",
+			cfg:     ThemesConfig{Schedule: []ThemeSchedule{{At: "7pm", Theme: "night"}}, Bundles: bundles},
+			wantErr: `config: invalid theme schedule time "7pm"`,
+		},
+		{
+			name:    "handles unknown scheduled theme",
+			cfg:     ThemesConfig{Schedule: []ThemeSchedule{{At: "19:00", Theme: "dusk"}}, Bundles: bundles},
+			wantErr: `config: scheduled theme "dusk" is not defined`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.cfg.Validate()
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestScheduledTheme(t *testing.T) {
+	switches := themeSwitches([]ThemeSchedule{{At: "19:30", Theme: "night"}, {At: "07:00", Theme: "day"}})
+	day := time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantTheme string
+		wantNext  time.Time
+	}{
+		{
+			name:      "before first switch",
+			now:       day.Add(3 * time.Hour),
+			wantTheme: "night",
+			wantNext:  day.Add(7 * time.Hour),
+		},
+		{
+			name:      "at switch",
+			now:       day.Add(7 * time.Hour),
+			wantTheme: "day",
+			wantNext:  day.Add(19*time.Hour + 30*time.Minute),
+		},
+		{
+			name:      "after last switch",
+			now:       day.Add(22 * time.Hour),
+			wantTheme: "night",
+			wantNext:  day.AddDate(0, 0, 1).Add(7 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			theme, next := scheduledTheme(switches, test.now)
+
+			assert.Equal(t, test.wantTheme, theme)
+			assert.Equal(t, test.wantNext, next)
+		})
+	}
+}
+
+func newThemesRuntime(t *testing.T, themes ThemesConfig) (*Runtime, *RecordingWindow) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name := range themes.Bundles {
+		path := filepath.Join(dir, name+".css")
+		err := os.WriteFile(path, []byte("body { color: "+name+"; }"), 0o600)
+		require.NoError(t, err)
+		themes.Bundles[name] = []string{path}
+	}
+
+	win := NewRecordingWindow()
+	rt := NewRuntime(Config{Themes: themes}, &UI{win: win}, &MockModuleRunner{}, newTestLogger())
+	t.Cleanup(rt.stopThemes)
+	return rt, win
+}
+
+func TestRuntime_SetTheme(t *testing.T) {
+	rt, win := newThemesRuntime(t, ThemesConfig{
+		Bundles: map[string][]string{"day": nil, "night": nil},
+	})
+
+	got := make(chan json.RawMessage, 2)
+	unsub := rt.Events().Subscribe(ThemeTopic, func(e Event) {
+		got <- e.Data
+	})
+	defer unsub()
+
+	err := rt.SetTheme("day")
+	require.NoError(t, err)
+	err = rt.SetTheme("night")
+
+	require.NoError(t, err)
+	assert.Equal(t, "night", rt.Theme())
+	assert.Equal(t, []string{
+		`loadCSS("themeCSS1", "body { color: day; }");`,
+		`removeCSS("themeCSS1");`,
+		`loadCSS("themeCSS1", "body { color: night; }");`,
+	}, win.Evals())
+	assert.Equal(t, []string{`loadCSS("themeCSS1", "body { color: night; }");`}, rt.ui.setup)
+	assert.JSONEq(t, `{"theme":"day"}`, string(<-got))
+	assert.JSONEq(t, `{"theme":"night"}`, string(<-got))
+}
+
+func TestRuntime_SetThemeHandlesUnknownTheme(t *testing.T) {
+	rt, _ := newThemesRuntime(t, ThemesConfig{
+		Bundles: map[string][]string{"day": nil},
+	})
+
+	err := rt.SetTheme("dusk")
+
+	assert.EqualError(t, err, `unknown theme "dusk"`)
+}
+
+func TestRuntime_StartThemesFollowsColorScheme(t *testing.T) {
+	rt, win := newThemesRuntime(t, ThemesConfig{
+		Default:     "day",
+		ColorScheme: map[string]string{"light": "day", "dark": "night"},
+		Bundles:     map[string][]string{"day": nil, "night": nil},
+	})
+
+	err := rt.startThemes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "day", rt.Theme())
+
+	fn, ok := win.Binding("colorSchemeChanged")
+	require.True(t, ok)
+	fn.(func(string))("dark")
+
+	assert.Equal(t, "night", rt.Theme())
+}
+
+func TestRuntime_StartThemesRemovesThemeWhenNotConfigured(t *testing.T) {
+	rt, win := newThemesRuntime(t, ThemesConfig{
+		Default: "day",
+		Bundles: map[string][]string{"day": nil},
+	})
+	err := rt.startThemes(context.Background())
+	require.NoError(t, err)
+
+	rt.cfg.Themes = ThemesConfig{}
+	err = rt.startThemes(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, rt.Theme())
+	assert.Contains(t, win.Evals(), `removeCSS("themeCSS1");`)
+	assert.Empty(t, rt.ui.setup)
+}
@@ -0,0 +1,43 @@
+package glass
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/glasslabs/looking-glass/module/types"
+)
+
+const (
+	defaultTickerSpeed = 50
+	defaultTickerGap   = 40
+)
+
+// tickerEscaper escapes the characters html escaping leaves that are
+// significant in a javascript template literal.
+var tickerEscaper = strings.NewReplacer("`", "&#96;", "$", "&#36;", `\`, "&#92;")
+
+// renderTicker renders the ticker items as html.
+//
+// The items are rendered twice so the track can scroll half its
+// width and wrap around seamlessly.
+func renderTicker(items []string, opts types.TickerOptions) string {
+	speed, gap := opts.Speed, opts.Gap
+	if speed <= 0 {
+		speed = defaultTickerSpeed
+	}
+	if gap <= 0 {
+		gap = defaultTickerGap
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<div class="ticker" style="--ticker-gap: ` + strconv.Itoa(gap) + `px">`)
+	sb.WriteString(`<div class="ticker-track" data-speed="` + strconv.FormatFloat(speed, 'f', -1, 64) + `">`)
+	for i := 0; i < 2; i++ {
+		for _, item := range items {
+			sb.WriteString(`<span class="ticker-item">` + tickerEscaper.Replace(html.EscapeString(item)) + `</span>`)
+		}
+	}
+	sb.WriteString("</div></div>")
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package glass
+
+import (
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTicker(t *testing.T) {
+	got := renderTicker([]string{"Rain later", "<b>`$5`</b>"}, types.TickerOptions{Speed: 80, Gap: 20})
+
+	want := `<div class="ticker" style="--ticker-gap: 20px"><div class="ticker-track" data-speed="80">` +
+		`<span class="ticker-item">Rain later</span><span class="ticker-item">&lt;b&gt;&#96;&#36;5&#96;&lt;/b&gt;</span>` +
+		`<span class="ticker-item">Rain later</span><span class="ticker-item">&lt;b&gt;&#96;&#36;5&#96;&lt;/b&gt;</span>` +
+		`</div></div>`
+	assert.Equal(t, want, got)
+}
+
+func TestRenderTicker_UsesDefaults(t *testing.T) {
+	got := renderTicker([]string{"Rain later"}, types.TickerOptions{})
+
+	assert.Contains(t, got, `style="--ticker-gap: 40px"`)
+	assert.Contains(t, got, `data-speed="50"`)
+}
+
+func TestUIContext_Ticker(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Ticker([]string{"Rain later", "Sun tomorrow"}, types.TickerOptions{Speed: 120})
+
+	require.NoError(t, err)
+	html := win.HTML("test")
+	assert.Contains(t, html, `<span class="ticker-item">Rain later</span>`)
+	assert.Contains(t, html, `<span class="ticker-item">Sun tomorrow</span>`)
+	assert.Contains(t, html, `data-speed="120"`)
+	assert.False(t, uiCtx.lastRendered().IsZero())
+}
@@ -1,14 +1,30 @@
 package glass
 
 import (
+	"bytes"
+	"context"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
+	"io"
+	"net/http"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/glasslabs/looking-glass/internal/i18n"
+	"github.com/glasslabs/looking-glass/internal/logadpt"
 	"github.com/glasslabs/looking-glass/module"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/hamba/logger/v2"
+	logCtx "github.com/hamba/logger/v2/ctx"
 	"github.com/vincent-petithory/dataurl"
 	"github.com/zserge/lorca"
 )
@@ -19,14 +35,165 @@ var (
 
 	//go:embed webui/fonts.css
 	fonts []byte
+
+	//go:embed webui/error.html
+	errorPage string
 )
 
+// ErrClosed is returned when using a closed ui.
+var ErrClosed = types.ErrClosed
+
+// ErrSandboxed is returned when evaluating javascript for a sandboxed module.
+var ErrSandboxed = types.ErrSandboxed
+
+var errorTmpl = template.Must(template.New("error").Parse(errorPage))
+
+const pagePollInterval = 100 * time.Millisecond
+
+// reservedNames are the javascript names defined by the bundled page.
+var reservedNames = map[string]bool{
+	"loadCSS":              true,
+	"createModule":         true,
+	"createModules":        true,
+	"sandboxModule":        true,
+	"renderSandbox":        true,
+	"sandboxes":            true,
+	"sandboxBridge":        true,
+	"allowSandboxFunction": true,
+	"setModuleData":        true,
+	"setModuleZIndex":      true,
+	"preloadFonts":         true,
+	"loadModuleHTML":       true,
+	"appendModuleHTML":     true,
+	"ping":                 true,
+	"setAnimations":        true,
+	"loadTicker":           true,
+	"runModuleScripts":     true,
+	"moduleScriptError":    true,
+	"moduleBounds":         true,
+	"pageBounds":           true,
+	"fadeModule":           true,
+	"removeModule":         true,
+	"applyGrid":            true,
+	"currentOrientation":   true,
+	"orientationChanged":   true,
+	"currentColorScheme":   true,
+	"colorSchemeChanged":   true,
+	"unboundFunctions":     true,
+	"unbindFunction":       true,
+	"rebindFunction":       true,
+	"moduleEvent":          true,
+	"dispatchModuleEvent":  true,
+	"placementGrid":        true,
+	"enableModuleDrag":     true,
+	"disableModuleDrag":    true,
+	"moduleDrag":           true,
+	"makeModuleDraggable":  true,
+	"moduleDropped":        true,
+	"stageBackground":      true,
+	"showBackground":       true,
+	"clearBackground":      true,
+	"backgroundData":       true,
+	"watchActivity":        true,
+	"unwatchActivity":      true,
+	"activityChanged":      true,
+	"activity":             true,
+	"stagedHTML":           true,
+	"stageModuleHTML":      true,
+	"commitModuleHTML":     true,
+	"discardModuleHTML":    true,
+	"setZoom":              true,
+	"removeCSS":            true,
+	"runBatch":             true,
+	"viewportSize":         true,
+	"viewportResized":      true,
+	"setModuleVisible":     true,
+	"notify":               true,
+	"setNetworkOffline":    true,
+	"setScreenBlank":       true,
+	"showSplash":           true,
+	"hideSplash":           true,
+}
+
 // UIConfig contains configuration for the UI.
 type UIConfig struct {
-	Width      int      `yaml:"width"`
-	Height     int      `yaml:"height"`
-	Fullscreen bool     `yaml:"fullscreen"`
-	CustomCSS  []string `yaml:"customCss"`
+	Width       int           `yaml:"width"`
+	Height      int           `yaml:"height"`
+	Fullscreen  bool          `yaml:"fullscreen"`
+	X           int           `yaml:"x"`
+	Y           int           `yaml:"y"`
+	CustomCSS   []string      `yaml:"customCss"`
+	LoadTimeout time.Duration `yaml:"loadTimeout"`
+	Cache       CacheConfig   `yaml:"cache"`
+	Lang        string        `yaml:"lang"`
+	Locales     string        `yaml:"locales"`
+
+	Fonts FontsConfig `yaml:"fonts"`
+
+	// SkipSelfTest skips the javascript bridge self-test at startup.
+	SkipSelfTest bool `yaml:"skipSelfTest"`
+
+	// PreventSleep prevents the display from sleeping while running.
+	PreventSleep bool `yaml:"preventSleep"`
+
+	// EvalTimeout is the maximum time a javascript evaluation may take.
+	// Zero means DefaultEvalTimeout.
+	EvalTimeout time.Duration `yaml:"evalTimeout"`
+
+	// ChromeArgs are extra chrome flags, added after the flags derived from
+	// the configuration so they take precedence where chrome uses the last flag.
+	ChromeArgs []string `yaml:"chromeArgs"`
+
+	// GridColumns is the number of columns modules with a grid placement
+	// are placed in. Zero means DefaultGridColumns.
+	GridColumns int `yaml:"gridColumns"`
+
+	// ZoomFactor is the zoom applied to the page, e.g. 2 on high-DPI displays.
+	// Zero means the page is not zoomed.
+	ZoomFactor float64 `yaml:"zoomFactor"`
+
+	// WindowOpacity is the opacity of the window background, clamped to [0,1].
+	// If nil, the window is opaque.
+	WindowOpacity *float64 `yaml:"windowOpacity"`
+
+	Splash SplashConfig `yaml:"splash"`
+
+	// Display is the number of the display the window is shown on,
+	// starting at 1. If set, X and Y are ignored. Zero means the window
+	// is positioned using X and Y.
+	Display int `yaml:"display"`
+}
+
+// DefaultEvalTimeout is the default maximum time a javascript evaluation may take.
+const DefaultEvalTimeout = 5 * time.Second
+
+// ErrEvalTimeout is returned when a javascript evaluation does not finish in time.
+var ErrEvalTimeout = errors.New("eval timed out")
+
+// ErrRendererNotReady is returned when the page has not finished rendering.
+var ErrRendererNotReady = errors.New("renderer is not ready")
+
+// DefaultGridColumns is the default number of columns of the module placement grid.
+const DefaultGridColumns = 12
+
+// gridColumns returns the number of columns of the module placement grid.
+func (c UIConfig) gridColumns() int {
+	if c.GridColumns <= 0 {
+		return DefaultGridColumns
+	}
+	return c.GridColumns
+}
+
+// opacity returns the clamped window opacity.
+func (c UIConfig) opacity() float64 {
+	switch {
+	case c.WindowOpacity == nil || *c.WindowOpacity > 1:
+		return 1
+	case *c.WindowOpacity < 0:
+		return 0
+	default:
+		return *c.WindowOpacity
+	}
 }
 
 // Validate validates the ui configuration.
@@ -34,91 +201,1009 @@ func (c UIConfig) Validate() error {
 	if c.Width <= 0 || c.Height <= 0 {
 		return errors.New("config: ui width and height muse be greater than zero")
 	}
+	if c.Cache.DiskSize < 0 || c.Cache.MediaSize < 0 {
+		return errors.New("config: ui cache sizes cannot be negative")
+	}
+	if c.GridColumns < 0 {
+		return errors.New("config: ui grid columns cannot be negative")
+	}
+	if c.ZoomFactor < 0 {
+		return errors.New("config: ui zoom factor cannot be negative")
+	}
+	if c.EvalTimeout < 0 {
+		return errors.New("config: ui eval timeout cannot be negative")
+	}
+	if c.Display < 0 {
+		return errors.New("config: ui display cannot be negative")
+	}
+	for _, arg := range c.ChromeArgs {
+		if name := chromeFlagName(arg); reservedChromeFlags[name] {
+			return fmt.Errorf("config: chrome flag %q is managed by looking glass", name)
+		}
+	}
 
 	return nil
 }
 
+// CacheConfig contains configuration for the chrome cache.
+type CacheConfig struct {
+	// DiskSize is the maximum disk cache size in bytes.
+	DiskSize int `yaml:"diskSize"`
+	// MediaSize is the maximum media cache size in bytes.
+	MediaSize int `yaml:"mediaSize"`
+	// Dir is the directory of the disk cache.
+	Dir string `yaml:"dir"`
+}
+
+// FontsConfig contains configuration for font loading.
+type FontsConfig struct {
+	// Preload are the fonts to load at startup, in css font shorthand (e.g. "1em Roboto").
+	Preload []string `yaml:"preload"`
+	// Wait hides modules until all fonts have loaded.
+	Wait bool `yaml:"wait"`
+}
+
+// preloadFontsJS returns the javascript preloading the configured fonts.
+func preloadFontsJS(cfg FontsConfig) string {
+	if len(cfg.Preload) == 0 && !cfg.Wait {
+		return ""
+	}
+
+	fonts := cfg.Preload
+	if fonts == nil {
+		fonts = []string{}
+	}
+	b, _ := json.Marshal(fonts)
+	return "preloadFonts(" + string(b) + ", " + strconv.FormatBool(cfg.Wait) + ");"
+}
+
+// HTMLTransformer transforms the html of a module before it is loaded.
+type HTMLTransformer func(module, html string) (string, error)
+
 // UI implements a ui manager.
 type UI struct {
-	win lorca.UI
+	win     lorca.UI
+	cfg     UIConfig
+	lang    *i18n.Catalog
+	profile string
+	capture func(clip Clip) ([]byte, error)
+	columns int
+	timeout time.Duration
+
+	// globalMu serialises changes to the global bindings of modules.
+	globalMu sync.Mutex
+	// fontsMu serialises loading fonts.
+	fontsMu sync.Mutex
+
+	mu           sync.RWMutex
+	cmds         chan struct{}
+	transformers []HTMLTransformer
+	scriptErrFns []ScriptErrorHandler
+	resizeFns    []ResizeHandler
+	panicFns     []PanicHandler
+	bindings     map[string][]BindingInfo
+	fns          map[string]interface{}
+	unbound      map[string]bool
+	events       map[string]*eventDispatcher
+	eventsBound  bool
+	storeDir     string
+	stores       map[string]*FileStore
+	splash       bool
+	fonts        map[string]bool
+	metrics      *Metrics
+	closed       bool
+
+	setup      []string
+	animations string
+	mods       []*moduleRecord
+	layout     string
+}
+
+// BindingInfo describes a function bound by a module.
+type BindingInfo struct {
+	Name string `json:"name"`
+	Args int    `json:"args"`
 }
 
 // NewUI returns a new UI.
-func NewUI(cfg UIConfig) (*UI, error) {
+func NewUI(cfg UIConfig, log *logger.Logger) (_ *UI, err error) {
+	lang, err := i18n.Load(cfg.Lang, cfg.Locales)
+	if err != nil {
+		return nil, err
+	}
+
+	win, profile, err := openWindow(resolveDisplay(cfg, listDisplays, log))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			closeWindow(win, profile)
+		}
+	}()
+
+	setup := []string{loadCSSJS("fonts", string(fonts))}
+	if op := cfg.opacity(); op < 1 {
+		setup = append(setup, loadCSSJS("opacity", opacityCSS(op)))
+	}
+	css, err := customCSSJS(cfg.CustomCSS, log)
+	if err != nil {
+		return nil, err
+	}
+	setup = append(setup, css...)
+	if js := preloadFontsJS(cfg.Fonts); js != "" {
+		setup = append(setup, js)
+	}
+	if cfg.ZoomFactor > 0 {
+		setup = append(setup, "setZoom("+formatFloat(cfg.ZoomFactor)+");")
+	}
+	for _, js := range setup {
+		if err := newEvalResult(win.Eval(js)).err; err != nil {
+			return nil, fmt.Errorf("could not setup page: %w", err)
+		}
+	}
+
+	ui := &UI{
+		win:     win,
+		cfg:     cfg,
+		lang:    lang,
+		profile: profile,
+		columns: cfg.gridColumns(),
+		timeout: cfg.EvalTimeout,
+		setup:   setup,
+	}
+	ui.capture = func(clip Clip) ([]byte, error) {
+		ui.mu.RLock()
+		profile := ui.profile
+		ui.mu.RUnlock()
+
+		return captureScreenshot(profile, clip)
+	}
+	if err = win.Bind("moduleScriptError", ui.reportScriptError); err != nil {
+		return nil, fmt.Errorf("could not bind script error handler: %w", err)
+	}
+	if err = win.Bind("viewportResized", ui.reportResize); err != nil {
+		return nil, fmt.Errorf("could not bind resize handler: %w", err)
+	}
+	ui.fns = map[string]interface{}{
+		"moduleScriptError": ui.reportScriptError,
+		"viewportResized":   ui.reportResize,
+	}
+	if cfg.Splash.Enabled {
+		if err = ui.showSplash(cfg.Splash); err != nil {
+			return nil, err
+		}
+	}
+	return ui, nil
+}
+
+// customCSSJS returns the javascript loading the custom css files.
+// Remote css that cannot be fetched is skipped with a warning.
+func customCSSJS(paths []string, log *logger.Logger) ([]string, error) {
+	return cssFilesJS("custom css", "customCSS", paths, log)
+}
+
+// cssFilesJS returns the javascript loading the kind of css files at paths,
+// named by prefix and their position. Remote css that cannot be fetched is skipped.
+func cssFilesJS(kind, prefix string, paths []string, log *logger.Logger) ([]string, error) {
+	var js []string
+	for i, cssPath := range paths {
+		name := prefix + strconv.Itoa(i+1)
+		if isRemoteCSS(cssPath) {
+			b, _, err := fetchAsset(cssPath)
+			if err != nil {
+				log.Warn("could not fetch "+kind, logCtx.Str("url", cssPath), logCtx.Error("error", err))
+				continue
+			}
+			js = append(js, loadCSSJS(name, string(b)))
+			continue
+		}
+
+		b, err := readAsset(cssPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s %q: %w", kind, cssPath, err)
+		}
+		js = append(js, loadCSSJS(name, string(b)))
+	}
+	return js, nil
+}
+
+// loadCSSJS returns the javascript loading css as the named style. Both are
+// encoded as json, so fetched css cannot break out of the call.
+func loadCSSJS(name, css string) string {
+	n, _ := json.Marshal(name)
+	b, _ := json.Marshal(css)
+	return "loadCSS(" + string(n) + ", " + string(b) + ");"
+}
+
+// openWindow opens a chrome window with the page loaded, returning
+// the window and its chrome profile directory.
+//
+// The window is closed and its profile removed if it cannot be used.
+func openWindow(cfg UIConfig) (_ lorca.UI, _ string, err error) {
+	url := dataurl.New(page, "text/html")
+	// The profile is created here, rather than by lorca, so the devtools port can be found.
+	profile, err := os.MkdirTemp("", "glass")
+	if err != nil {
+		return nil, "", fmt.Errorf("could not create chrome profile: %w", err)
+	}
+	win, err := lorca.New(url.String(), profile, cfg.Width, cfg.Height, chromeArgs(cfg)...)
+	if err != nil {
+		_ = os.RemoveAll(profile)
+		return nil, "", fmt.Errorf("could not create window: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			closeWindow(win, profile)
+		}
+	}()
+
+	if err = positionWindow(win, cfg); err != nil {
+		return nil, "", err
+	}
+
+	if err = waitForPage(win, cfg.LoadTimeout); err != nil {
+		return nil, "", fmt.Errorf("could not load page: %w", err)
+	}
+	if !cfg.SkipSelfTest {
+		if err = selfTest(win); err != nil {
+			return nil, "", err
+		}
+	}
+	return win, profile, nil
+}
+
+// closeWindow closes a window that could not be used, removing its profile.
+func closeWindow(win lorca.UI, profile string) {
+	_ = win.Close()
+	_ = os.RemoveAll(profile)
+}
+
+// readAsset reads the asset file at path.
+func readAsset(path string) ([]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case fi.IsDir():
+		return nil, errors.New("expected a file, got directory")
+	case !fi.Mode().IsRegular():
+		return nil, errors.New("expected a regular file")
+	}
+	return os.ReadFile(path)
+}
+
+// fetchAssetTimeout is the maximum time fetching a remote asset,
+// such as custom css, may take.
+const fetchAssetTimeout = 10 * time.Second
+
+// maxAssetSize is the maximum size of a remote asset. Larger
+// assets are rejected rather than held in memory.
+const maxAssetSize = 32 << 20
+
+// isRemoteCSS determines if the custom css path is an http url.
+func isRemoteCSS(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// fetchAsset fetches a remote asset, returning its content and content type.
+func fetchAsset(url string) ([]byte, string, error) {
+	c := &http.Client{Timeout: fetchAssetTimeout}
+	resp, err := c.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(b) > maxAssetSize {
+		return nil, "", fmt.Errorf("asset is larger than %d bytes", maxAssetSize)
+	}
+	return b, resp.Header.Get("Content-Type"), nil
+}
+
+// reservedChromeFlags are the chrome flags looking glass relies on.
+// The page is loaded with --app, the profile is used to find the devtools
+// port and the devtools are used for screenshots and window control.
+var reservedChromeFlags = map[string]bool{
+	"--app":                      true,
+	"--user-data-dir":            true,
+	"--remote-debugging-port":    true,
+	"--remote-debugging-pipe":    true,
+	"--remote-debugging-address": true,
+}
+
+// chromeFlagName returns the name of a chrome flag, without its value.
+func chromeFlagName(arg string) string {
+	if i := strings.IndexByte(arg, '='); i >= 0 {
+		return arg[:i]
+	}
+	return arg
+}
+
+// chromeArgs returns the chrome arguments for the configuration.
+func chromeArgs(cfg UIConfig) []string {
 	var args []string
 	if cfg.Fullscreen {
 		args = append(args, "--start-fullscreen")
 	}
-	url := dataurl.New(page, "text/html")
-	win, err := lorca.New(url.String(), "", cfg.Width, cfg.Height, args...)
+	if cfg.X != 0 || cfg.Y != 0 || cfg.Display > 0 {
+		args = append(args, "--window-position="+strconv.Itoa(cfg.X)+","+strconv.Itoa(cfg.Y))
+	}
+	if cfg.Cache.DiskSize > 0 {
+		args = append(args, "--disk-cache-size="+strconv.Itoa(cfg.Cache.DiskSize))
+	}
+	if cfg.Cache.MediaSize > 0 {
+		args = append(args, "--media-cache-size="+strconv.Itoa(cfg.Cache.MediaSize))
+	}
+	if cfg.Cache.Dir != "" {
+		args = append(args, "--disk-cache-dir="+cfg.Cache.Dir)
+	}
+	if cfg.opacity() < 1 {
+		args = append(args, "--enable-transparent-visuals")
+	}
+	return append(args, cfg.ChromeArgs...)
+}
+
+// positionWindow moves the window to the configured position.
+//
+// Chrome makes a window fullscreen on the display it is on, so a fullscreen
+// window is first moved, then made fullscreen again.
+func positionWindow(win lorca.UI, cfg UIConfig) error {
+	if cfg.X == 0 && cfg.Y == 0 && cfg.Display == 0 {
+		return nil
+	}
+
+	err := win.SetBounds(lorca.Bounds{
+		Left:        cfg.X,
+		Top:         cfg.Y,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		WindowState: lorca.WindowStateNormal,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not create window: %w", err)
+		return fmt.Errorf("could not position window: %w", err)
+	}
+	if !cfg.Fullscreen {
+		return nil
+	}
+	if err = win.SetBounds(lorca.Bounds{WindowState: lorca.WindowStateFullscreen}); err != nil {
+		return fmt.Errorf("could not make window fullscreen: %w", err)
+	}
+	return nil
+}
+
+// opacityCSS returns the css making the page background transparent.
+func opacityCSS(op float64) string {
+	return "html, body { background: rgba(0, 0, 0, " + formatFloat(op) + ") !important; }"
+}
+
+// waitForPage waits for the page to respond to a ping.
+func waitForPage(win lorca.UI, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var pong string
+		if err := newEvalResult(win.Eval("ping();")).decode(&pong); err == nil && pong == "pong" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("page did not respond")
+		}
+		time.Sleep(pagePollInterval)
 	}
+}
 
-	val := win.Eval("loadCSS(`fonts`, `" + string(fonts) + "`);")
-	if val.Err() != nil {
-		return nil, fmt.Errorf("could not load fonts: %w", err)
+// selfTest checks the javascript bridge round-trips a known expression.
+func selfTest(win lorca.UI) error {
+	res := newEvalResult(win.Eval("1+1"))
+	if res.err != nil {
+		return fmt.Errorf("bridge self-test failed: %w", res.err)
+	}
+	if got := string(res.bytes()); got != "2" {
+		return fmt.Errorf("bridge self-test failed: expected 1+1 to be 2, got %s", got)
 	}
-	for i, cssPath := range cfg.CustomCSS {
-		b, err := os.ReadFile(cssPath)
+	return nil
+}
+
+// newErrorPage returns a data url of the error page describing err.
+func newErrorPage(lang *i18n.Catalog, msg string, err error) string {
+	var buf bytes.Buffer
+	_ = errorTmpl.Execute(&buf, map[string]string{
+		"Lang":    lang.Lang(),
+		"Title":   lang.T("error.title"),
+		"Message": msg,
+		"Error":   err.Error(),
+	})
+	return dataurl.New(buf.Bytes(), "text/html").String()
+}
+
+// UseHTMLTransformer adds html transformers that are applied, in order,
+// to all module html before it is loaded.
+func (ui *UI) UseHTMLTransformer(fns ...HTMLTransformer) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.transformers = append(ui.transformers, fns...)
+}
+
+func (ui *UI) transformHTML(module, html string) (string, error) {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	var err error
+	for _, fn := range ui.transformers {
+		html, err = fn(module, html)
 		if err != nil {
-			return nil, fmt.Errorf("could not read custom css %q: %w", cssPath, err)
-		}
-		name := "customCSS" + strconv.Itoa(i+1)
-		val := win.Eval("loadCSS(`" + name + "`, `" + string(b) + "`);")
-		if val.Err() != nil {
-			return nil, fmt.Errorf("could not load custom css %q: %w", cssPath, err)
+			return "", err
 		}
 	}
+	return html, nil
+}
 
-	return &UI{
-		win: win,
-	}, nil
+// ScriptErrorHandler handles an error thrown by a module script.
+type ScriptErrorHandler func(module, msg string)
+
+// OnScriptError adds handlers called when a module script throws.
+func (ui *UI) OnScriptError(fns ...ScriptErrorHandler) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.scriptErrFns = append(ui.scriptErrFns, fns...)
+}
+
+// reportScriptError is called from javascript when a module script throws.
+func (ui *UI) reportScriptError(module, msg string) {
+	ui.mu.RLock()
+	fns := ui.scriptErrFns
+	ui.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(module, msg)
+	}
 }
 
 // Bind binds a function into javascript.
 func (ui *UI) Bind(name string, fun interface{}) error {
-	return ui.win.Bind(name, fun)
+	if ui.isClosed() {
+		return ErrClosed
+	}
+	if err := ui.do("bind "+name, func(win lorca.UI) error {
+		return win.Bind(name, fun)
+	}); err != nil {
+		return err
+	}
+
+	ui.mu.Lock()
+	if ui.fns == nil {
+		ui.fns = map[string]interface{}{}
+	}
+	ui.fns[name] = fun
+	unbound := ui.unbound[name]
+	delete(ui.unbound, name)
+	ui.mu.Unlock()
+
+	// Lorca only replaces the function of an existing binding,
+	// so the javascript function removed by Unbind is restored.
+	if unbound {
+		if _, err := ui.Eval(fmt.Sprintf(`rebindFunction("%s");`, name)); err != nil {
+			return fmt.Errorf("could not rebind %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Unbind removes a function bound into javascript.
+//
+// Lorca cannot remove a binding, so the bound function is replaced
+// with one returning an error and the javascript function is removed.
+func (ui *UI) Unbind(name string) error {
+	if ui.isClosed() {
+		return ErrClosed
+	}
+	if err := ui.do("unbind "+name, func(win lorca.UI) error {
+		return win.Bind(name, func() error { return fmt.Errorf("%q is not bound", name) })
+	}); err != nil {
+		return err
+	}
+	if _, err := ui.Eval(fmt.Sprintf(`unbindFunction("%s");`, name)); err != nil {
+		return fmt.Errorf("could not unbind %q: %w", name, err)
+	}
+
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	delete(ui.fns, name)
+	if ui.unbound == nil {
+		ui.unbound = map[string]bool{}
+	}
+	ui.unbound[name] = true
+	return nil
+}
+
+// Bindings returns the functions bound by each module.
+func (ui *UI) Bindings() map[string][]BindingInfo {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	bindings := make(map[string][]BindingInfo, len(ui.bindings))
+	for mod, infos := range ui.bindings {
+		bindings[mod] = append([]BindingInfo(nil), infos...)
+	}
+	return bindings
+}
+
+func (ui *UI) trackBinding(module, name string, fun interface{}) {
+	info := BindingInfo{Name: name}
+	if typ := reflect.TypeOf(fun); typ != nil && typ.Kind() == reflect.Func {
+		info.Args = typ.NumIn()
+	}
+
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	if ui.bindings == nil {
+		ui.bindings = map[string][]BindingInfo{}
+	}
+	infos := ui.bindings[module]
+	for i := range infos {
+		if infos[i].Name == name {
+			infos[i] = info
+			return
+		}
+	}
+	ui.bindings[module] = append(infos, info)
+}
+
+// bindingOwners returns the modules binding name, in module order.
+func (ui *UI) bindingOwners(name string) []string {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	var owners []string
+	for mod, infos := range ui.bindings {
+		for _, info := range infos {
+			if info.Name == name {
+				owners = append(owners, mod)
+				break
+			}
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// untrackBinding removes a binding of the module, returning
+// false if the module did not bind the name.
+func (ui *UI) untrackBinding(module, name string) bool {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	infos := ui.bindings[module]
+	for i := range infos {
+		if infos[i].Name == name {
+			ui.bindings[module] = append(infos[:i], infos[i+1:]...)
+			return true
+		}
+	}
+	return false
 }
 
 // Eval evaluates a javascript expression.
+//
+// If the ui has been closed, ErrClosed is returned.
 func (ui *UI) Eval(js string) (interface{}, error) {
-	v := ui.win.Eval(js)
-	if v.Err() != nil {
-		return nil, v.Err()
+	return ui.moduleEval("", js)
+}
+
+// moduleEval evaluates a javascript expression on behalf of module.
+func (ui *UI) moduleEval(module, js string) (interface{}, error) {
+	var i interface{}
+	err := ui.moduleEvalInto(module, &i, js)
+	return i, err
+}
+
+// EvalInto evaluates a javascript expression, decoding the result into out.
+//
+// If the result is empty, out is left untouched.
+// If the ui has been closed, ErrClosed is returned. If the evaluation does
+// not finish within the eval timeout, an ErrEvalTimeout error is returned.
+func (ui *UI) EvalInto(out interface{}, js string) error {
+	return ui.moduleEvalInto("", out, js)
+}
+
+// moduleEvalInto evaluates a javascript expression on behalf of module,
+// decoding the result into out.
+func (ui *UI) moduleEvalInto(module string, out interface{}, js string) error {
+	ctx, cancel := ui.evalTimeoutContext()
+	defer cancel()
+
+	return ui.evalContext(ctx, module, js).decodeOptional(out)
+}
+
+// EvalIntoStrict evaluates a javascript expression, decoding the result into out.
+//
+// If the result is empty, an ErrEmptyResult error is returned.
+func (ui *UI) EvalIntoStrict(out interface{}, js string) error {
+	return ui.moduleEvalIntoStrict("", out, js)
+}
+
+// moduleEvalIntoStrict evaluates a javascript expression on behalf of module,
+// decoding the result into out.
+func (ui *UI) moduleEvalIntoStrict(module string, out interface{}, js string) error {
+	ctx, cancel := ui.evalTimeoutContext()
+	defer cancel()
+
+	err := ui.evalContext(ctx, module, js).decode(out)
+	if errors.Is(err, ErrEmptyResult) {
+		return fmt.Errorf("%w: %s", ErrEmptyResult, jsPrefix(js))
 	}
+	return err
+}
 
-	if len(v.Bytes()) == 0 {
-		return nil, nil
+// evalTimeoutContext returns a context done after the eval timeout.
+func (ui *UI) evalTimeoutContext() (context.Context, context.CancelFunc) {
+	ui.mu.RLock()
+	timeout := ui.timeout
+	ui.mu.RUnlock()
+	if timeout <= 0 {
+		timeout = DefaultEvalTimeout
 	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// EvalContext evaluates a javascript expression, returning once ctx is done.
+func (ui *UI) EvalContext(ctx context.Context, js string) (interface{}, error) {
+	return ui.moduleEvalContext(ctx, "", js)
+}
 
+// moduleEvalContext evaluates a javascript expression on behalf of module,
+// returning once ctx is done.
+func (ui *UI) moduleEvalContext(ctx context.Context, module, js string) (interface{}, error) {
 	var i interface{}
-	err := v.To(&i)
+	err := ui.evalContext(ctx, module, js).decodeOptional(&i)
 	return i, err
 }
 
+// EvalIntoContext evaluates a javascript expression, decoding the result
+// into out, returning once ctx is done.
+//
+// The evaluation itself cannot be cancelled, it is abandoned when ctx is done.
+func (ui *UI) EvalIntoContext(ctx context.Context, out interface{}, js string) error {
+	return ui.evalContext(ctx, "", js).decodeOptional(out)
+}
+
+// evalContext evaluates a javascript expression on behalf of module, returning
+// once ctx is done. The module is empty for evaluations made by the ui itself.
+func (ui *UI) evalContext(ctx context.Context, module, js string) evalResult {
+	if ui.isClosed() {
+		return evalFailed(ErrClosed)
+	}
+
+	var v lorca.Value
+	err := ui.call(ctx, func(win lorca.UI) {
+		start := time.Now()
+		v = win.Eval(js)
+		if m := ui.evalMetrics(); m != nil {
+			m.observeEval(module, time.Since(start))
+		}
+	})
+	if err != nil {
+		return evalFailed(evalContextError(ctx, js))
+	}
+	return newEvalResult(v)
+}
+
+// evalContextError returns the error of an evaluation abandoned when ctx was done.
+func evalContextError(ctx context.Context, js string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", ErrEvalTimeout, jsPrefix(js))
+	}
+	return ctx.Err()
+}
+
+// jsPrefixLen is the length of javascript included in eval errors.
+const jsPrefixLen = 64
+
+// jsPrefix returns the start of the javascript for use in errors.
+func jsPrefix(js string) string {
+	if len(js) <= jsPrefixLen {
+		return js
+	}
+	i := jsPrefixLen
+	for i > 0 && !utf8.RuneStart(js[i]) {
+		i--
+	}
+	return js[:i] + "..."
+}
+
+// ShowError replaces the page with an error page describing err.
+//
+// The error page is persistent, modules will no longer be visible.
+func (ui *UI) ShowError(msg string, err error) error {
+	if ui.isClosed() {
+		return ErrClosed
+	}
+	return ui.do("show error", func(win lorca.UI) error {
+		return win.Load(newErrorPage(ui.lang, msg, err))
+	})
+}
+
+// T returns the localised message for the given key, formatted with args.
+func (ui *UI) T(key string, args ...interface{}) string {
+	return ui.lang.T(key, args...)
+}
+
+// SetAnimationsEnabled enables or disables all css animations and transitions.
+func (ui *UI) SetAnimationsEnabled(enabled bool) error {
+	js := "setAnimations(" + strconv.FormatBool(enabled) + ");"
+	if _, err := ui.Eval(js); err != nil {
+		return err
+	}
+
+	ui.mu.Lock()
+	ui.animations = js
+	ui.mu.Unlock()
+	return nil
+}
+
+// animationsEnabled determines if css animations and transitions are enabled.
+func (ui *UI) animationsEnabled() bool {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	return ui.animations != "setAnimations(false);"
+}
+
+// Move moves the window to the given position.
+//
+// Negative coordinates are clamped to zero.
+func (ui *UI) Move(x, y int) error {
+	return ui.updateBounds(func(b *lorca.Bounds) {
+		b.Left, b.Top = clampZero(x), clampZero(y)
+	})
+}
+
+// Resize resizes the window to the given size.
+//
+// Negative sizes are clamped to zero.
+func (ui *UI) Resize(w, h int) error {
+	return ui.updateBounds(func(b *lorca.Bounds) {
+		b.Width, b.Height = clampZero(w), clampZero(h)
+	})
+}
+
+func (ui *UI) updateBounds(fn func(b *lorca.Bounds)) error {
+	if ui.isClosed() {
+		return ErrClosed
+	}
+
+	return ui.do("set window bounds", func(win lorca.UI) error {
+		b, err := win.Bounds()
+		if err != nil {
+			return fmt.Errorf("could not get window bounds: %w", err)
+		}
+		fn(&b)
+		// Chrome only accepts a position and size for a normal window.
+		b.WindowState = lorca.WindowStateNormal
+		if err = win.SetBounds(b); err != nil {
+			return fmt.Errorf("could not set window bounds: %w", err)
+		}
+		return nil
+	})
+}
+
+// Screenshot captures what the page currently shows as a png image.
+//
+// If the page has not finished loading, an ErrRendererNotReady error is returned.
+func (ui *UI) Screenshot() ([]byte, error) {
+	if !ui.alive() {
+		return nil, ErrClosed
+	}
+	if ui.capture == nil {
+		return nil, errors.New("screenshots are not supported")
+	}
+
+	var clip Clip
+	if err := ui.EvalInto(&clip, "pageBounds();"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRendererNotReady, err)
+	}
+	if clip.Width <= 0 || clip.Height <= 0 {
+		return nil, ErrRendererNotReady
+	}
+
+	img, err := ui.capture(clip)
+	if err != nil {
+		return nil, fmt.Errorf("could not capture screenshot: %w", err)
+	}
+	return img, nil
+}
+
+func clampZero(v int) int {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
 // Done returns a channel signalling the UI being closed.
 func (ui *UI) Done() <-chan struct{} {
-	return ui.win.Done()
+	return ui.window().Done()
 }
 
 // Close closes the ui.
 func (ui *UI) Close() error {
-	return ui.win.Close()
+	ui.mu.Lock()
+	if ui.closed {
+		ui.mu.Unlock()
+		return nil
+	}
+	ui.closed = true
+	win, profile := ui.win, ui.profile
+	ui.mu.Unlock()
+
+	if err := win.Close(); err != nil {
+		return err
+	}
+	if profile != "" {
+		return os.RemoveAll(profile)
+	}
+	return nil
+}
+
+// do runs fn with the current chrome window, returning the error of fn.
+//
+// If the window is not called within the eval timeout, an ErrEvalTimeout
+// error naming what was called is returned.
+func (ui *UI) do(what string, fn func(win lorca.UI) error) error {
+	ctx, cancel := ui.evalTimeoutContext()
+	defer cancel()
+
+	var err error
+	if cerr := ui.call(ctx, func(win lorca.UI) {
+		err = fn(win)
+	}); cerr != nil {
+		return evalContextError(ctx, what)
+	}
+	return err
+}
+
+// call runs fn with the current chrome window, returning once fn returns
+// or ctx is done.
+//
+// Calls to the window are queued and run one at a time, in the order
+// they were made, so modules can use the ui from any goroutine. A call
+// abandoned when ctx is done keeps its turn until fn returns or the window
+// stops, as lorca never fails the calls pending when chrome stops. fn must
+// not call the ui.
+func (ui *UI) call(ctx context.Context, fn func(win lorca.UI)) error {
+	win, release, err := ui.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan interface{}, 1)
+	go func() {
+		defer release()
+		// Panics are passed to the caller, so they are handled as if called in place.
+		defer func() {
+			done <- recover()
+		}()
+		fn(win)
+	}()
+
+	select {
+	case p := <-done:
+		if p != nil {
+			panic(p)
+		}
+		return nil
+	case <-ctx.Done():
+		go func() {
+			select {
+			case <-done:
+			case <-win.Done():
+				release()
+			}
+		}()
+		return ctx.Err()
+	}
+}
+
+// acquire waits for the turn of the caller to use the current window,
+// returning the window and a function ending the turn. Callers are served
+// in the order they called.
+func (ui *UI) acquire(ctx context.Context) (lorca.UI, func(), error) {
+	ui.mu.Lock()
+	if ui.cmds == nil {
+		ui.cmds = make(chan struct{}, 1)
+	}
+	cmds, win := ui.cmds, ui.win
+	ui.mu.Unlock()
+
+	select {
+	case cmds <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return win, func() {
+		once.Do(func() { <-cmds })
+	}, nil
+}
+
+// window returns the current chrome window.
+func (ui *UI) window() lorca.UI {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	return ui.win
+}
+
+// alive determines if the ui is open and the window is running.
+func (ui *UI) alive() bool {
+	if ui.isClosed() {
+		return false
+	}
+	win := ui.window()
+	if win == nil {
+		return true
+	}
+	select {
+	case <-win.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+func (ui *UI) isClosed() bool {
+	ui.mu.RLock()
+	defer ui.mu.RUnlock()
+
+	return ui.closed
 }
 
 // UIContext implements a UI in context of a module element.
 type UIContext struct {
 	ui   *UI
 	name string
+	log  *logger.Logger
+	bus  *EventBus
+	reg  *ModuleRegistry
+	http *Fetcher
+
+	maxNodes  int
+	refresh   time.Duration
+	sandboxed bool
+	scopedCSS bool
+
+	mu       sync.Mutex
+	rendered time.Time
+	hidden   bool
+	msgs     *eventDispatcher
+	topics   map[string]func()
+	batching bool
+	batched  []string
+
+	async asyncQueue
 }
 
 // NewUIContext returns a ui with the context of a module.
 func NewUIContext(ui *UI, name string, pos module.Position) (*UIContext, error) {
 	name = strings.ReplaceAll(name, " ", "_")
-	if _, err := ui.Eval(fmt.Sprintf(`createModule("%s", "%s", "%s");`, name, pos.Vertical, pos.Horizontal)); err != nil {
+	js := fmt.Sprintf(`createModule("%s", "%s", "%s");`, name, pos.Vertical, pos.Horizontal)
+	if _, err := ui.Eval(js); err != nil {
 		return nil, fmt.Errorf("%s: could not create module ui element: %w", name, err)
 	}
+	ui.recordModule(name, js)
 
 	return &UIContext{
 		ui:   ui,
@@ -126,24 +1211,627 @@ func NewUIContext(ui *UI, name string, pos module.Position) (*UIContext, error)
 	}, nil
 }
 
+// NewUIContexts returns ui contexts for the modules, creating all module
+// elements in a single call.
+//
+// If a module element cannot be created, its context is nil and its error
+// is returned, along with those of other failed modules as ModuleErrors.
+func NewUIContexts(ui *UI, descs []module.Descriptor) ([]*UIContext, error) {
+	uiCtxs, errs, err := newUIContexts(ui, descs)
+	if err != nil {
+		return nil, err
+	}
+	return uiCtxs, moduleErrors(errs)
+}
+
+// newUIContexts returns ui contexts for the modules, along with the error
+// of each module whose element could not be created. If the elements could
+// not be created at all, only an error is returned.
+func newUIContexts(ui *UI, descs []module.Descriptor) ([]*UIContext, []error, error) {
+	type gridSpec struct {
+		Columns int `json:"columns"`
+		Column  int `json:"column"`
+		Span    int `json:"span"`
+		Row     int `json:"row"`
+	}
+	type spec struct {
+		Name    string    `json:"name"`
+		Vert    string    `json:"vert"`
+		Horiz   string    `json:"horiz"`
+		Grid    *gridSpec `json:"grid,omitempty"`
+		ZIndex  int       `json:"zIndex,omitempty"`
+		Sandbox *[]string `json:"sandbox,omitempty"`
+	}
+
+	columns := ui.columns
+	if columns <= 0 {
+		columns = DefaultGridColumns
+	}
+	specs := make([]spec, len(descs))
+	for i, desc := range descs {
+		specs[i] = spec{Name: strings.ReplaceAll(desc.InstanceID(), " ", "_"), ZIndex: desc.ZIndex}
+		if desc.Sandbox.Enabled {
+			// The permissions are never nil, so a sandbox without permissions is still created.
+			allow := append([]string{}, desc.Sandbox.Allow...)
+			specs[i].Sandbox = &allow
+		}
+		// Modules in a grid area are created in the grid.
+		if desc.Area != "" {
+			continue
+		}
+		specs[i].Vert = desc.Position.Vertical
+		specs[i].Horiz = desc.Position.Horizontal
+		if desc.Grid != nil {
+			specs[i].Grid = &gridSpec{
+				Columns: columns,
+				Column:  desc.Grid.Column,
+				Span:    desc.Grid.ColumnSpan(),
+				Row:     desc.Grid.GridRow(),
+			}
+		}
+	}
+	b, err := json.Marshal(specs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode modules: %w", err)
+	}
+
+	res, err := ui.Eval("createModules(" + string(b) + ");")
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create module ui elements: %w", err)
+	}
+	status, _ := res.(map[string]interface{})
+
+	errs := make([]error, len(specs))
+	uiCtxs := make([]*UIContext, len(specs))
+	for i, s := range specs {
+		msg, ok := status[s.Name].(string)
+		switch {
+		case !ok:
+			msg = "no status returned"
+			fallthrough
+		case msg != "":
+			errs[i] = fmt.Errorf("%s: could not create module ui element: %s", s.Name, msg)
+			continue
+		}
+
+		js := fmt.Sprintf(`createModule("%s", "%s", "%s");`, s.Name, s.Vert, s.Horiz)
+		switch {
+		case s.ZIndex != 0:
+			g, _ := json.Marshal(s.Grid)
+			js = fmt.Sprintf(`createModule("%s", "%s", "%s", %s, %d);`, s.Name, s.Vert, s.Horiz, g, s.ZIndex)
+		case s.Grid != nil:
+			g, _ := json.Marshal(s.Grid)
+			js = fmt.Sprintf(`createModule("%s", "%s", "%s", %s);`, s.Name, s.Vert, s.Horiz, g)
+		}
+		ui.recordModule(s.Name, js)
+		if s.Sandbox != nil {
+			allow, _ := json.Marshal(s.Sandbox)
+			sandbox := fmt.Sprintf(`sandboxModule("%s", %s);`, s.Name, allow)
+			ui.updateModule(s.Name, func(rec *moduleRecord) {
+				rec.sandbox = []string{sandbox}
+			})
+		}
+		uiCtxs[i] = &UIContext{
+			ui:        ui,
+			name:      s.Name,
+			refresh:   descs[i].Refresh,
+			sandboxed: s.Sandbox != nil,
+			scopedCSS: descs[i].ScopedCSS,
+		}
+	}
+	return uiCtxs, errs, nil
+}
+
 // LoadCSS loads a css style into the ui.
+//
+// If the module css is scoped, the selectors are limited to the module element.
 func (u *UIContext) LoadCSS(css string) error {
-	_, err := u.ui.Eval(fmt.Sprintf("loadCSS(`%s`, `%s`);", u.name, css))
-	return err
+	if u.scopedCSS && !u.sandboxed {
+		css = scopeCSS(css, moduleScope(u.name))
+	}
+	js := fmt.Sprintf("loadCSS(`%s`, `%s`);", u.name, css)
+	if _, err := u.eval(js); err != nil {
+		return err
+	}
+	u.ui.updateModule(u.name, func(rec *moduleRecord) {
+		rec.css = append(rec.css, js)
+	})
+	return nil
 }
 
+// htmlChunkSize is the size above which module html is loaded in chunks,
+// keeping each evaluation below the size limit of the devtools channel.
+const htmlChunkSize = 256 << 10
+
 // LoadHTML loads html into the module.
+//
+// Large html is staged in chunks and only loaded once all chunks have been
+// received, so a failure leaves the current module html in place.
 func (u *UIContext) LoadHTML(html string) error {
-	_, err := u.ui.Eval(fmt.Sprintf("loadModuleHTML(`%s`, `%s`);", u.name, html))
+	return u.loadHTML(html, false)
+}
+
+// loadHTML loads html into the module. If escape is true, the html is
+// escaped so it evaluates to itself in the javascript template literal.
+func (u *UIContext) loadHTML(html string, escape bool) error {
+	html, err := u.ui.transformHTML(u.name, html)
+	if err != nil {
+		return fmt.Errorf("%s: could not transform html: %w", u.name, err)
+	}
+	if escape {
+		html = templateLiteralEscaper.Replace(html)
+	}
+
+	js := []string{fmt.Sprintf("loadModuleHTML(`%s`, `%s`);", u.name, html)}
+	if len(html) > htmlChunkSize {
+		js, err = u.loadChunkedHTML(html)
+	} else {
+		_, err = u.eval(js[0])
+	}
+	if err != nil {
+		return err
+	}
+	u.ui.updateModule(u.name, func(rec *moduleRecord) {
+		rec.load = js
+		rec.html = nil
+	})
+	u.markRendered()
+	return nil
+}
+
+// loadChunkedHTML stages the html in chunks before loading it, returning
+// the evaluated javascript.
+func (u *UIContext) loadChunkedHTML(html string) ([]string, error) {
+	var js []string
+	for _, chunk := range splitHTML(html, htmlChunkSize) {
+		js = append(js, fmt.Sprintf("stageModuleHTML(`%s`, `%s`);", u.name, chunk))
+	}
+	js = append(js, fmt.Sprintf("commitModuleHTML(`%s`);", u.name))
+
+	for _, s := range js {
+		if _, err := u.eval(s); err != nil {
+			if _, derr := u.ui.moduleEval(u.name, fmt.Sprintf("discardModuleHTML(`%s`);", u.name)); derr != nil {
+				return nil, fmt.Errorf("%w (could not discard staged html: %v)", err, derr)
+			}
+			return nil, err
+		}
+	}
+	return js, nil
+}
+
+// splitHTML splits html into chunks of at most size bytes.
+//
+// Chunks are split before a tag where possible, and never within a rune or
+// after a '\\' or '$', so each chunk evaluates the same in a template literal
+// as it would as part of the whole html.
+func splitHTML(html string, size int) []string {
+	var chunks []string
+	for len(html) > size {
+		i := strings.LastIndexByte(html[:size], '<')
+		for i > 0 && isTemplateSpecial(html[i-1]) {
+			i = strings.LastIndexByte(html[:i-1], '<')
+		}
+		if i <= 0 {
+			i = size
+			for i > 0 && (!utf8.RuneStart(html[i]) || isTemplateSpecial(html[i-1])) {
+				i--
+			}
+			if i == 0 {
+				// No safe split exists, leave the html whole.
+				break
+			}
+		}
+		chunks = append(chunks, html[:i])
+		html = html[i:]
+	}
+	return append(chunks, html)
+}
+
+func isTemplateSpecial(c byte) bool {
+	return c == '\\' || c == '$'
+}
+
+// AppendHTML appends html to the module.
+//
+// If a maximum number of nodes has been set, the oldest nodes
+// are removed once the module exceeds it.
+func (u *UIContext) AppendHTML(html string) error {
+	html, err := u.ui.transformHTML(u.name, html)
+	if err != nil {
+		return fmt.Errorf("%s: could not transform html: %w", u.name, err)
+	}
+
+	js := fmt.Sprintf("appendModuleHTML(`%s`, `%s`, %d);", u.name, html, u.maxNodes)
+	if _, err = u.eval(js); err != nil {
+		return err
+	}
+	u.ui.updateModule(u.name, func(rec *moduleRecord) {
+		rec.html = append(rec.html, js)
+		if u.maxNodes > 0 && len(rec.html) > u.maxNodes+1 {
+			rec.html = rec.html[len(rec.html)-u.maxNodes-1:]
+		}
+	})
+	u.markRendered()
+	return nil
+}
+
+func (u *UIContext) markRendered() {
+	u.mu.Lock()
+	u.rendered = time.Now()
+	u.mu.Unlock()
+}
+
+// lastRendered returns the time html was last rendered into the module.
+func (u *UIContext) lastRendered() time.Time {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.rendered
+}
+
+// SetMaxNodes sets the maximum number of nodes kept in the module
+// when appending html. Zero means no limit.
+func (u *UIContext) SetMaxNodes(n int) {
+	if n < 0 {
+		n = 0
+	}
+	u.maxNodes = n
+}
+
+// Logger returns the logger of the module, tagged with the module name and position.
+func (u *UIContext) Logger() types.Logger {
+	return logadpt.LogAdapter{Log: u.logger()}
+}
+
+func (u *UIContext) logger() *logger.Logger {
+	if u.log == nil {
+		return logger.New(io.Discard, logger.LogfmtFormat(), logger.Error)
+	}
+	return u.log
+}
+
+// RefreshInterval returns the configured interval the module is
+// refreshed on. It is zero when the module is not refreshed.
+func (u *UIContext) RefreshInterval() time.Duration {
+	return u.refresh
+}
+
+// RenderChart renders a chart into the module.
+func (u *UIContext) RenderChart(spec types.ChartSpec) error {
+	html, err := renderChart(spec, u.ui.lang)
+	if err != nil {
+		return fmt.Errorf("%s: %w", u.name, err)
+	}
+	return u.LoadHTML(html)
+}
+
+// Ticker renders a horizontally scrolling ticker of items into the module.
+//
+// Calling Ticker again swaps the items without restarting the scroll.
+func (u *UIContext) Ticker(items []string, opts types.TickerOptions) error {
+	js := fmt.Sprintf("loadTicker(`%s`, `%s`);", u.name, renderTicker(items, opts))
+	if _, err := u.eval(js); err != nil {
+		return err
+	}
+	u.ui.updateModule(u.name, func(rec *moduleRecord) {
+		rec.load = []string{js}
+		rec.html = nil
+	})
+	u.markRendered()
+	return nil
+}
+
+// moduleFadeDuration is the duration of module fade transitions.
+const moduleFadeDuration = 500 * time.Millisecond
+
+// fade transitions the opacity of the module to the given value,
+// waiting for the transition to finish.
+func (u *UIContext) fade(to float64) error {
+	js := fmt.Sprintf(`fadeModule("%s", %s, %d);`, u.name, strconv.FormatFloat(to, 'f', -1, 64), moduleFadeDuration.Milliseconds())
+	_, err := u.ui.moduleEval(u.name, js)
 	return err
 }
 
+// SetVisible shows or hides the module element. The element keeps its
+// html and bound functions while hidden, so showing it again is instant.
+func (u *UIContext) SetVisible(visible bool) error {
+	js := fmt.Sprintf(`setModuleVisible("%s", %t);`, u.name, visible)
+	if _, err := u.eval(js); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	u.hidden = !visible
+	u.mu.Unlock()
+
+	u.ui.updateModule(u.name, func(rec *moduleRecord) {
+		rec.hidden = ""
+		if !visible {
+			rec.hidden = js
+		}
+	})
+	return nil
+}
+
+// SetData attaches a json payload to the module element as its data property,
+// dispatching a "data" event on the element.
+//
+// Data set before the html is loaded is available to the module scripts
+// when they first run, so the first paint can already show it.
+func (u *UIContext) SetData(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: could not encode data: %w", u.name, err)
+	}
+	name, _ := json.Marshal(u.name)
+	js := fmt.Sprintf("setModuleData(%s, %s);", name, b)
+	if _, err = u.eval(js); err != nil {
+		return err
+	}
+
+	u.ui.updateModule(u.name, func(rec *moduleRecord) {
+		rec.data = js
+	})
+	return nil
+}
+
+// Visible determines if the module element is shown. Modules are visible when created.
+func (u *UIContext) Visible() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return !u.hidden
+}
+
+// Close removes the module element, its bound functions
+// and event handlers from the ui.
+func (u *UIContext) Close() error {
+	u.closeEvents()
+	u.closeMessages()
+	if u.reg != nil {
+		u.reg.remove(u.name)
+	}
+	for _, info := range u.ui.Bindings()[u.name] {
+		if err := u.Unbind(info.Name); err != nil {
+			return err
+		}
+	}
+	if _, err := u.ui.moduleEval(u.name, fmt.Sprintf(`removeModule("%s");`, u.name)); err != nil {
+		return err
+	}
+	u.ui.forgetModule(u.name)
+	return nil
+}
+
+// Screenshot captures the module element as a png image.
+func (u *UIContext) Screenshot() ([]byte, error) {
+	if u.ui.capture == nil {
+		return nil, fmt.Errorf("%s: screenshots are not supported", u.name)
+	}
+
+	res, err := u.ui.moduleEval(u.name, fmt.Sprintf("moduleBounds(`%s`);", u.name))
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not read module bounds: %w", u.name, err)
+	}
+	var clip Clip
+	if err = json.Unmarshal(b, &clip); err != nil {
+		return nil, fmt.Errorf("%s: could not read module bounds: %w", u.name, err)
+	}
+	if clip.Width <= 0 || clip.Height <= 0 {
+		return nil, fmt.Errorf("%s: module is not visible", u.name)
+	}
+
+	img, err := u.ui.capture(clip)
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not capture screenshot: %w", u.name, err)
+	}
+	return img, nil
+}
+
 // Bind binds a function into javascript.
 func (u *UIContext) Bind(name string, fun interface{}) error {
-	return u.ui.Bind(name, fun)
+	if reservedNames[name] {
+		return fmt.Errorf("%s: could not bind %q: name is reserved", u.name, name)
+	}
+	if err := checkBinding(fun); err != nil {
+		return fmt.Errorf("%s: could not bind %q: %w", u.name, name, err)
+	}
+	// The function is bound in the namespace of the module instance, so
+	// instances of the same module do not replace each others functions.
+	if err := u.ui.Bind(moduleBinding(u.name, name), u.guardBinding(fun)); err != nil {
+		return err
+	}
+	if err := u.bindGlobal(name, func() { u.ui.trackBinding(u.name, name, fun) }); err != nil {
+		return err
+	}
+
+	if u.sandboxed {
+		// Sandboxed scripts can only call the functions bound by their module.
+		js := fmt.Sprintf(`allowSandboxFunction("%s", "%s");`, u.name, name)
+		if _, err := u.ui.moduleEval(u.name, js); err != nil {
+			return fmt.Errorf("%s: could not allow %q in sandbox: %w", u.name, name, err)
+		}
+		u.ui.updateModule(u.name, func(rec *moduleRecord) {
+			rec.sandbox = append(rec.sandbox, js)
+		})
+	}
+	return nil
+}
+
+// Unbind removes a function bound by the module from javascript.
+//
+// Unbinding a name the module has not bound does nothing.
+func (u *UIContext) Unbind(name string) error {
+	var bound bool
+	if err := u.bindGlobal(name, func() { bound = u.ui.untrackBinding(u.name, name) }); err != nil || !bound {
+		return err
+	}
+	return u.ui.Unbind(moduleBinding(u.name, name))
+}
+
+// bindGlobal applies the change to the module bindings in track, then
+// updates the global binding of name.
+//
+// The global name is only bound while a single module instance binds it,
+// calling the function of that instance. Once several instances bind the
+// name it is ambiguous, and the functions can only be called through
+// the namespace of each instance.
+func (u *UIContext) bindGlobal(name string, track func()) error {
+	u.ui.globalMu.Lock()
+	defer u.ui.globalMu.Unlock()
+
+	track()
+
+	owners := u.ui.bindingOwners(name)
+	u.ui.mu.RLock()
+	_, bound := u.ui.fns[name]
+	fn := u.ui.fns[moduleBinding(u.name, name)]
+	if len(owners) == 1 {
+		fn = u.ui.fns[moduleBinding(owners[0], name)]
+	}
+	u.ui.mu.RUnlock()
+
+	switch {
+	case len(owners) == 1:
+		return u.ui.Bind(name, fn)
+	case bound:
+		if len(owners) > 1 {
+			u.logger().Warn("function is bound by several module instances, it can only be called through the module element",
+				logCtx.Str("function", name), logCtx.Strs("modules", owners))
+		}
+		return u.ui.Unbind(name)
+	}
+	return nil
+}
+
+// moduleBinding returns the name a function is bound as in
+// the namespace of the module.
+func moduleBinding(module, name string) string {
+	return module + "." + name
 }
 
 // Eval evaluates a javascript expression.
+//
+// While batching, the expression is queued and no result is returned.
 func (u *UIContext) Eval(js string, ctx ...interface{}) (interface{}, error) {
-	return u.ui.Eval(fmt.Sprintf(js, ctx...))
+	if err := u.sandboxError(); err != nil {
+		return nil, err
+	}
+	js = fmt.Sprintf(js, ctx...)
+	if u.queue(js) {
+		return nil, nil
+	}
+	_ = u.async.wait(context.Background())
+
+	v, err := u.ui.moduleEval(u.name, js)
+	return v, u.evalError(err)
+}
+
+// EvalContext evaluates a javascript expression, returning once ctx is done.
+func (u *UIContext) EvalContext(ctx context.Context, js string, args ...interface{}) (interface{}, error) {
+	if err := u.sandboxError(); err != nil {
+		return nil, err
+	}
+	js = fmt.Sprintf(js, args...)
+	if u.queue(js) {
+		return nil, nil
+	}
+	if err := u.async.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	v, err := u.ui.moduleEvalContext(ctx, u.name, js)
+	return v, u.evalError(err)
+}
+
+// EvalInto evaluates a javascript expression, decoding the result into out.
+//
+// If the result is empty, or the expression is queued while batching,
+// out is left untouched.
+func (u *UIContext) EvalInto(out interface{}, js string, ctx ...interface{}) error {
+	if err := u.sandboxError(); err != nil {
+		return err
+	}
+	js = fmt.Sprintf(js, ctx...)
+	if u.queue(js) {
+		return nil
+	}
+	_ = u.async.wait(context.Background())
+
+	return u.evalError(u.ui.moduleEvalInto(u.name, out, js))
+}
+
+// EvalIntoStrict evaluates a javascript expression, decoding the result into out.
+//
+// If the result is empty, an ErrEmptyResult error is returned. While batching
+// the expression is not evaluated, and an ErrBatching error is returned.
+func (u *UIContext) EvalIntoStrict(out interface{}, js string, args ...interface{}) error {
+	if err := u.sandboxError(); err != nil {
+		return err
+	}
+	js = fmt.Sprintf(js, args...)
+	if u.isBatching() {
+		return fmt.Errorf("%w: %s", ErrBatching, jsPrefix(js))
+	}
+	_ = u.async.wait(context.Background())
+
+	return u.evalError(u.ui.moduleEvalIntoStrict(u.name, out, js))
+}
+
+// EvalBool evaluates a javascript expression with a boolean result.
+//
+// If the result is empty, false is returned.
+func (u *UIContext) EvalBool(js string, args ...interface{}) (bool, error) {
+	var b bool
+	err := u.EvalInto(&b, js, args...)
+	return b, err
+}
+
+// EvalString evaluates a javascript expression with a string result.
+//
+// If the result is empty, an empty string is returned.
+func (u *UIContext) EvalString(js string, args ...interface{}) (string, error) {
+	var s string
+	err := u.EvalInto(&s, js, args...)
+	return s, err
+}
+
+// EvalInt evaluates a javascript expression with an integer result.
+//
+// If the result is empty, zero is returned.
+func (u *UIContext) EvalInt(js string, args ...interface{}) (int, error) {
+	var i int
+	err := u.EvalInto(&i, js, args...)
+	return i, err
+}
+
+// EvalFloat evaluates a javascript expression with a numeric result.
+//
+// If the result is empty, zero is returned.
+func (u *UIContext) EvalFloat(js string, args ...interface{}) (float64, error) {
+	var f float64
+	err := u.EvalInto(&f, js, args...)
+	return f, err
+}
+
+// sandboxError returns an error when the module is sandboxed. The module
+// is rendered in an iframe, so javascript evaluated in the page would not
+// reach the module HTML.
+func (u *UIContext) sandboxError() error {
+	if u.sandboxed {
+		return fmt.Errorf("%s: %w", u.name, ErrSandboxed)
+	}
+	return nil
+}
+
+// evalError adds the module to eval timeout errors.
+func (u *UIContext) evalError(err error) error {
+	if errors.Is(err, ErrEvalTimeout) {
+		return fmt.Errorf("%s: %w", u.name, err)
+	}
+	return err
 }
@@ -1,13 +1,23 @@
 package glass
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	. "github.com/agiledragon/gomonkey/v2"
 	"github.com/glasslabs/looking-glass/module"
+	"github.com/hamba/logger/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -24,12 +34,17 @@ func TestNewUI(t *testing.T) {
 		},
 	}
 	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
 	ui.On("Eval", mock.MatchedBy(func(js string) bool {
-		return strings.HasPrefix(js, "loadCSS(`fonts`")
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
 	})).Once().Return(NewValue("", nil))
-	ui.On("Eval", "loadCSS(`customCSS1`, `custom css`);").Once().Return(NewValue("", nil))
+	ui.On("Eval", `loadCSS("customCSS1", "custom css");`).Once().Return(NewValue("", nil))
 
 	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
 		assert.Equal(t, 1024, width)
 		assert.Equal(t, 764, height)
 		assert.Equal(t, 1024, width)
@@ -41,102 +56,1198 @@ func TestNewUI(t *testing.T) {
 		patches.Reset()
 	})
 
-	got, err := NewUI(cfg)
+	got, err := NewUI(cfg, newTestLogger())
 
 	require.NoError(t, err)
 	assert.IsType(t, (*UI)(nil), got)
 	ui.AssertExpectations(t)
 }
 
+func TestFetchAsset_RejectsLargeAssets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(make([]byte, maxAssetSize+1))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, _, err := fetchAsset(srv.URL)
+
+	assert.EqualError(t, err, "asset is larger than 33554432 bytes")
+}
+
+func TestNewUI_FetchesRemoteCustomCSS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/theme.css" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = rw.Write([]byte("remote css"))
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := UIConfig{
+		Width:  1024,
+		Height: 764,
+		CustomCSS: []string{
+			srv.URL + "/missing.css",
+			srv.URL + "/theme.css",
+			"testdata/custom.css",
+		},
+		SkipSelfTest: true,
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+	ui.On("Eval", `loadCSS("customCSS2", "remote css");`).Once().Return(NewValue("", nil))
+	ui.On("Eval", `loadCSS("customCSS3", "custom css");`).Once().Return(NewValue("", nil))
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	_, err := NewUI(cfg, log)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `msg="could not fetch custom css" url=`+srv.URL+`/missing.css error="unexpected status code 404"`)
+	ui.AssertExpectations(t)
+}
+
+func TestCSSFilesJS_EncodesCSS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("body { content: `${alert(1)}`; }"))
+	}))
+	t.Cleanup(srv.Close)
+
+	got, err := cssFilesJS("custom css", "customCSS", []string{srv.URL}, newTestLogger())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`loadCSS("customCSS1", "body { content: ` + "`${alert(1)}`" + `; }");`}, got)
+}
+
+func TestNewUI_PassesCacheArgs(t *testing.T) {
+	cfg := UIConfig{
+		Width:  1024,
+		Height: 764,
+		Cache: CacheConfig{
+			DiskSize:  1048576,
+			MediaSize: 2097152,
+			Dir:       "/tmp/glass-cache",
+		},
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		assert.Contains(t, customArgs, "--disk-cache-size=1048576")
+		assert.Contains(t, customArgs, "--media-cache-size=2097152")
+		assert.Contains(t, customArgs, "--disk-cache-dir=/tmp/glass-cache")
+		assert.NotContains(t, customArgs, "--start-fullscreen")
+
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+}
+
+func TestNewUI_PassesCustomChromeArgs(t *testing.T) {
+	cfg := UIConfig{
+		Width:      1024,
+		Height:     764,
+		Fullscreen: true,
+		ChromeArgs: []string{"--kiosk", "--disable-pinch", "--disk-cache-size=1"},
+		Cache: CacheConfig{
+			DiskSize: 1048576,
+		},
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		want := []string{"--start-fullscreen", "--disk-cache-size=1048576", "--kiosk", "--disable-pinch", "--disk-cache-size=1"}
+		assert.Equal(t, want, customArgs)
+
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+}
+
+func TestNewUI_SetsWindowOpacity(t *testing.T) {
+	opacity := 0.5
+	cfg := UIConfig{
+		Width:         1024,
+		Height:        764,
+		WindowOpacity: &opacity,
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+	ui.On("Eval", `loadCSS("opacity", "html, body { background: rgba(0, 0, 0, 0.5) !important; }");`).Once().Return(NewValue("", nil))
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		assert.Contains(t, customArgs, "--enable-transparent-visuals")
+
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+}
+
+func TestNewUI_SetsZoomFactor(t *testing.T) {
+	cfg := UIConfig{
+		Width:      1024,
+		Height:     764,
+		ZoomFactor: 1.5,
+		CustomCSS: []string{
+			"testdata/custom.css",
+		},
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+	ui.On("Eval", `loadCSS("customCSS1", "custom css");`).Once().Return(NewValue("", nil))
+	ui.On("Eval", "setZoom(1.5);").Once().Return(NewValue("", nil))
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	got, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+	// The zoom is applied after the page css, and is replayed with it.
+	assert.Equal(t, "setZoom(1.5);", got.setup[len(got.setup)-1])
+	assert.Contains(t, got.setup, `loadCSS("customCSS1", "custom css");`)
+}
+
+func TestNewUI_PreloadsFonts(t *testing.T) {
+	cfg := UIConfig{
+		Width:  1024,
+		Height: 764,
+		Fonts: FontsConfig{
+			Preload: []string{"1em Roboto", "bold 1em Roboto"},
+			Wait:    true,
+		},
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+	ui.On("Eval", `preloadFonts(["1em Roboto","bold 1em Roboto"], true);`).Once().Return(NewValue("", nil))
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+}
+
+func TestPreloadFontsJS(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  FontsConfig
+		want string
+	}{
+		{
+			name: "no fonts",
+			cfg:  FontsConfig{},
+			want: "",
+		},
+		{
+			name: "preload without waiting",
+			cfg:  FontsConfig{Preload: []string{"1em Roboto"}},
+			want: `preloadFonts(["1em Roboto"], false);`,
+		},
+		{
+			name: "wait for all fonts",
+			cfg:  FontsConfig{Wait: true},
+			want: `preloadFonts([], true);`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, preloadFontsJS(test.cfg))
+		})
+	}
+}
+
+func TestUIConfig_Opacity(t *testing.T) {
+	float64Ptr := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name    string
+		opacity *float64
+		want    float64
+	}{
+		{name: "unset", opacity: nil, want: 1},
+		{name: "transparent", opacity: float64Ptr(0.25), want: 0.25},
+		{name: "fully transparent", opacity: float64Ptr(0), want: 0},
+		{name: "clamps negative", opacity: float64Ptr(-1), want: 0},
+		{name: "clamps above one", opacity: float64Ptr(1.5), want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := UIConfig{WindowOpacity: test.opacity}
+
+			assert.Equal(t, test.want, cfg.opacity())
+		})
+	}
+}
+
+func TestNewUI_HandlesSelfTestFailure(t *testing.T) {
+	cfg := UIConfig{
+		Width:  1024,
+		Height: 764,
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`"11"`, nil))
+	ui.On("Close").Once().Return(nil)
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	assert.EqualError(t, err, `bridge self-test failed: expected 1+1 to be 2, got "11"`)
+	ui.AssertExpectations(t)
+}
+
+func TestNewUI_PositionsWindow(t *testing.T) {
+	cfg := UIConfig{
+		Width:        1024,
+		Height:       764,
+		Fullscreen:   true,
+		X:            1920,
+		Y:            10,
+		SkipSelfTest: true,
+	}
+	ui := &MockLorcaUI{}
+	ui.On("SetBounds", lorca.Bounds{Left: 1920, Top: 10, Width: 1024, Height: 764, WindowState: lorca.WindowStateNormal}).Once().Return(nil)
+	ui.On("SetBounds", lorca.Bounds{WindowState: lorca.WindowStateFullscreen}).Once().Return(nil)
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		assert.Contains(t, customArgs, "--start-fullscreen")
+		assert.Contains(t, customArgs, "--window-position=1920,10")
+
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+}
+
+func TestNewUI_SkipsSelfTest(t *testing.T) {
+	cfg := UIConfig{
+		Width:        1024,
+		Height:       764,
+		SkipSelfTest: true,
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, `loadCSS("fonts"`)
+	})).Once().Return(NewValue("", nil))
+	ui.On("Bind", "moduleScriptError", mock.Anything).Once().Return(nil)
+	ui.On("Bind", "viewportResized", mock.Anything).Once().Return(nil)
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.NoError(t, err)
+	ui.AssertExpectations(t)
+}
+
+func TestNewUI_HandlesCustomCSSDirectory(t *testing.T) {
+	dir := t.TempDir()
+	cfg := UIConfig{
+		Width:     1024,
+		Height:    764,
+		CustomCSS: []string{dir},
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Once().Return(NewValue(`"pong"`, nil))
+	ui.On("Eval", "1+1").Once().Return(NewValue(`2`, nil))
+	ui.On("Close").Once().Return(nil)
+
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	assert.EqualError(t, err, fmt.Sprintf("could not read custom css %q: expected a file, got directory", dir))
+	ui.AssertExpectations(t)
+}
+
 func TestNewUI_HandlesWindowError(t *testing.T) {
 	cfg := UIConfig{
 		Width:  1024,
 		Height: 764,
 	}
 
-	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
-		return nil, errors.New("test error")
-	})
-	t.Cleanup(func() {
-		patches.Reset()
-	})
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		return nil, errors.New("test error")
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "could not create window: test error")
+}
+
+func TestNewUI_HandlesPageLoadError(t *testing.T) {
+	cfg := UIConfig{
+		Width:       1024,
+		Height:      764,
+		LoadTimeout: 10 * time.Millisecond,
+	}
+	ui := &MockLorcaUI{}
+	ui.On("Eval", "ping();").Return(NewValue("", nil))
+	ui.On("Close").Once().Return(nil)
+
+	var profile string
+	patches := ApplyFunc(lorca.New, func(url, dir string, width, height int, customArgs ...string) (lorca.UI, error) {
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+		profile = dir
+		return ui, nil
+	})
+	t.Cleanup(func() {
+		patches.Reset()
+	})
+
+	_, err := NewUI(cfg, newTestLogger())
+
+	require.Error(t, err)
+	assert.EqualError(t, err, "could not load page: page did not respond")
+	assert.NoDirExists(t, profile)
+	ui.AssertExpectations(t)
+}
+
+func TestUI_ShowError(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Load", mock.MatchedBy(func(url string) bool {
+		return strings.HasPrefix(url, "data:text/html")
+	})).Once().Return(nil)
+	ui := &UI{win: win}
+
+	err := ui.ShowError("Too many restarts.", ErrRestartLimit)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUI_SetAnimationsEnabled(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", "setAnimations(false);").Once().Return(emptyVal)
+	win.On("Eval", "setAnimations(true);").Once().Return(emptyVal)
+	ui := &UI{win: win}
+
+	err := ui.SetAnimationsEnabled(false)
+	require.NoError(t, err)
+
+	err = ui.SetAnimationsEnabled(true)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUI_Move(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Bounds").Return(lorca.Bounds{Left: 10, Top: 20, Width: 640, Height: 480, WindowState: lorca.WindowStateNormal}, nil)
+	win.On("SetBounds", lorca.Bounds{Left: 1920, Top: 0, Width: 640, Height: 480, WindowState: lorca.WindowStateNormal}).Once().Return(nil)
+	ui := &UI{win: win}
+
+	err := ui.Move(1920, -5)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUI_Resize(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Bounds").Return(lorca.Bounds{Left: 10, Top: 20, Width: 640, Height: 480, WindowState: lorca.WindowStateNormal}, nil)
+	win.On("SetBounds", lorca.Bounds{Left: 10, Top: 20, Width: 0, Height: 1080, WindowState: lorca.WindowStateNormal}).Once().Return(nil)
+	ui := &UI{win: win}
+
+	err := ui.Resize(-1, 1080)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUI_MoveLeavesFullscreen(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Bounds").Return(lorca.Bounds{Left: 0, Top: 0, Width: 1920, Height: 1080, WindowState: lorca.WindowStateFullscreen}, nil)
+	win.On("SetBounds", lorca.Bounds{Left: 1920, Top: 0, Width: 1920, Height: 1080, WindowState: lorca.WindowStateNormal}).Once().Return(nil)
+	ui := &UI{win: win}
+
+	err := ui.Move(1920, 0)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUI_ResizeHandlesSetBoundsError(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Bounds").Return(lorca.Bounds{Width: 640, Height: 480}, nil)
+	win.On("SetBounds", mock.Anything).Return(errors.New("test error"))
+	ui := &UI{win: win}
+
+	err := ui.Resize(1920, 1080)
+
+	assert.EqualError(t, err, "could not set window bounds: test error")
+}
+
+func TestUI_Done(t *testing.T) {
+	ch := make(chan struct{})
+	t.Cleanup(func() {
+		close(ch)
+	})
+	win := &MockLorcaUI{}
+	win.On("Done").Return(ch)
+	ui := &UI{win: win}
+
+	got := ui.Done()
+
+	if ch != got {
+		assert.Fail(t, "incorrect channel")
+		return
+	}
+	win.AssertExpectations(t)
+}
+
+func TestUI_Close(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Close").Return(nil)
+	ui := &UI{win: win}
+
+	err := ui.Close()
+
+	assert.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_ReturnsErrClosedAfterClose(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Close").Return(nil).Once()
+	ui := &UI{win: win}
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	err = ui.Close()
+	require.NoError(t, err)
+	err = ui.Close()
+	require.NoError(t, err)
+
+	_, err = uiCtx.Eval("1+1")
+	assert.ErrorIs(t, err, ErrClosed)
+	err = uiCtx.LoadHTML("test html")
+	assert.ErrorIs(t, err, ErrClosed)
+	err = uiCtx.LoadCSS("test css")
+	assert.ErrorIs(t, err, ErrClosed)
+	err = uiCtx.Bind("test", func() {})
+	assert.ErrorIs(t, err, ErrClosed)
+	win.AssertExpectations(t)
+}
+
+func TestNewUIContext(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal, nil)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+
+	got, err := NewUIContext(ui, "test", pos)
+
+	require.NoError(t, err)
+	assert.IsType(t, &UIContext{}, got)
+	win.AssertExpectations(t)
+}
+
+func TestNewUIContext_HandlesModuleError(t *testing.T) {
+	errVal := NewValue("", errors.New("test err"))
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(errVal)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+
+	_, err := NewUIContext(ui, "test", pos)
+
+	require.Error(t, err)
+	assert.EqualError(t, err, "test: could not create module ui element: test err")
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_LoadCSS(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "loadCSS(`test`, `test css`);").Return(emptyVal)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.LoadCSS("test css")
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_LoadHTML(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "loadModuleHTML(`test`, `test html`);").Return(emptyVal)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.LoadHTML("test html")
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_LoadHTMLStagesLargeHTML(t *testing.T) {
+	row := "<tr><td>stop</td><td>12:00</td></tr>"
+	html := "<table>" + strings.Repeat(row, 2*htmlChunkSize/len(row)) + "</table>"
+
+	emptyVal := NewValue("", nil)
+	var staged strings.Builder
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, "stageModuleHTML(`test`, `")
+	})).Run(func(args mock.Arguments) {
+		js := args.String(0)
+		assert.LessOrEqual(t, len(js), htmlChunkSize+100)
+		staged.WriteString(strings.TrimSuffix(strings.TrimPrefix(js, "stageModuleHTML(`test`, `"), "`);"))
+	}).Return(emptyVal)
+	win.On("Eval", "commitModuleHTML(`test`);").Once().Return(emptyVal)
+
+	ui := &UI{win: win}
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	err = uiCtx.LoadHTML(html)
+
+	require.NoError(t, err)
+	assert.Equal(t, html, staged.String())
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_LoadHTMLDiscardsStagedHTMLOnError(t *testing.T) {
+	html := strings.Repeat("<p>row</p>", 2*htmlChunkSize/10)
+
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, "stageModuleHTML(`test`, `")
+	})).Once().Return(emptyVal)
+	win.On("Eval", mock.MatchedBy(func(js string) bool {
+		return strings.HasPrefix(js, "stageModuleHTML(`test`, `")
+	})).Once().Return(NewValue("", errors.New("test error")))
+	win.On("Eval", "discardModuleHTML(`test`);").Once().Return(emptyVal)
+
+	ui := &UI{win: win}
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	err = uiCtx.LoadHTML(html)
+
+	assert.EqualError(t, err, "test error")
+	win.AssertExpectations(t)
+	win.AssertNotCalled(t, "Eval", "commitModuleHTML(`test`);")
+}
+
+func TestSplitHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		size int
+		want []string
+	}{
+		{
+			name: "splits before tags",
+			html: "<p>one</p><p>two</p>",
+			size: 12,
+			want: []string{"<p>one</p>", "<p>two</p>"},
+		},
+		{
+			name: "does not split before escaped tags",
+			html: `<p>a\<b</p>`,
+			size: 6,
+			want: []string{`<p>a\<`, "b</p>"},
+		},
+		{
+			name: "does not split runes or escapes",
+			html: `ab\ncdéf`,
+			size: 3,
+			want: []string{"ab", `\nc`, "dé", "f"},
+		},
+		{
+			name: "keeps small html whole",
+			html: "<p>one</p>",
+			size: 20,
+			want: []string{"<p>one</p>"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitHTML(test.html, test.size)
+
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.html, strings.Join(got, ""))
+		})
+	}
+}
+
+func TestUIContext_LoadHTMLAppliesTransformers(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "loadModuleHTML(`test`, `<div><img loading=\"lazy\" src=\"a.png\"></div>`);").Return(emptyVal)
+
+	ui := &UI{win: win}
+	ui.UseHTMLTransformer(
+		func(module, html string) (string, error) {
+			return strings.ReplaceAll(html, "<img ", `<img loading="lazy" `), nil
+		},
+		func(module, html string) (string, error) {
+			assert.Equal(t, "test", module)
+			return "<div>" + html + "</div>", nil
+		},
+	)
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.LoadHTML(`<img src="a.png">`)
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_LoadHTMLHandlesTransformerError(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+
+	ui := &UI{win: win}
+	ui.UseHTMLTransformer(func(module, html string) (string, error) {
+		return "", errors.New("test error")
+	})
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.LoadHTML("test html")
+
+	require.Error(t, err)
+	assert.EqualError(t, err, "test: could not transform html: test error")
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_AppendHTML(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "appendModuleHTML(`test`, `test html`, 0);").Return(emptyVal)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.AppendHTML("test html")
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_AppendHTMLWithMaxNodes(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "appendModuleHTML(`test`, `test html`, 10);").Return(emptyVal)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+	uiCtx.SetMaxNodes(10)
+
+	err = uiCtx.AppendHTML("test html")
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_Bind(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Bind", "testfunc", mock.AnythingOfType("func(string, string) string")).Return(nil)
+	win.On("Bind", "test.testfunc", mock.AnythingOfType("func(string, string) string")).Return(nil)
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	err = uiCtx.Bind("testfunc", func(a, b string) string { return "test" })
+
+	require.NoError(t, err)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_BindChecksSignature(t *testing.T) {
+	type reading struct {
+		Room  string            `json:"room"`
+		Temps []float64         `json:"temps"`
+		Tags  map[string]string `json:"tags"`
+		cache chan int
+		Done  func() `json:"-"`
+	}
+
+	tests := []struct {
+		name    string
+		fun     interface{}
+		wantErr string
+	}{
+		{
+			name: "no arguments or return values",
+			fun:  func() {},
+		},
+		{
+			name: "struct argument and return value",
+			fun:  func(r reading, ids []int) (reading, error) { return r, nil },
+		},
+		{
+			name: "pointer and interface arguments",
+			fun:  func(r *reading, v interface{}) map[string]reading { return nil },
+		},
+		{
+			name: "json marshaler return value",
+			fun:  func() (json.RawMessage, error) { return nil, nil },
+		},
+		{
+			name: "error return value",
+			fun:  func(string) error { return nil },
+		},
+		{
+			name:    "handles non function",
+			fun:     "test",
+			wantErr: `test: could not bind "testfunc": only functions can be bound`,
+		},
+		{
+			name:    "handles variadic function",
+			fun:     func(a ...string) {},
+			wantErr: `test: could not bind "testfunc": variadic functions cannot be bound`,
+		},
+		{
+			name:    "handles channel argument",
+			fun:     func(s string, c chan int) {},
+			wantErr: `test: could not bind "testfunc": argument 2: chan int cannot be converted to json`,
+		},
+		{
+			name:    "handles function field in return value",
+			fun:     func() struct{ Fn func() } { return struct{ Fn func() }{} },
+			wantErr: `test: could not bind "testfunc": return value: field Fn: func() cannot be converted to json`,
+		},
+		{
+			name:    "handles second return value not error",
+			fun:     func() (string, string) { return "", "" },
+			wantErr: `test: could not bind "testfunc": second return value must be an error`,
+		},
+		{
+			name:    "handles too many return values",
+			fun:     func() (string, int, error) { return "", 0, nil },
+			wantErr: `test: could not bind "testfunc": function may only return a value, an error or a value and an error`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uiCtx, win := NewTestUIContext()
+
+			err := uiCtx.Bind("testfunc", test.fun)
+
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				_, ok := win.Binding("testfunc")
+				assert.False(t, ok)
+				return
+			}
+			require.NoError(t, err)
+			_, ok := win.Binding("testfunc")
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestUIContext_BindNamespacesInstances(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	london, err := NewUIContext(ui, "clock_london", module.Position{Vertical: module.Top, Horizontal: module.Left})
+	require.NoError(t, err)
+	tokyo, err := NewUIContext(ui, "clock_tokyo", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	err = london.Bind("zone", func() string { return "Europe/London" })
+	require.NoError(t, err)
+	err = tokyo.Bind("zone", func() string { return "Asia/Tokyo" })
+	require.NoError(t, err)
+
+	fn, ok := win.Binding("clock_london.zone")
+	require.True(t, ok)
+	assert.Equal(t, "Europe/London", fn.(func() string)())
+	fn, ok = win.Binding("clock_tokyo.zone")
+	require.True(t, ok)
+	assert.Equal(t, "Asia/Tokyo", fn.(func() string)())
+	fn, ok = win.Binding("zone")
+	require.True(t, ok)
+	assert.Error(t, fn.(func() error)())
+	assert.Contains(t, win.Evals(), `unbindFunction("zone");`)
+}
 
-	_, err := NewUI(cfg)
+func TestUIContext_UnbindRebindsGlobalToRemainingInstance(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	london, err := NewUIContext(ui, "clock_london", module.Position{Vertical: module.Top, Horizontal: module.Left})
+	require.NoError(t, err)
+	tokyo, err := NewUIContext(ui, "clock_tokyo", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+	err = london.Bind("zone", func() string { return "Europe/London" })
+	require.NoError(t, err)
+	err = tokyo.Bind("zone", func() string { return "Asia/Tokyo" })
+	require.NoError(t, err)
 
-	assert.Error(t, err)
-	assert.EqualError(t, err, "could not create window: test error")
+	err = tokyo.Unbind("zone")
+
+	require.NoError(t, err)
+	fn, ok := win.Binding("zone")
+	require.True(t, ok)
+	assert.Equal(t, "Europe/London", fn.(func() string)())
+	assert.Contains(t, win.Evals(), `rebindFunction("zone");`)
 }
 
-func TestUI_Done(t *testing.T) {
-	ch := make(chan struct{})
-	t.Cleanup(func() {
-		close(ch)
-	})
+func TestUI_Bindings(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	err := uiCtx.Bind("toggle", func() {})
+	require.NoError(t, err)
+	err = uiCtx.Bind("setTemp", func(room string, temp float64) error { return nil })
+	require.NoError(t, err)
+
+	got := uiCtx.ui.Bindings()
+
+	want := map[string][]BindingInfo{
+		"test": {
+			{Name: "toggle", Args: 0},
+			{Name: "setTemp", Args: 2},
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestUIContext_Unbind(t *testing.T) {
+	emptyVal := NewValue("", nil)
 	win := &MockLorcaUI{}
-	win.On("Done").Return(ch)
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Bind", "testfunc", mock.AnythingOfType("func(string, string) string")).Once().Return(nil)
+	win.On("Bind", "test.testfunc", mock.AnythingOfType("func(string, string) string")).Once().Return(nil)
+	win.On("Bind", "testfunc", mock.AnythingOfType("func() error")).Once().Return(nil)
+	win.On("Bind", "test.testfunc", mock.AnythingOfType("func() error")).Once().Return(nil)
+	win.On("Eval", `unbindFunction("testfunc");`).Once().Return(emptyVal)
+	win.On("Eval", `unbindFunction("test.testfunc");`).Once().Return(emptyVal)
+
 	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+	err = uiCtx.Bind("testfunc", func(a, b string) string { return "test" })
+	require.NoError(t, err)
 
-	got := ui.Done()
+	err = uiCtx.Unbind("testfunc")
 
-	if ch != got {
-		assert.Fail(t, "incorrect channel")
-		return
-	}
+	require.NoError(t, err)
+	assert.Empty(t, ui.Bindings()["test"])
 	win.AssertExpectations(t)
 }
 
-func TestUI_Close(t *testing.T) {
+func TestUIContext_UnbindIgnoresUnknownName(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.Unbind("testfunc")
+
+	require.NoError(t, err)
+	for _, c := range win.Calls() {
+		assert.NotEqual(t, "Bind", c.Method)
+	}
+	assert.NotContains(t, win.Evals(), `unbindFunction("testfunc");`)
+}
+
+func TestUIContext_BindAfterUnbind(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	err := uiCtx.Bind("testfunc", func() {})
+	require.NoError(t, err)
+	err = uiCtx.Unbind("testfunc")
+	require.NoError(t, err)
+
+	err = uiCtx.Bind("testfunc", func() {})
+
+	require.NoError(t, err)
+	evals := win.Evals()
+	assert.Equal(t, []string{
+		`unbindFunction("testfunc");`,
+		`unbindFunction("test.testfunc");`,
+		`rebindFunction("test.testfunc");`,
+		`rebindFunction("testfunc");`,
+	}, evals[len(evals)-4:])
+	assert.Equal(t, []BindingInfo{{Name: "testfunc"}}, uiCtx.ui.Bindings()["test"])
+}
+
+func TestUIContext_BindHandlesReservedName(t *testing.T) {
+	emptyVal := NewValue("", nil)
 	win := &MockLorcaUI{}
-	win.On("Close").Return(nil)
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+
 	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
 
-	err := ui.Close()
+	err = uiCtx.Bind("loadCSS", func(a, b string) string { return "test" })
 
-	assert.NoError(t, err)
+	require.Error(t, err)
+	assert.EqualError(t, err, `test: could not bind "loadCSS": name is reserved`)
+	win.AssertNotCalled(t, "Bind", mock.Anything, mock.Anything)
 	win.AssertExpectations(t)
 }
 
-func TestNewUIContext(t *testing.T) {
+func TestUIContext_Eval(t *testing.T) {
 	emptyVal := NewValue("", nil)
+	mapVal := NewValue(`{"test": "return"}`, nil)
 	win := &MockLorcaUI{}
-	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal, nil)
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "some js test").Return(mapVal)
 
 	ui := &UI{win: win}
 	pos := module.Position{
 		Vertical:   module.Top,
 		Horizontal: module.Right,
 	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
 
-	got, err := NewUIContext(ui, "test", pos)
+	got, err := uiCtx.Eval("some js %s", "test")
 
 	require.NoError(t, err)
-	assert.IsType(t, &UIContext{}, got)
+	assert.Equal(t, map[string]interface{}{"test": "return"}, got)
 	win.AssertExpectations(t)
 }
 
-func TestNewUIContext_HandlesModuleError(t *testing.T) {
-	errVal := NewValue("", errors.New("test err"))
+func TestUIContext_EvalHandlesEmptyValue(t *testing.T) {
+	emptyVal := NewValue("", nil)
 	win := &MockLorcaUI{}
-	win.On("Eval", `createModule("test", "top", "right");`).Return(errVal)
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "some js test").Return(emptyVal)
 
 	ui := &UI{win: win}
 	pos := module.Position{
 		Vertical:   module.Top,
 		Horizontal: module.Right,
 	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
+
+	got, err := uiCtx.Eval("some js %s", "test")
+
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	win.AssertExpectations(t)
+}
+
+func TestUIContext_EvalTimesOut(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", mock.Anything).WaitUntil(time.After(time.Second)).Return(emptyVal)
+	win.On("Done").Return(make(chan struct{})).Maybe()
+
+	ui := &UI{win: win, timeout: 10 * time.Millisecond}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
 
-	_, err := NewUIContext(ui, "test", pos)
+	_, err = uiCtx.Eval("document.querySelector('#test').innerHTML = `" + strings.Repeat("a", 100) + "`;")
 
 	require.Error(t, err)
-	assert.EqualError(t, err, "test: could not create module ui element: test err")
-	win.AssertExpectations(t)
+	assert.True(t, errors.Is(err, ErrEvalTimeout))
+	assert.Equal(t, "test: eval timed out: document.querySelector('#test').innerHTML = `aaaaaaaaaaaaaaaaaaa...", err.Error())
 }
 
-func TestUIContext_LoadCSS(t *testing.T) {
+func TestUIContext_EvalAsync(t *testing.T) {
 	emptyVal := NewValue("", nil)
+	mapVal := NewValue(`{"test": "return"}`, nil)
+	release := make(chan time.Time)
 	win := &MockLorcaUI{}
 	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
-	win.On("Eval", "loadCSS(`test`, `test css`);").Return(emptyVal)
+	win.On("Eval", "some async js").WaitUntil(release).Return(emptyVal).Once()
+	win.On("Done").Return(make(chan struct{})).Maybe()
+	win.On("Eval", "some js test").Return(mapVal)
 
 	ui := &UI{win: win}
 	pos := module.Position{
@@ -146,17 +1257,21 @@ func TestUIContext_LoadCSS(t *testing.T) {
 	uiCtx, err := NewUIContext(ui, "test", pos)
 	require.NoError(t, err)
 
-	err = uiCtx.LoadCSS("test css")
+	uiCtx.EvalAsync("some %s js", "async")
+	go func() { close(release) }()
+	got, err := uiCtx.Eval("some js %s", "test")
 
 	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"test": "return"}, got)
 	win.AssertExpectations(t)
+	win.AssertCalled(t, "Eval", "some async js")
 }
 
-func TestUIContext_LoadHTML(t *testing.T) {
+func TestUIContext_EvalAsyncLogsErrors(t *testing.T) {
 	emptyVal := NewValue("", nil)
 	win := &MockLorcaUI{}
 	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
-	win.On("Eval", "loadModuleHTML(`test`, `test html`);").Return(emptyVal)
+	win.On("Eval", "some async js").Return(NewValue("", errors.New("test error")))
 
 	ui := &UI{win: win}
 	pos := module.Position{
@@ -166,17 +1281,19 @@ func TestUIContext_LoadHTML(t *testing.T) {
 	uiCtx, err := NewUIContext(ui, "test", pos)
 	require.NoError(t, err)
 
-	err = uiCtx.LoadHTML("test html")
+	uiCtx.EvalAsync("some async js")
+	err = uiCtx.async.wait(context.Background())
 
 	require.NoError(t, err)
 	win.AssertExpectations(t)
 }
 
-func TestUIContext_Bind(t *testing.T) {
+func TestUIContext_EvalContextReturnsWhileWaitingForAsync(t *testing.T) {
 	emptyVal := NewValue("", nil)
 	win := &MockLorcaUI{}
 	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
-	win.On("Bind", "testfunc", mock.AnythingOfType("func(string, string) string")).Return(nil)
+	win.On("Eval", "some async js").WaitUntil(time.After(time.Second)).Return(emptyVal)
+	win.On("Done").Return(make(chan struct{})).Maybe()
 
 	ui := &UI{win: win}
 	pos := module.Position{
@@ -186,18 +1303,135 @@ func TestUIContext_Bind(t *testing.T) {
 	uiCtx, err := NewUIContext(ui, "test", pos)
 	require.NoError(t, err)
 
-	err = uiCtx.Bind("testfunc", func(a, b string) string { return "test" })
+	uiCtx.EvalAsync("some async js")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = uiCtx.EvalContext(ctx, "some js")
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestUIContext_EvalContext(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
+	win.On("Eval", "slow js").WaitUntil(time.After(time.Second)).Return(emptyVal)
+	win.On("Done").Return(make(chan struct{})).Maybe()
+	win.On("Eval", "fast js").Return(NewValue(`42`, nil))
+
+	ui := &UI{win: win}
+	pos := module.Position{
+		Vertical:   module.Top,
+		Horizontal: module.Right,
+	}
+	uiCtx, err := NewUIContext(ui, "test", pos)
+	require.NoError(t, err)
 
+	got, err := uiCtx.EvalContext(context.Background(), "fast %s", "js")
 	require.NoError(t, err)
-	win.AssertExpectations(t)
+	assert.Equal(t, float64(42), got)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = uiCtx.EvalContext(ctx, "slow js")
+
+	assert.EqualError(t, err, "test: eval timed out: slow js")
 }
 
-func TestUIContext_Eval(t *testing.T) {
+func TestUI_EvalPassesPanicsToCaller(t *testing.T) {
+	win := &MockLorcaUI{}
+	ui := &UI{win: win}
+
+	assert.Panics(t, func() {
+		_, _ = ui.Eval("unexpected js")
+	})
+}
+
+// overlapWindow records if the window is used concurrently.
+type overlapWindow struct {
+	*RecordingWindow
+
+	active  int32
+	overlap int32
+}
+
+func (w *overlapWindow) Eval(js string) lorca.Value {
+	if atomic.AddInt32(&w.active, 1) > 1 {
+		atomic.StoreInt32(&w.overlap, 1)
+	}
+	defer atomic.AddInt32(&w.active, -1)
+
+	time.Sleep(100 * time.Microsecond)
+	return w.RecordingWindow.Eval(js)
+}
+
+func TestUI_EvalIsSerialised(t *testing.T) {
+	win := &overlapWindow{RecordingWindow: NewRecordingWindow()}
+	ui := &UI{win: win}
+
+	const (
+		workers = 8
+		evals   = 20
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < evals; j++ {
+				_, err := ui.Eval(fmt.Sprintf("update(%d, %d);", i, j))
+				assert.NoError(t, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Zero(t, atomic.LoadInt32(&win.overlap))
+	got := make([][]int, workers)
+	for _, js := range win.Evals() {
+		var i, j int
+		_, err := fmt.Sscanf(js, "update(%d, %d);", &i, &j)
+		require.NoError(t, err)
+		got[i] = append(got[i], j)
+	}
+	for i := range got {
+		require.Len(t, got[i], evals)
+		for j := range got[i] {
+			assert.Equal(t, j, got[i][j])
+		}
+	}
+}
+
+func TestUI_EvalHoldsQueueUntilAbandonedEvalReturns(t *testing.T) {
+	emptyVal := NewValue("", nil)
+	hung := make(chan time.Time)
+	win := &MockLorcaUI{}
+	win.On("Eval", "hung js").WaitUntil(hung).Return(emptyVal)
+	win.On("Done").Return(make(chan struct{})).Maybe()
+	win.On("Eval", "fast js").Return(NewValue(`42`, nil))
+	ui := &UI{win: win, timeout: 10 * time.Millisecond}
+
+	_, err := ui.Eval("hung js")
+	require.ErrorIs(t, err, ErrEvalTimeout)
+	_, err = ui.Eval("fast js")
+	require.ErrorIs(t, err, ErrEvalTimeout)
+	win.AssertNotCalled(t, "Eval", "fast js")
+
+	close(hung)
+	ui.timeout = time.Second
+	got, err := ui.Eval("fast js")
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(42), got)
+}
+
+func TestUIContext_EvalInto(t *testing.T) {
 	emptyVal := NewValue("", nil)
-	mapVal := NewValue(`{"test": "return"}`, nil)
 	win := &MockLorcaUI{}
 	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
-	win.On("Eval", "some js test").Return(mapVal)
+	win.On("Eval", "some js test").Return(NewValue(`{"name": "clock", "size": {"width": 100}}`, nil))
 
 	ui := &UI{win: win}
 	pos := module.Position{
@@ -207,14 +1441,21 @@ func TestUIContext_Eval(t *testing.T) {
 	uiCtx, err := NewUIContext(ui, "test", pos)
 	require.NoError(t, err)
 
-	got, err := uiCtx.Eval("some js %s", "test")
+	var got struct {
+		Name string `json:"name"`
+		Size struct {
+			Width int `json:"width"`
+		} `json:"size"`
+	}
+	err = uiCtx.EvalInto(&got, "some js %s", "test")
 
 	require.NoError(t, err)
-	assert.Equal(t, map[string]interface{}{"test": "return"}, got)
+	assert.Equal(t, "clock", got.Name)
+	assert.Equal(t, 100, got.Size.Width)
 	win.AssertExpectations(t)
 }
 
-func TestUIContext_EvalHandlesEmptyValue(t *testing.T) {
+func TestUIContext_EvalIntoHandlesEmptyValue(t *testing.T) {
 	emptyVal := NewValue("", nil)
 	win := &MockLorcaUI{}
 	win.On("Eval", `createModule("test", "top", "right");`).Return(emptyVal)
@@ -228,13 +1469,63 @@ func TestUIContext_EvalHandlesEmptyValue(t *testing.T) {
 	uiCtx, err := NewUIContext(ui, "test", pos)
 	require.NoError(t, err)
 
-	got, err := uiCtx.Eval("some js %s", "test")
+	got := "untouched"
+	err = uiCtx.EvalInto(&got, "some js %s", "test")
 
 	require.NoError(t, err)
-	assert.Nil(t, got)
+	assert.Equal(t, "untouched", got)
 	win.AssertExpectations(t)
 }
 
+func TestUIContext_EvalTyped(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	require.NoError(t, win.OnEval("document.hidden;", true, nil))
+	require.NoError(t, win.OnEval("document.title;", "Mirror", nil))
+	require.NoError(t, win.OnEval("document.querySelectorAll('#test li').length;", 3, nil))
+	require.NoError(t, win.OnEval("window.devicePixelRatio;", 1.5, nil))
+
+	hidden, err := uiCtx.EvalBool("document.hidden;")
+	require.NoError(t, err)
+	title, err := uiCtx.EvalString("document.title;")
+	require.NoError(t, err)
+	items, err := uiCtx.EvalInt("document.querySelectorAll('#%s li').length;", "test")
+	require.NoError(t, err)
+	ratio, err := uiCtx.EvalFloat("window.devicePixelRatio;")
+	require.NoError(t, err)
+
+	assert.True(t, hidden)
+	assert.Equal(t, "Mirror", title)
+	assert.Equal(t, 3, items)
+	assert.Equal(t, 1.5, ratio)
+}
+
+func TestUIContext_EvalTypedHandlesEmptyValue(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	b, err := uiCtx.EvalBool("void 0;")
+	require.NoError(t, err)
+	s, err := uiCtx.EvalString("void 0;")
+	require.NoError(t, err)
+	i, err := uiCtx.EvalInt("void 0;")
+	require.NoError(t, err)
+	f, err := uiCtx.EvalFloat("void 0;")
+	require.NoError(t, err)
+
+	assert.False(t, b)
+	assert.Empty(t, s)
+	assert.Zero(t, i)
+	assert.Zero(t, f)
+}
+
+func TestUIContext_EvalTypedHandlesWrongType(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	require.NoError(t, win.OnEval("document.title;", "Mirror", nil))
+
+	_, err := uiCtx.EvalInt("document.title;")
+
+	assert.Error(t, err)
+}
+
 func TestUIContext_EvalHandlesError(t *testing.T) {
 	emptyVal := NewValue("", nil)
 	errorVal := NewValue("", errors.New("test"))
@@ -337,3 +1628,118 @@ func (v Value) Object() (object map[string]lorca.Value) {
 	}
 	return object
 }
+
+func TestUIContext_SetVisible(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+	err := uiCtx.LoadHTML("<div>clock</div>")
+	require.NoError(t, err)
+	assert.True(t, uiCtx.Visible())
+
+	err = uiCtx.SetVisible(false)
+	require.NoError(t, err)
+	assert.False(t, uiCtx.Visible())
+	err = uiCtx.SetVisible(true)
+	require.NoError(t, err)
+
+	assert.True(t, uiCtx.Visible())
+	evals := win.Evals()
+	assert.Equal(t, []string{`setModuleVisible("test", false);`, `setModuleVisible("test", true);`}, evals[len(evals)-2:])
+	assert.Equal(t, "<div>clock</div>", win.HTML("test"))
+}
+
+func TestUIContext_SetVisibleHandlesEvalError(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `setModuleVisible("test", false);`).Return(NewValue("", errors.New("test error")))
+	uiCtx := &UIContext{ui: &UI{win: win}, name: "test"}
+
+	err := uiCtx.SetVisible(false)
+
+	assert.Error(t, err)
+	assert.True(t, uiCtx.Visible())
+}
+
+func TestUIContext_SetData(t *testing.T) {
+	uiCtx, win := NewTestUIContext()
+
+	err := uiCtx.SetData(map[string]interface{}{"temp": 21.5, "unit": "C"})
+
+	require.NoError(t, err)
+	evals := win.Evals()
+	assert.Equal(t, `setModuleData("test", {"temp":21.5,"unit":"C"});`, evals[len(evals)-1])
+}
+
+func TestUIContext_SetDataHandlesEncodeError(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	err := uiCtx.SetData(make(chan int))
+
+	assert.EqualError(t, err, "test: could not encode data: json: unsupported type: chan int")
+}
+
+func TestNewUIContexts_StacksModules(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	descs := []module.Descriptor{
+		{Name: "photo", Position: module.Position{Vertical: module.Middle, Horizontal: module.Center}, ZIndex: -1},
+		{Name: "clock", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}},
+		{Name: "news", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}, ZIndex: 5},
+	}
+
+	_, err := NewUIContexts(ui, descs)
+
+	require.NoError(t, err)
+	assert.Equal(t, `createModules([{"name":"photo","vert":"middle","horiz":"center","zIndex":-1},{"name":"clock","vert":"top","horiz":"left"},{"name":"news","vert":"top","horiz":"right","zIndex":5}]);`, win.Evals()[0])
+	assert.Equal(t, `createModule("photo", "middle", "center", null, -1);`, ui.mods[0].create)
+	assert.Equal(t, `createModule("clock", "top", "left");`, ui.mods[1].create)
+}
+
+func TestNewUIContexts_SandboxesModules(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	descs := []module.Descriptor{
+		{Name: "news", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}, Sandbox: module.Sandbox{Enabled: true, Allow: []string{"allow-scripts"}}},
+		{Name: "photo", Position: module.Position{Vertical: module.Top, Horizontal: module.Left}, Sandbox: module.Sandbox{Enabled: true}},
+		{Name: "clock", Position: module.Position{Vertical: module.Bottom, Horizontal: module.Left}},
+	}
+
+	uiCtxs, err := NewUIContexts(ui, descs)
+
+	require.NoError(t, err)
+	assert.Equal(t, `createModules([{"name":"news","vert":"top","horiz":"right","sandbox":["allow-scripts"]},{"name":"photo","vert":"top","horiz":"left","sandbox":[]},{"name":"clock","vert":"bottom","horiz":"left"}]);`, win.Evals()[0])
+	assert.Equal(t, []string{`sandboxModule("news", ["allow-scripts"]);`}, ui.mods[0].sandbox)
+	assert.Equal(t, []string{`sandboxModule("photo", []);`}, ui.mods[1].sandbox)
+	assert.Empty(t, ui.mods[2].sandbox)
+
+	err = uiCtxs[0].Bind("refresh", func() {})
+	require.NoError(t, err)
+	err = uiCtxs[2].Bind("tick", func() {})
+	require.NoError(t, err)
+
+	assert.Contains(t, win.Evals(), `allowSandboxFunction("news", "refresh");`)
+	assert.NotContains(t, win.Evals(), `allowSandboxFunction("clock", "tick");`)
+	assert.Equal(t, []string{`sandboxModule("news", ["allow-scripts"]);`, `allowSandboxFunction("news", "refresh");`}, ui.mods[0].sandbox)
+}
+
+func TestUIContext_EvalSandboxedModule(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	descs := []module.Descriptor{
+		{Name: "news", Position: module.Position{Vertical: module.Top, Horizontal: module.Right}, Sandbox: module.Sandbox{Enabled: true}},
+	}
+	uiCtxs, err := NewUIContexts(ui, descs)
+	require.NoError(t, err)
+	evals := len(win.Evals())
+
+	_, err = uiCtxs[0].Eval(`document.title;`)
+	assert.ErrorIs(t, err, ErrSandboxed)
+	var s string
+	err = uiCtxs[0].EvalInto(&s, `document.title;`)
+	assert.ErrorIs(t, err, ErrSandboxed)
+	err = uiCtxs[0].EvalIntoStrict(&s, `document.title;`)
+	assert.ErrorIs(t, err, ErrSandboxed)
+	_, err = uiCtxs[0].EvalContext(context.Background(), `document.title;`)
+	assert.ErrorIs(t, err, ErrSandboxed)
+	uiCtxs[0].EvalAsync(`document.title;`)
+
+	assert.Len(t, win.Evals(), evals)
+}
@@ -0,0 +1,287 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/zserge/lorca"
+)
+
+// Call is a call recorded by a RecordingWindow.
+type Call struct {
+	// Method is the window method that was called.
+	Method string
+	// Arg is the javascript, binding name or url of the call.
+	Arg string
+}
+
+// RecordingWindow is a fake window that records all calls made to it.
+//
+// It is used in tests in place of a chrome window.
+type RecordingWindow struct {
+	mu       sync.Mutex
+	calls    []Call
+	bindings map[string]interface{}
+	results  map[string]scriptedValue
+	bounds   lorca.Bounds
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewRecordingWindow returns a recording window.
+func NewRecordingWindow() *RecordingWindow {
+	return &RecordingWindow{
+		bindings: map[string]interface{}{},
+		results:  map[string]scriptedValue{},
+		done:     make(chan struct{}),
+	}
+}
+
+// TestModuleName is the name of the module of a test ui context.
+const TestModuleName = "test"
+
+// NewTestUIContext returns a module ui context backed by a recording window.
+//
+// This is the supported way to test modules without chrome. All calls
+// made through the ui context can be inspected on the returned window.
+// Messages published by the module are delivered to its own subscriptions.
+func NewTestUIContext() (*UIContext, *RecordingWindow) {
+	win := NewRecordingWindow()
+	uiCtx, _ := NewUIContext(&UI{win: win}, TestModuleName, module.Position{Vertical: module.Top, Horizontal: module.Left})
+	uiCtx.bus = NewEventBus()
+	uiCtx.reg = NewModuleRegistry()
+	uiCtx.reg.add(uiCtx.name)
+	return uiCtx, win
+}
+
+// Calls returns all recorded calls.
+func (w *RecordingWindow) Calls() []Call {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]Call(nil), w.calls...)
+}
+
+// Evals returns all evaluated javascript.
+func (w *RecordingWindow) Evals() []string {
+	var evals []string
+	for _, c := range w.Calls() {
+		if c.Method == "Eval" {
+			evals = append(evals, c.Arg)
+		}
+	}
+	return evals
+}
+
+// HTML returns the html last loaded into the given module.
+func (w *RecordingWindow) HTML(name string) string {
+	var html string
+	prefixes := []string{"loadModuleHTML(`" + name + "`, `", "loadTicker(`" + name + "`, `"}
+	for _, js := range w.statements() {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(js, prefix) {
+				html = strings.TrimSuffix(strings.TrimPrefix(js, prefix), "`);")
+			}
+		}
+	}
+	return html
+}
+
+// CSS returns all css loaded for the given module.
+func (w *RecordingWindow) CSS(name string) []string {
+	var css []string
+	prefix := "loadCSS(`" + name + "`, `"
+	for _, js := range w.statements() {
+		if strings.HasPrefix(js, prefix) {
+			css = append(css, strings.TrimSuffix(strings.TrimPrefix(js, prefix), "`);"))
+		}
+	}
+	return css
+}
+
+// statements returns all evaluated javascript, with batches
+// expanded into their statements.
+func (w *RecordingWindow) statements() []string {
+	var stmts []string
+	for _, js := range w.Evals() {
+		if strings.HasPrefix(js, batchPrefix) && strings.HasSuffix(js, batchSuffix) {
+			js = strings.TrimSuffix(strings.TrimPrefix(js, batchPrefix), batchSuffix)
+			stmts = append(stmts, strings.Split(js, batchSep)...)
+			continue
+		}
+		stmts = append(stmts, js)
+	}
+	return stmts
+}
+
+// Binding returns the function bound to the given name.
+func (w *RecordingWindow) Binding(name string) (interface{}, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fn, ok := w.bindings[name]
+	return fn, ok
+}
+
+// OnEval scripts the result of evaluating the javascript js. The result is
+// encoded as json and returned, along with err, each time js is evaluated.
+//
+// Javascript without a scripted result evaluates to an empty value.
+func (w *RecordingWindow) OnEval(js string, result interface{}, err error) error {
+	b, merr := json.Marshal(result)
+	if merr != nil {
+		return fmt.Errorf("could not encode result: %w", merr)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.results[js] = scriptedValue{recordedValue: b, err: err}
+	return nil
+}
+
+// Load records the loaded url.
+func (w *RecordingWindow) Load(url string) error {
+	w.record("Load", url)
+	return nil
+}
+
+// Bounds returns the window bounds.
+func (w *RecordingWindow) Bounds() (lorca.Bounds, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.bounds, nil
+}
+
+// SetBounds sets the window bounds.
+func (w *RecordingWindow) SetBounds(b lorca.Bounds) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.bounds = b
+	return nil
+}
+
+// Bind records the bound function.
+func (w *RecordingWindow) Bind(name string, fn interface{}) error {
+	w.record("Bind", name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.bindings[name] = fn
+	return nil
+}
+
+// Eval records the javascript, returning its scripted result or an empty value.
+func (w *RecordingWindow) Eval(js string) lorca.Value {
+	w.record("Eval", js)
+
+	w.mu.Lock()
+	res, ok := w.results[js]
+	w.mu.Unlock()
+	if ok {
+		return res
+	}
+
+	switch {
+	case js == "ping();":
+		return recordedValue(`"pong"`)
+	case js == "currentOrientation();":
+		return recordedValue(`"landscape"`)
+	case js == "currentColorScheme();":
+		return recordedValue(`"light"`)
+	case js == "viewportSize();":
+		return recordedValue(`{"width":1024,"height":768}`)
+	case strings.HasPrefix(js, "createModules("):
+		return createdModules(js)
+	}
+	return recordedValue("")
+}
+
+// createdModules returns a successful status for each module
+// in a createModules call.
+func createdModules(js string) lorca.Value {
+	var mods []struct {
+		Name string `json:"name"`
+	}
+	raw := strings.TrimSuffix(strings.TrimPrefix(js, "createModules("), ");")
+	_ = json.Unmarshal([]byte(raw), &mods)
+
+	status := make(map[string]string, len(mods))
+	for _, mod := range mods {
+		status[mod.Name] = ""
+	}
+	b, _ := json.Marshal(status)
+	return recordedValue(b)
+}
+
+// Done returns a channel that is closed when the window is closed.
+func (w *RecordingWindow) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close closes the window.
+func (w *RecordingWindow) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *RecordingWindow) record(method, arg string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.calls = append(w.calls, Call{Method: method, Arg: arg})
+}
+
+// recordedValue is a raw json lorca value.
+type recordedValue json.RawMessage
+
+func (v recordedValue) Err() error { return nil }
+
+func (v recordedValue) To(x interface{}) error {
+	if len(v) == 0 {
+		return nil
+	}
+	return json.Unmarshal(v, x)
+}
+
+func (v recordedValue) Float() (f float32) { _ = v.To(&f); return f }
+func (v recordedValue) Int() (i int)       { _ = v.To(&i); return i }
+func (v recordedValue) String() (s string) { _ = v.To(&s); return s }
+func (v recordedValue) Bool() (b bool)     { _ = v.To(&b); return b }
+func (v recordedValue) Bytes() []byte      { return v }
+
+func (v recordedValue) Object() map[string]lorca.Value {
+	raw := map[string]json.RawMessage{}
+	_ = v.To(&raw)
+	obj := make(map[string]lorca.Value, len(raw))
+	for k, val := range raw {
+		obj[k] = recordedValue(val)
+	}
+	return obj
+}
+
+func (v recordedValue) Array() []lorca.Value {
+	var raw []json.RawMessage
+	_ = v.To(&raw)
+	arr := make([]lorca.Value, 0, len(raw))
+	for _, val := range raw {
+		arr = append(arr, recordedValue(val))
+	}
+	return arr
+}
+
+// scriptedValue is a scripted lorca value.
+type scriptedValue struct {
+	recordedValue
+
+	err error
+}
+
+func (v scriptedValue) Err() error { return v.err }
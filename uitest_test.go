@@ -0,0 +1,44 @@
+package glass_test
+
+import (
+	"fmt"
+	"testing"
+
+	glass "github.com/glasslabs/looking-glass"
+	"github.com/glasslabs/looking-glass/module/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greeter struct {
+	ui types.UI
+}
+
+func (g greeter) Greet(name string) error {
+	if err := g.ui.LoadCSS(".greeting { color: #fff; }"); err != nil {
+		return err
+	}
+	return g.ui.LoadHTML(fmt.Sprintf(`<p class="greeting">Hello %s</p>`, name))
+}
+
+func TestNewTestUIContext(t *testing.T) {
+	uiCtx, win := glass.NewTestUIContext()
+
+	err := greeter{ui: uiCtx}.Greet("Bob")
+	require.NoError(t, err)
+
+	assert.Equal(t, `<p class="greeting">Hello Bob</p>`, win.HTML("test"))
+	assert.Equal(t, []string{".greeting { color: #fff; }"}, win.CSS("test"))
+}
+
+func TestNewTestUIContext_RecordsBindings(t *testing.T) {
+	uiCtx, win := glass.NewTestUIContext()
+
+	err := uiCtx.Bind("greet", func() string { return "hello" })
+	require.NoError(t, err)
+
+	fn, ok := win.Binding("greet")
+	require.True(t, ok)
+	assert.Equal(t, "hello", fn.(func() string)())
+	assert.Contains(t, win.Calls(), glass.Call{Method: "Bind", Arg: "greet"})
+}
@@ -0,0 +1,102 @@
+package glass
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glasslabs/looking-glass/internal/i18n"
+	"github.com/glasslabs/looking-glass/module"
+)
+
+// ConfigErrors contains all problems found when validating a configuration.
+type ConfigErrors []error
+
+// Error returns the error message.
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ValidateConfig loads the configuration at path and checks that it can be
+// run, without creating a window. Besides validating the configuration, the
+// custom css, splash, background and locale files must exist, and modules
+// without a version, along with their local fonts, must exist under the
+// modules path.
+// Modules with a version are downloaded when run, so they are not checked.
+//
+// All problems found are returned together as ConfigErrors.
+func ValidateConfig(path string, secrets map[string]interface{}, modPath string) error {
+	cfg, err := LoadConfig(path, secrets)
+	if err != nil {
+		return err
+	}
+
+	errs := cfg.validate()
+	errs = append(errs, cfg.UI.checkFiles()...)
+	errs = append(errs, cfg.Background.checkFiles()...)
+	if cfg.Loader.StrictPositions {
+		var enabled []module.Descriptor
+		for _, desc := range cfg.Modules {
+			if desc.IsEnabled() {
+				enabled = append(enabled, desc)
+			}
+		}
+		if err = overlapError(positionOverlaps(enabled)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if modPath != "" {
+		svc, err := module.NewService(modPath, nil)
+		if err != nil {
+			return err
+		}
+		for _, desc := range cfg.Modules {
+			if desc.Version != "" {
+				continue
+			}
+			if _, err = os.Stat(svc.Dir(desc)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: module %q not found in the modules path", desc.InstanceID(), desc.Path))
+				continue
+			}
+			for _, font := range desc.Fonts {
+				if _, err = fontFaceCSS(font, svc.Dir(desc)); err != nil {
+					errs = append(errs, fmt.Errorf("%s: could not load font %q: %w", desc.InstanceID(), font.Family, err))
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return ConfigErrors(errs)
+}
+
+// checkFiles returns the problems with the files referenced by the ui configuration.
+func (c UIConfig) checkFiles() []error {
+	var errs []error
+	for _, cssPath := range c.CustomCSS {
+		if isRemoteCSS(cssPath) {
+			continue
+		}
+		if _, err := readAsset(cssPath); err != nil {
+			errs = append(errs, fmt.Errorf("config: could not read custom css %q: %w", cssPath, err))
+		}
+	}
+	for _, p := range []string{c.Splash.HTML, c.Splash.CSS} {
+		if p == "" {
+			continue
+		}
+		if _, err := readAsset(p); err != nil {
+			errs = append(errs, fmt.Errorf("config: could not read splash file %q: %w", p, err))
+		}
+	}
+	if _, err := i18n.Load(c.Lang, c.Locales); err != nil {
+		errs = append(errs, fmt.Errorf("config: %w", err))
+	}
+	return errs
+}
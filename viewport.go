@@ -0,0 +1,56 @@
+package glass
+
+import (
+	"encoding/json"
+	"fmt"
+
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// ResizeTopic is the event bus topic a viewport resize is published on.
+const ResizeTopic = "glass.resize"
+
+// viewport is the size of the page viewport in css pixels.
+type viewport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ResizeHandler handles a resize of the page viewport, in css pixels.
+type ResizeHandler func(w, h int)
+
+// OnResize adds handlers called when the page viewport is resized.
+func (ui *UI) OnResize(fns ...ResizeHandler) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	ui.resizeFns = append(ui.resizeFns, fns...)
+}
+
+// reportResize is called from javascript when the viewport is resized.
+func (ui *UI) reportResize(w, h int) {
+	ui.mu.RLock()
+	fns := ui.resizeFns
+	ui.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(w, h)
+	}
+}
+
+// ViewportSize returns the size of the page viewport in css pixels.
+func (u *UIContext) ViewportSize() (w, h int, err error) {
+	var v viewport
+	if err = u.ui.moduleEvalInto(u.name, &v, "viewportSize();"); err != nil {
+		return 0, 0, fmt.Errorf("%s: could not get viewport size: %w", u.name, u.evalError(err))
+	}
+	return v.Width, v.Height, nil
+}
+
+// viewportResized publishes the viewport size on ResizeTopic.
+func (r *Runtime) viewportResized(w, h int) {
+	b, _ := json.Marshal(viewport{Width: w, Height: h})
+	r.bus.Publish(Event{Topic: ResizeTopic, Data: b})
+
+	r.log.Debug("viewport resized", logCtx.Int("width", w), logCtx.Int("height", h))
+}
@@ -0,0 +1,52 @@
+package glass
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIContext_ViewportSize(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	w, h, err := uiCtx.ViewportSize()
+
+	require.NoError(t, err)
+	assert.Equal(t, 1024, w)
+	assert.Equal(t, 768, h)
+}
+
+func TestUIContext_ViewportSizeHandlesError(t *testing.T) {
+	win := &MockLorcaUI{}
+	win.On("Eval", `createModule("test", "top", "right");`).Return(NewValue("", nil))
+	win.On("Eval", "viewportSize();").Return(NewValue("", errors.New("test error")))
+
+	ui := &UI{win: win}
+	uiCtx, err := NewUIContext(ui, "test", module.Position{Vertical: module.Top, Horizontal: module.Right})
+	require.NoError(t, err)
+
+	_, _, err = uiCtx.ViewportSize()
+
+	assert.EqualError(t, err, "test: could not get viewport size: test error")
+}
+
+func TestRuntime_ViewportResizedPublishesSize(t *testing.T) {
+	win := NewRecordingWindow()
+	ui := &UI{win: win}
+	rt := NewRuntime(Config{}, ui, &MockModuleRunner{}, newTestLogger())
+	ui.OnResize(rt.viewportResized)
+
+	got := make(chan json.RawMessage, 1)
+	unsub := rt.Events().Subscribe(ResizeTopic, func(e Event) {
+		got <- e.Data
+	})
+	defer unsub()
+
+	ui.reportResize(800, 480)
+
+	assert.JSONEq(t, `{"width":800,"height":480}`, string(<-got))
+}
@@ -0,0 +1,259 @@
+package glass
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/glasslabs/looking-glass/module"
+	logCtx "github.com/hamba/logger/v2/ctx"
+)
+
+// devDebounce is the time module files must be unchanged before
+// the module is reloaded in dev mode.
+const devDebounce = 300 * time.Millisecond
+
+// moduleDirer is implemented by module runners that know
+// the directory of a module.
+type moduleDirer interface {
+	Dir(desc module.Descriptor) string
+}
+
+// moduleWatcher watches module directories, calling fn with the
+// module name once its files stop changing.
+type moduleWatcher struct {
+	w        *fsnotify.Watcher
+	debounce time.Duration
+	fn       func(name string)
+
+	mu     sync.Mutex
+	dirs   map[string][]string
+	owners map[string]string
+	timers map[string]*time.Timer
+	closed bool
+
+	// calls tracks the callbacks in flight, so close can wait for them.
+	calls sync.WaitGroup
+	done  chan struct{}
+}
+
+func newModuleWatcher(debounce time.Duration, fn func(name string)) (*moduleWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	mw := &moduleWatcher{
+		w:        w,
+		debounce: debounce,
+		fn:       fn,
+		dirs:     map[string][]string{},
+		owners:   map[string]string{},
+		timers:   map[string]*time.Timer{},
+		done:     make(chan struct{}),
+	}
+	go mw.run()
+	return mw, nil
+}
+
+func (w *moduleWatcher) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case event, ok := <-w.w.Events:
+			if !ok {
+				return
+			}
+			w.changed(event.Name)
+		case _, ok := <-w.w.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (w *moduleWatcher) changed(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	name, ok := w.owners[filepath.Dir(path)]
+	if !ok {
+		name, ok = w.owners[path]
+	}
+	if !ok {
+		return
+	}
+
+	if w.closed {
+		return
+	}
+	if t, ok := w.timers[name]; ok {
+		t.Stop()
+	}
+	var t *time.Timer
+	t = time.AfterFunc(w.debounce, func() {
+		w.mu.Lock()
+		// A stopped timer may still fire, so only the current
+		// timer of the module is allowed to call fn.
+		if w.closed || w.timers[name] != t {
+			w.mu.Unlock()
+			return
+		}
+		delete(w.timers, name)
+		w.calls.Add(1)
+		w.mu.Unlock()
+		defer w.calls.Done()
+
+		w.fn(name)
+	})
+	w.timers[name] = t
+}
+
+// add watches the directory of a module, including its sub directories.
+func (w *moduleWatcher) add(name, dir string) error {
+	var dirs []string
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(fi.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err = w.w.Add(path); err != nil {
+			return err
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, d := range dirs {
+		w.owners[d] = name
+	}
+	w.dirs[name] = append(w.dirs[name], dirs...)
+	return err
+}
+
+// remove stops watching the directory of a module.
+func (w *moduleWatcher) remove(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, d := range w.dirs[name] {
+		_ = w.w.Remove(d)
+		delete(w.owners, d)
+	}
+	delete(w.dirs, name)
+	if t, ok := w.timers[name]; ok {
+		t.Stop()
+		delete(w.timers, name)
+	}
+}
+
+// close stops watching all modules, waiting for callbacks in flight to return.
+func (w *moduleWatcher) close() error {
+	w.mu.Lock()
+	w.closed = true
+	for name, t := range w.timers {
+		t.Stop()
+		delete(w.timers, name)
+	}
+	w.mu.Unlock()
+
+	err := w.w.Close()
+	<-w.done
+	w.calls.Wait()
+	return err
+}
+
+// watchModule reloads the module when its files change, if dev mode is enabled.
+func (r *Runtime) watchModule(ctx context.Context, state *moduleState) {
+	if !r.cfg.Dev {
+		return
+	}
+	direr, ok := r.svc.(moduleDirer)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	if r.watch == nil {
+		w, err := newModuleWatcher(devDebounce, func(name string) {
+			r.reloadModule(ctx, name)
+		})
+		if err != nil {
+			r.mu.Unlock()
+			r.log.Error("could not watch modules", logCtx.Error("error", err))
+			return
+		}
+		r.watch = w
+	}
+	w := r.watch
+	r.mu.Unlock()
+
+	if err := w.add(state.desc.InstanceID(), direr.Dir(state.desc)); err != nil {
+		r.log.Error("could not watch module", logCtx.Str("module", state.desc.InstanceID()), logCtx.Error("error", err))
+	}
+}
+
+// unwatchModule stops watching the files of the module.
+func (r *Runtime) unwatchModule(name string) {
+	r.mu.Lock()
+	w := r.watch
+	r.mu.Unlock()
+
+	if w != nil {
+		w.remove(name)
+	}
+}
+
+// stopWatching stops watching all module files.
+func (r *Runtime) stopWatching() {
+	r.mu.Lock()
+	w := r.watch
+	r.watch = nil
+	r.mu.Unlock()
+
+	if w != nil {
+		_ = w.close()
+	}
+}
+
+// reloadModule closes and loads the module again, picking up its changed files.
+func (r *Runtime) reloadModule(ctx context.Context, name string) {
+	state, err := r.moduleState(name)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	running := state.running
+	r.mu.Unlock()
+	if !running {
+		return
+	}
+
+	r.log.Info("module files changed, reloading", logCtx.Str("module", name))
+	r.unload(state)
+
+	r.mu.Lock()
+	state.ui = nil
+	state.err = nil
+	state.failed = false
+	r.mu.Unlock()
+
+	if err = r.load(ctx, []*moduleState{state}, true); err != nil {
+		r.log.Error("could not reload module", logCtx.Str("module", name), logCtx.Error("error", err))
+	}
+}
@@ -0,0 +1,179 @@
+package glass
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/looking-glass/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleWatcher_DebouncesChanges(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0o750))
+
+	changed := make(chan string, 10)
+	w, err := newModuleWatcher(50*time.Millisecond, func(name string) {
+		changed <- name
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.close() })
+
+	err = w.add("test", dir)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		err = os.WriteFile(filepath.Join(dir, "assets", "style.css"), []byte("body {}"), 0o600)
+		require.NoError(t, err)
+	}
+
+	select {
+	case name := <-changed:
+		assert.Equal(t, "test", name)
+	case <-time.After(time.Second):
+		require.Fail(t, "module change not reported")
+	}
+	select {
+	case <-changed:
+		assert.Fail(t, "changes were not debounced")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestModuleWatcher_Remove(t *testing.T) {
+	dir := t.TempDir()
+
+	var calls int32
+	w, err := newModuleWatcher(10*time.Millisecond, func(string) {
+		atomic.AddInt32(&calls, 1)
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.close() })
+
+	err = w.add("test", dir)
+	require.NoError(t, err)
+	w.remove("test")
+
+	err = os.WriteFile(filepath.Join(dir, "style.css"), []byte("body {}"), 0o600)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestModuleWatcher_CloseWaitsForCallbacks(t *testing.T) {
+	dir := t.TempDir()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int32
+	w, err := newModuleWatcher(10*time.Millisecond, func(string) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+		}
+	})
+	require.NoError(t, err)
+
+	err = w.add("test", dir)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "style.css"), []byte("body {}"), 0o600)
+	require.NoError(t, err)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		require.Fail(t, "module change not reported")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = w.close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		require.Fail(t, "close did not wait for the callback")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "close did not return")
+	}
+
+	w.changed(dir)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+type dirModuleRunner struct {
+	*MockModuleRunner
+
+	dir string
+}
+
+func (r dirModuleRunner) Dir(module.Descriptor) string {
+	return r.dir
+}
+
+func TestRuntime_ReloadsChangedModulesInDevMode(t *testing.T) {
+	dir := t.TempDir()
+
+	uiCtx, _ := NewTestUIContext()
+	ui := uiCtx.ui
+
+	desc := module.Descriptor{
+		Name:     "test",
+		Path:     "test-module",
+		Position: module.Position{Vertical: module.Top, Horizontal: module.Right},
+	}
+	runs := make(chan struct{}, 10)
+	mod := &MockModule{}
+	mod.On("Close").Return(nil)
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		runs <- struct{}{}
+	}).Return(mod, nil)
+
+	rt := NewRuntime(Config{Dev: true, Modules: []module.Descriptor{desc}}, ui, dirModuleRunner{MockModuleRunner: svc, dir: dir}, newTestLogger())
+	t.Cleanup(func() { _ = rt.Close() })
+
+	err := rt.Load(context.Background())
+	require.NoError(t, err)
+	<-runs
+
+	err = os.WriteFile(filepath.Join(dir, "style.css"), []byte("body {}"), 0o600)
+	require.NoError(t, err)
+
+	select {
+	case <-runs:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "module was not reloaded")
+	}
+	mod.AssertCalled(t, "Close")
+}
+
+func TestRuntime_DoesNotWatchModulesWithoutDevMode(t *testing.T) {
+	uiCtx, _ := NewTestUIContext()
+
+	desc := module.Descriptor{Name: "test", Path: "test-module"}
+	svc := &MockModuleRunner{}
+	svc.On("Extract", desc).Return(nil)
+	svc.On("Run", mock.Anything, desc, mock.Anything, mock.Anything).Return(&MockModule{}, nil)
+
+	rt := NewRuntime(Config{Modules: []module.Descriptor{desc}}, uiCtx.ui, dirModuleRunner{MockModuleRunner: svc, dir: t.TempDir()}, newTestLogger())
+
+	err := rt.Load(context.Background())
+
+	require.NoError(t, err)
+	assert.Nil(t, rt.watch)
+}